@@ -0,0 +1,145 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package checksum
+
+import (
+	"sigs.k8s.io/kustomize/api/hasher"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Annotation is the annotation of pod templates that
+// holds the checksum of the objects that they refer to.
+const Annotation = "kustomize.config.k8s.io/checksum"
+
+// Ref is an object that pod templates may refer to.
+type Ref struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// podTemplatePaths are the paths to the pod templates of
+// the workloads.
+var podTemplatePaths = [][]string{
+	{"spec", "template"},
+	{"spec", "jobTemplate", "spec", "template"},
+}
+
+// refPaths are the paths, in pod specs, of the names of
+// the objects that they mount, or take the environment
+// of containers from, by kind; lists are descended into.
+var refPaths = map[string][][]string{
+	"ConfigMap": {
+		{"volumes", "configMap", "name"},
+		{"volumes", "projected", "sources", "configMap", "name"},
+		{"containers", "envFrom", "configMapRef", "name"},
+		{"containers", "env", "valueFrom", "configMapKeyRef", "name"},
+		{"initContainers", "envFrom", "configMapRef", "name"},
+		{"initContainers", "env", "valueFrom", "configMapKeyRef", "name"},
+	},
+	"Secret": {
+		{"volumes", "secret", "secretName"},
+		{"volumes", "projected", "sources", "secret", "name"},
+		{"containers", "envFrom", "secretRef", "name"},
+		{"containers", "env", "valueFrom", "secretKeyRef", "name"},
+		{"initContainers", "envFrom", "secretRef", "name"},
+		{"initContainers", "env", "valueFrom", "secretKeyRef", "name"},
+	},
+}
+
+// Filter annotates the pod templates of workloads with a
+// checksum of the hashes of the objects that they refer
+// to, so that changing the objects rolls the workloads.
+// Pod templates referring to none of the objects aren't
+// annotated.
+type Filter struct {
+	// Hashes are the hashes of the contents of the objects.
+	Hashes map[Ref]string
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	meta, err := node.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range podTemplatePaths {
+		template, err := node.Pipe(yaml.Lookup(path...))
+		if err != nil {
+			return nil, err
+		}
+		if template == nil {
+			continue
+		}
+		spec, err := template.Pipe(yaml.Lookup("spec"))
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil {
+			continue
+		}
+		var hashes []string
+		seen := make(map[Ref]bool)
+		for kind, paths := range refPaths {
+			for _, p := range paths {
+				for _, name := range valuesAt(spec, p) {
+					ref := Ref{Kind: kind, Namespace: meta.Namespace, Name: name}
+					h, ok := f.Hashes[ref]
+					if !ok || seen[ref] {
+						continue
+					}
+					seen[ref] = true
+					hashes = append(hashes, kind+"/"+name+"="+h)
+				}
+			}
+		}
+		if len(hashes) == 0 {
+			continue
+		}
+		sum, err := hasher.SortArrayAndComputeHash(hashes)
+		if err != nil {
+			return nil, err
+		}
+		if err = template.PipeE(yaml.SetAnnotation(Annotation, sum)); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// valuesAt returns the scalar values at the path,
+// descending into the items of lists.
+func valuesAt(node *yaml.RNode, path []string) []string {
+	if node == nil {
+		return nil
+	}
+	if node.YNode().Kind == yaml.SequenceNode {
+		var result []string
+		elements, _ := node.Elements()
+		for _, e := range elements {
+			result = append(result, valuesAt(e, path)...)
+		}
+		return result
+	}
+	if len(path) == 0 {
+		if node.YNode().Kind == yaml.ScalarNode {
+			return []string{node.YNode().Value}
+		}
+		return nil
+	}
+	if node.YNode().Kind != yaml.MappingNode {
+		return nil
+	}
+	field := node.Field(path[0])
+	if field == nil {
+		return nil
+	}
+	return valuesAt(field.Value, path[1:])
+}
@@ -0,0 +1,245 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package checksum
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+)
+
+func TestFilter(t *testing.T) {
+	hashes := map[Ref]string{
+		{Kind: "ConfigMap", Name: "settings"}:                  "c2fbh8mkmf",
+		{Kind: "Secret", Name: "tls"}:                          "k7tgh24b7d",
+		{Kind: "ConfigMap", Namespace: "other", Name: "env"}:   "2kt7m5b8h6",
+		{Kind: "ConfigMap", Name: "env"}:                       "dd9mc7g2t5",
+		{Kind: "Secret", Namespace: "other", Name: "settings"}: "fg8h2t6b8c",
+	}
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"deployment": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      volumes:
+      - name: settings
+        configMap:
+          name: settings
+      - name: tls
+        secret:
+          secretName: tls
+      containers:
+      - name: app
+        envFrom:
+        - configMapRef:
+            name: env
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+      annotations:
+        kustomize.config.k8s.io/checksum: '28gmf7k4ff'
+    spec:
+      volumes:
+      - name: settings
+        configMap:
+          name: settings
+      - name: tls
+        secret:
+          secretName: tls
+      containers:
+      - name: app
+        envFrom:
+        - configMapRef:
+            name: env
+`,
+		},
+		"cronjob in namespace": {
+			input: `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+  namespace: other
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers:
+          - name: init
+            env:
+            - name: MODE
+              valueFrom:
+                configMapKeyRef:
+                  name: env
+                  key: mode
+          containers:
+          - name: app
+            env:
+            - name: TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: tls
+                  key: token
+`,
+			expected: `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+  namespace: other
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers:
+          - name: init
+            env:
+            - name: MODE
+              valueFrom:
+                configMapKeyRef:
+                  name: env
+                  key: mode
+          containers:
+          - name: app
+            env:
+            - name: TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: tls
+                  key: token
+        metadata:
+          annotations:
+            kustomize.config.k8s.io/checksum: '42c49gc4kh'
+`,
+		},
+		"no references": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      volumes:
+      - name: data
+        emptyDir: {}
+      - name: other
+        projected:
+          sources:
+          - secret:
+              name: settings
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      volumes:
+      - name: data
+        emptyDir: {}
+      - name: other
+        projected:
+          sources:
+          - secret:
+              name: settings
+`,
+		},
+		"template without spec": {
+			input: `
+apiVersion: example.com/v1
+kind: Scheduled
+metadata:
+  name: nightly
+spec:
+  template:
+    metadata:
+      name: unused
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          volumes:
+          - name: settings
+            configMap:
+              name: settings
+`,
+			expected: `
+apiVersion: example.com/v1
+kind: Scheduled
+metadata:
+  name: nightly
+spec:
+  template:
+    metadata:
+      name: unused
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          volumes:
+          - name: settings
+            configMap:
+              name: settings
+        metadata:
+          annotations:
+            kustomize.config.k8s.io/checksum: 'd9mfk27fth'
+`,
+		},
+		"not a workload": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  a: b
+`,
+			expected: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  a: b
+`,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			f := Filter{Hashes: hashes}
+			if !assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(filtertest_test.RunFilter(t, tc.input, f))) {
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package checksum contains a kio.Filter implementation of the kustomize
+// ChecksumTransformer.
+package checksum
@@ -0,0 +1,7 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package field contains a kio.Filter implementation of the kustomize
+// FieldTransformer, which sets, edits or deletes the fields that a list of
+// FieldSpecs locate.
+package field
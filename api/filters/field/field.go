@@ -0,0 +1,262 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package field
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Operation is what a Filter does to a field.
+type Operation string
+
+const (
+	// Set sets the field to the Value.
+	Set Operation = "set"
+
+	// Prepend puts the Value before that of the field, or,
+	// if the field is a list, inserts it as the first item.
+	Prepend Operation = "prepend"
+
+	// Append puts the Value after that of the field, or,
+	// if the field is a list, adds it as the last item.
+	Append Operation = "append"
+
+	// Replace replaces the matches of the Regex in the field,
+	// or in each item of a list, with the Value, in which
+	// "$1" stands for the first submatch, and so on.
+	Replace Operation = "replace"
+
+	// Delete removes the field.
+	Delete Operation = "delete"
+
+	// Template sets the field to the Value, a text/template
+	// executed on the object, e.g. "{{.metadata.name}}-db".
+	Template Operation = "template"
+)
+
+// Filter applies an Operation to the fields of an object
+// that the fieldSpecs locate.
+type Filter struct {
+	Operation Operation `json:"operation,omitempty" yaml:"operation,omitempty"`
+
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Regex is the pattern that Replace replaces.
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+
+	FsSlice types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+// Validate returns an error if the Operation is unknown
+// or lacks what it needs.
+func (f Filter) Validate() error {
+	_, err := f.compile()
+	return err
+}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	c, err := f.compile()
+	if err != nil {
+		return nil, err
+	}
+	return kio.FilterAll(yaml.FilterFunc(c.run)).Filter(nodes)
+}
+
+// compiled is a Filter with its Regex or template parsed.
+type compiled struct {
+	Filter
+	re   *regexp.Regexp
+	tmpl *template.Template
+}
+
+func (f Filter) compile() (*compiled, error) {
+	c := &compiled{Filter: f}
+	switch f.Operation {
+	case Set, Prepend, Append, Delete:
+	case Replace:
+		if f.Regex == "" {
+			return nil, fmt.Errorf("operation %s requires a regex", f.Operation)
+		}
+		re, err := regexp.Compile(f.Regex)
+		if err != nil {
+			return nil, err
+		}
+		c.re = re
+	case Template:
+		tmpl, err := template.New("value").
+			Option("missingkey=error").Parse(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		c.tmpl = tmpl
+	case "":
+		return nil, fmt.Errorf("operation must be one of %s, %s, %s, %s, %s or %s",
+			Set, Prepend, Append, Replace, Delete, Template)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", f.Operation)
+	}
+	if f.Operation != Replace && f.Regex != "" {
+		return nil, fmt.Errorf("operation %s takes no regex", f.Operation)
+	}
+	return c, nil
+}
+
+func (c *compiled) run(node *yaml.RNode) (*yaml.RNode, error) {
+	if c.Operation == Delete {
+		return node, c.delete(node)
+	}
+	value := c.Value
+	if c.tmpl != nil {
+		var err error
+		value, err = c.execute(node)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := node.PipeE(fsslice.Filter{
+		FsSlice: c.FsSlice,
+		SetValue: func(n *yaml.RNode) error {
+			return c.apply(n.YNode(), value)
+		},
+		CreateKind: yaml.ScalarNode,
+	})
+	return node, err
+}
+
+// execute returns the value of the template for the object.
+func (c *compiled) execute(node *yaml.RNode) (string, error) {
+	j, err := node.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	var obj map[string]interface{}
+	if err = json.Unmarshal(j, &obj); err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err = c.tmpl.Execute(&b, obj); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// apply applies the operation, other than Delete, to the
+// field n.
+func (c *compiled) apply(n *yaml.Node, value string) error {
+	if c.Operation == Set || c.Operation == Template {
+		setString(n, value)
+		return nil
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		switch c.Operation {
+		case Prepend:
+			setString(n, value+n.Value)
+		case Append:
+			setString(n, n.Value+value)
+		case Replace:
+			setString(n, c.re.ReplaceAllString(n.Value, value))
+		}
+		return nil
+	case yaml.SequenceNode:
+		item := &yaml.Node{Kind: yaml.ScalarNode}
+		setString(item, value)
+		switch c.Operation {
+		case Prepend:
+			n.Content = append([]*yaml.Node{item}, n.Content...)
+		case Append:
+			n.Content = append(n.Content, item)
+		case Replace:
+			for _, item := range n.Content {
+				if item.Kind == yaml.ScalarNode {
+					setString(item, c.re.ReplaceAllString(item.Value, value))
+				}
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("can't %s a field that is neither a list nor a scalar", c.Operation)
+}
+
+// setString sets the node to the scalar value.  A number
+// or boolean keeps its type if the value is of it too;
+// any other value is a string.
+func setString(n *yaml.Node, value string) {
+	tag := n.Tag
+	n.Kind = yaml.ScalarNode
+	n.Content = nil
+	n.Value = value
+	if tag == yaml.IntTag || tag == yaml.BoolTag || tag == "!!float" {
+		n.Tag = ""
+		if n.ShortTag() == tag {
+			n.Tag, n.Style = tag, 0
+			return
+		}
+	}
+	n.Tag = yaml.StringTag
+	if yaml.IsYaml1_1NonString(n) {
+		n.Style = yaml.DoubleQuotedStyle
+	} else if tag != yaml.StringTag {
+		n.Style = 0
+	}
+}
+
+// delete removes the fields, by way of their parents.
+func (c *compiled) delete(node *yaml.RNode) error {
+	for _, fs := range c.FsSlice {
+		path := strings.Split(fs.Path, "/")
+		name := strings.TrimSuffix(path[len(path)-1], "[]")
+		clearIn := func(n *yaml.RNode) error {
+			if n.YNode().Kind == yaml.SequenceNode {
+				return n.VisitElements(func(item *yaml.RNode) error {
+					return clearField(item, name)
+				})
+			}
+			return clearField(n, name)
+		}
+		if len(path) == 1 {
+			meta, err := node.GetMeta()
+			if err != nil {
+				return err
+			}
+			if !fsslice.GetGVK(meta).IsSelected(&fs.Gvk) {
+				continue
+			}
+			if err = clearIn(node); err != nil {
+				return err
+			}
+			continue
+		}
+		fs.Path = strings.Join(path[:len(path)-1], "/")
+		fs.CreateIfNotPresent = false
+		err := node.PipeE(fsslice.Filter{
+			FsSlice:  types.FsSlice{fs},
+			SetValue: clearIn,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func clearField(n *yaml.RNode, name string) error {
+	if n.YNode().Kind != yaml.MappingNode {
+		return nil
+	}
+	_, err := n.Pipe(yaml.Clear(name))
+	return err
+}
@@ -0,0 +1,259 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package field
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const input = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    tier: "3"
+spec:
+  replicas: 1
+  paused: false
+  template:
+    spec:
+      containers:
+      - name: app
+        image: registry.old.example.com/app:1.0
+        args:
+        - --verbose
+      - name: proxy
+        image: registry.old.example.com/proxy:2.0
+status:
+  replicas: 1
+`
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		filter   Filter
+		expected string
+	}{
+		"set": {
+			filter: Filter{
+				Operation: Set,
+				Value:     "4",
+				FsSlice: types.FsSlice{
+					{Path: "spec/replicas"},
+					{Path: "metadata/labels/tier"},
+					{Path: "metadata/labels/env", CreateIfNotPresent: true},
+				},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    tier: "4"
+    env: "4"
+spec:
+  replicas: 4
+  paused: false
+  template:
+    spec:
+      containers:
+      - name: app
+        image: registry.old.example.com/app:1.0
+        args:
+        - --verbose
+      - name: proxy
+        image: registry.old.example.com/proxy:2.0
+status:
+  replicas: 1
+`,
+		},
+		"set a boolean to a string": {
+			filter: Filter{
+				Operation: Set,
+				Value:     "sometimes",
+				FsSlice:   types.FsSlice{{Path: "spec/paused"}},
+			},
+			expected: strings.Replace(input, "paused: false", "paused: sometimes", 1),
+		},
+		"prepend and append": {
+			filter: Filter{
+				Operation: Append,
+				Value:     "-canary",
+				FsSlice: types.FsSlice{
+					{Path: "metadata/name"},
+					{Path: "spec/template/spec/containers/name"},
+				},
+			},
+			expected: strings.NewReplacer(
+				"name: web", "name: web-canary",
+				"name: app", "name: app-canary",
+				"name: proxy", "name: proxy-canary").Replace(input),
+		},
+		"prepend to a list": {
+			filter: Filter{
+				Operation: Prepend,
+				Value:     "--port=80",
+				FsSlice: types.FsSlice{
+					{Path: "spec/template/spec/containers/args"},
+				},
+			},
+			expected: strings.Replace(input,
+				"        - --verbose", "        - --port=80\n        - --verbose", 1),
+		},
+		"replace": {
+			filter: Filter{
+				Operation: Replace,
+				Regex:     `^registry\.old\.example\.com/(.*):`,
+				Value:     "registry.example.com/team/$1:",
+				FsSlice: types.FsSlice{
+					{Path: "spec/template/spec/containers/image", Gvk: resid.Gvk{Kind: "Deployment"}},
+				},
+			},
+			expected: strings.NewReplacer(
+				"registry.old.example.com/app", "registry.example.com/team/app",
+				"registry.old.example.com/proxy", "registry.example.com/team/proxy").Replace(input),
+		},
+		"replace in a list": {
+			filter: Filter{
+				Operation: Replace,
+				Regex:     "^--",
+				Value:     "-",
+				FsSlice: types.FsSlice{
+					{Path: "spec/template/spec/containers/args"},
+				},
+			},
+			expected: strings.Replace(input, "- --verbose", "- -verbose", 1),
+		},
+		"delete": {
+			filter: Filter{
+				Operation: Delete,
+				FsSlice: types.FsSlice{
+					{Path: "status"},
+					{Path: "spec/template/spec/containers/args"},
+					{Path: "metadata/labels/missing"},
+				},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    tier: "3"
+spec:
+  replicas: 1
+  paused: false
+  template:
+    spec:
+      containers:
+      - name: app
+        image: registry.old.example.com/app:1.0
+      - name: proxy
+        image: registry.old.example.com/proxy:2.0
+`,
+		},
+		"delete only from the kind": {
+			filter: Filter{
+				Operation: Delete,
+				FsSlice: types.FsSlice{
+					{Path: "status", Gvk: resid.Gvk{Kind: "Deployment"}},
+					{Path: "spec", Gvk: resid.Gvk{Kind: "StatefulSet"}},
+				},
+			},
+			expected: input[:strings.Index(input, "status:")],
+		},
+		"template": {
+			filter: Filter{
+				Operation: Template,
+				Value:     "{{.metadata.name}}-{{.metadata.namespace}}",
+				FsSlice: types.FsSlice{
+					{Path: "metadata/labels/app", CreateIfNotPresent: true},
+				},
+			},
+			expected: strings.Replace(input,
+				`    tier: "3"`, "    tier: \"3\"\n    app: web-prod", 1),
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			if !assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(filtertest_test.RunFilter(t, input, tc.filter))) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	testCases := map[string]struct {
+		filter   Filter
+		expected string
+	}{
+		"no operation": {
+			filter:   Filter{},
+			expected: "operation must be one of set, prepend, append, replace, delete or template",
+		},
+		"unknown operation": {
+			filter:   Filter{Operation: "sed"},
+			expected: `unknown operation "sed"`,
+		},
+		"replace without a regex": {
+			filter:   Filter{Operation: Replace},
+			expected: "operation replace requires a regex",
+		},
+		"regex without replace": {
+			filter:   Filter{Operation: Set, Regex: "a"},
+			expected: "operation set takes no regex",
+		},
+		"bad regex": {
+			filter:   Filter{Operation: Replace, Regex: "("},
+			expected: "error parsing regexp: missing closing ): `(`",
+		},
+		"bad template": {
+			filter:   Filter{Operation: Template, Value: "{{.metadata.name"},
+			expected: "template: value:1: unclosed action",
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := tc.filter.Validate()
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	f := Filter{
+		Operation: Template,
+		Value:     "{{.metadata.uid}}",
+		FsSlice:   types.FsSlice{{Path: "metadata/name"}},
+	}
+	_, err := f.Filter([]*yaml.RNode{yaml.MustParse(input)})
+	if err == nil || !strings.Contains(err.Error(), `map has no entry for key "uid"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f = Filter{
+		Operation: Append,
+		Value:     "x",
+		FsSlice:   types.FsSlice{{Path: "metadata/labels"}},
+	}
+	_, err = f.Filter([]*yaml.RNode{yaml.MustParse(input)})
+	if err == nil || !strings.Contains(err.Error(),
+		"can't append a field that is neither a list nor a scalar") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
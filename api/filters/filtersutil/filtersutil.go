@@ -7,6 +7,16 @@ import (
 	"sort"
 )
 
+// PodSpecPaths are the paths to the pod specs of
+// workloads, in which their containers are: that of a
+// Pod, that of the pod template of a Deployment and the
+// like, and that of the job template of a CronJob.
+var PodSpecPaths = [][]string{
+	{"spec"},
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
 // SortedMapKeys returns a sorted slice of keys to the given map.
 // Writing this function never gets old.
 func SortedMapKeys(m map[string]string) []string {
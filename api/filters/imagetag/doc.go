@@ -1,7 +1,7 @@
 // Copyright 2020 The Kubernetes Authors.
 // SPDX-License-Identifier: Apache-2.0
 
-// Package imagetag contains two kio.Filter implementations to cover the
+// Package imagetag contains three kio.Filter implementations to cover the
 // functionality of the kustomize imagetag transformer.
 //
 // Filter updates fields based on a FieldSpec and an ImageTag.
@@ -9,4 +9,10 @@
 // LegacyFilter doesn't use a FieldSpec, and instead only updates image
 // references if the field is name image and it is underneath a field called
 // either containers or initContainers.
+//
+// Both resolve the tagPolicy and digestFrom of an ImageTag against an
+// Index, the content of an image index file such as images.lock.yaml.
+//
+// IndexFilter finds images as LegacyFilter does, and fails on the first
+// one that isn't in an Index.
 package imagetag
@@ -16,6 +16,9 @@ type Filter struct {
 
 	// FsSlice contains the FieldSpecs to locate the namespace field
 	FsSlice types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Index resolves the TagPolicy and DigestFrom of ImageTag.
+	Index *Index `json:"-" yaml:"-"`
 }
 
 var _ kio.Filter = Filter{}
@@ -28,17 +31,18 @@ func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 func (f Filter) filter(node *yaml.RNode) (*yaml.RNode, error) {
 	if err := node.PipeE(fsslice.Filter{
 		FsSlice:  f.FsSlice,
-		SetValue: updateImageTagFn(f.ImageTag),
+		SetValue: updateImageTagFn(f.ImageTag, f.Index),
 	}); err != nil {
 		return nil, err
 	}
 	return node, nil
 }
 
-func updateImageTagFn(imageTag types.Image) fsslice.SetFn {
+func updateImageTagFn(imageTag types.Image, index *Index) fsslice.SetFn {
 	return func(node *yaml.RNode) error {
 		return node.PipeE(imageTagUpdater{
 			ImageTag: imageTag,
+			Index:    index,
 		})
 	}
 }
@@ -0,0 +1,155 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package imagetag
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/image"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Index is an image index: the approved tags of images and
+// their digests, e.g. as a release process records them
+// in an images.lock.yaml file:
+//
+//   images:
+//   - name: nginx
+//     tags:
+//     - tag: 1.19.2
+//       digest: sha256:...
+type Index struct {
+	Images []IndexedImage `json:"images,omitempty" yaml:"images,omitempty"`
+}
+
+// IndexedImage is an image of an Index.
+type IndexedImage struct {
+	// Name is a tag-less image name.
+	Name string       `json:"name,omitempty" yaml:"name,omitempty"`
+	Tags []IndexedTag `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// IndexedTag is an approved tag of an image, and the
+// digest it refers to.
+type IndexedTag struct {
+	Tag    string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+}
+
+// ParseIndex parses the content of an image index file.
+func ParseIndex(content []byte) (*Index, error) {
+	ix := &Index{}
+	if err := yaml.UnmarshalStrict(content, ix); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, img := range ix.Images {
+		if img.Name == "" {
+			return nil, fmt.Errorf("image index has an image without a name")
+		}
+		if seen[img.Name] {
+			return nil, fmt.Errorf("image %s is in the image index twice", img.Name)
+		}
+		seen[img.Name] = true
+	}
+	return ix, nil
+}
+
+func (ix *Index) image(name string) *IndexedImage {
+	for i := range ix.Images {
+		if ix.Images[i].Name == name {
+			return &ix.Images[i]
+		}
+	}
+	return nil
+}
+
+// Covers returns true if the image reference, e.g.
+// "nginx:1.19.2", names an image of the index, by one of
+// its tags or digests.  A reference with neither is to the
+// tag "latest".
+func (ix *Index) Covers(ref string) bool {
+	name, tag := image.Split(ref)
+	img := ix.image(name)
+	if img == nil {
+		return false
+	}
+	if tag == "" {
+		tag = ":latest"
+	}
+	for _, t := range img.Tags {
+		if tag == ":"+t.Tag || (t.Digest != "" && tag == "@"+t.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	semverPolicyPrefix = "semver:"
+	digestFromFile     = "file"
+)
+
+// resolve returns the tag, with its separator, that the
+// TagPolicy and DigestFrom fields of imageTag give the
+// image with the given name and tag.
+func (ix *Index) resolve(imageTag types.Image, name, tag string) (string, error) {
+	if ix == nil {
+		return "", fmt.Errorf(
+			"image %s has a tagPolicy or digestFrom, but there's no image index", name)
+	}
+	img := ix.image(name)
+	if img == nil {
+		return "", fmt.Errorf("image %s isn't in the image index", name)
+	}
+	if imageTag.TagPolicy != "" {
+		if !strings.HasPrefix(imageTag.TagPolicy, semverPolicyPrefix) {
+			return "", fmt.Errorf("unknown tag policy %q", imageTag.TagPolicy)
+		}
+		c, err := parseConstraints(
+			strings.TrimPrefix(imageTag.TagPolicy, semverPolicyPrefix))
+		if err != nil {
+			return "", err
+		}
+		best, found := version{}, false
+		for _, t := range img.Tags {
+			v, ok := parseVersion(t.Tag)
+			// Pre-releases are never picked.
+			if !ok || v.pre != "" || !c(v) {
+				continue
+			}
+			if !found || v.compare(best) > 0 {
+				best, found, tag = v, true, ":"+t.Tag
+			}
+		}
+		if !found {
+			return "", fmt.Errorf(
+				"no tag of image %s in the image index satisfies %s",
+				name, imageTag.TagPolicy)
+		}
+	}
+	switch imageTag.DigestFrom {
+	case "":
+		return tag, nil
+	case digestFromFile:
+	default:
+		return "", fmt.Errorf(
+			"digestFrom must be %q, not %q", digestFromFile, imageTag.DigestFrom)
+	}
+	if strings.HasPrefix(tag, "@") {
+		return tag, nil
+	}
+	if tag == "" {
+		tag = ":latest"
+	}
+	for _, t := range img.Tags {
+		if ":"+t.Tag == tag && t.Digest != "" {
+			return "@" + t.Digest, nil
+		}
+	}
+	return "", fmt.Errorf(
+		"image %s%s has no digest in the image index", name, tag)
+}
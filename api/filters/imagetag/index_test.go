@@ -0,0 +1,273 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package imagetag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const indexContent = `
+images:
+- name: nginx
+  tags:
+  - tag: 1.18.0
+    digest: sha256:118
+  - tag: 1.19.1
+    digest: sha256:1191
+  - tag: 1.19.2
+  - tag: 2.0.0-rc.1
+    digest: sha256:200rc1
+  - tag: latest
+    digest: sha256:latest
+- name: redis
+  tags:
+  - tag: v0.2.3
+  - tag: v0.2.9
+  - tag: v0.3.0
+`
+
+func makeIndex(t *testing.T) *Index {
+	ix, err := ParseIndex([]byte(indexContent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return ix
+}
+
+func TestParseIndexErrors(t *testing.T) {
+	for content, expected := range map[string]string{
+		"images:\n- tags: []\n":                   "image index has an image without a name",
+		"images:\n- name: nginx\n- name: nginx\n": "image nginx is in the image index twice",
+		"images:\n- name: nginx\n  tag: 1.0\n":    `error unmarshaling JSON: while decoding JSON: json: unknown field "tag"`,
+	} {
+		_, err := ParseIndex([]byte(content))
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestIndexCovers(t *testing.T) {
+	ix := makeIndex(t)
+	for ref, expected := range map[string]bool{
+		"nginx":              true,
+		"nginx:1.19.2":       true,
+		"nginx@sha256:1191":  true,
+		"nginx:1.20.0":       false,
+		"nginx@sha256:other": false,
+		"redis":              false,
+		"apache:1.19.2":      false,
+	} {
+		if actual := ix.Covers(ref); actual != expected {
+			t.Errorf("%s: expected %v, got %v", ref, expected, actual)
+		}
+	}
+}
+
+func TestIndexResolve(t *testing.T) {
+	ix := makeIndex(t)
+	testCases := map[string]struct {
+		image       types.Image
+		name, tag   string
+		expected    string
+		expectedErr string
+	}{
+		"caret": {
+			image:    types.Image{TagPolicy: "semver:^1.2"},
+			name:     "nginx",
+			expected: ":1.19.2",
+		},
+		"caret below 1.0.0": {
+			image:    types.Image{TagPolicy: "semver:^0.2.3"},
+			name:     "redis",
+			expected: ":v0.2.9",
+		},
+		"tilde": {
+			image:    types.Image{TagPolicy: "semver:~1.18"},
+			name:     "nginx",
+			expected: ":1.18.0",
+		},
+		"range": {
+			image:    types.Image{TagPolicy: "semver:>=1.18.0, <1.19.2"},
+			name:     "nginx",
+			expected: ":1.19.1",
+		},
+		"wildcard skips pre-releases": {
+			image:    types.Image{TagPolicy: "semver:*"},
+			name:     "nginx",
+			expected: ":1.19.2",
+		},
+		"digest of the tag": {
+			image:    types.Image{DigestFrom: "file"},
+			name:     "nginx",
+			tag:      ":1.19.1",
+			expected: "@sha256:1191",
+		},
+		"digest of latest": {
+			image:    types.Image{DigestFrom: "file"},
+			name:     "nginx",
+			expected: "@sha256:latest",
+		},
+		"digest of the picked tag": {
+			image:    types.Image{TagPolicy: "semver:1.18.x", DigestFrom: "file"},
+			name:     "nginx",
+			tag:      ":1.19.2",
+			expected: "@sha256:118",
+		},
+		"unknown image": {
+			image:       types.Image{DigestFrom: "file"},
+			name:        "apache",
+			expectedErr: "image apache isn't in the image index",
+		},
+		"unknown policy": {
+			image:       types.Image{TagPolicy: "latest"},
+			name:        "nginx",
+			expectedErr: `unknown tag policy "latest"`,
+		},
+		"bad range": {
+			image:       types.Image{TagPolicy: "semver:^one"},
+			name:        "nginx",
+			expectedErr: `invalid version constraint "^one"`,
+		},
+		"no tag in range": {
+			image:       types.Image{TagPolicy: "semver:^3"},
+			name:        "nginx",
+			expectedErr: "no tag of image nginx in the image index satisfies semver:^3",
+		},
+		"bad digestFrom": {
+			image:       types.Image{DigestFrom: "registry"},
+			name:        "nginx",
+			expectedErr: `digestFrom must be "file", not "registry"`,
+		},
+		"no digest": {
+			image:       types.Image{DigestFrom: "file"},
+			name:        "nginx",
+			tag:         ":1.19.2",
+			expectedErr: "image nginx:1.19.2 has no digest in the image index",
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			actual, err := ix.resolve(tc.image, tc.name, tc.tag)
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	var none *Index
+	_, err := none.resolve(types.Image{DigestFrom: "file"}, "nginx", "")
+	if err == nil || err.Error() !=
+		"image nginx has a tagPolicy or digestFrom, but there's no image index" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for tag, expected := range map[string]version{
+		"1.2.3":            {1, 2, 3, ""},
+		"v10.0.1":          {10, 0, 1, ""},
+		"1.2.3-rc.1+build": {1, 2, 3, "rc.1"},
+	} {
+		v, ok := parseVersion(tag)
+		if !ok || v != expected {
+			t.Errorf("%s: expected %v, got %v, %v", tag, expected, v, ok)
+		}
+	}
+	for _, tag := range []string{"latest", "1.2", "1.2.3.4", "01.2.3", "1.2.3-"} {
+		if _, ok := parseVersion(tag); ok {
+			t.Errorf("%s: expected no version", tag)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// In ascending order.
+	tags := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i := range tags {
+		for j := range tags {
+			v, _ := parseVersion(tags[i])
+			w, _ := parseVersion(tags[j])
+			if actual := v.compare(w); actual != sign(i-j) {
+				t.Errorf("%s vs %s: expected %d, got %d", tags[i], tags[j], sign(i-j), actual)
+			}
+		}
+	}
+}
+
+func TestIndexFilter(t *testing.T) {
+	ix := makeIndex(t)
+	input := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - image: nginx@sha256:118
+      containers:
+      - image: nginx:1.19.2
+      - image: nginx
+`
+	assert.Equal(t,
+		strings.TrimSpace(input),
+		strings.TrimSpace(filtertest.RunFilter(t, input, IndexFilter{Index: ix})))
+
+	err := kio.Pipeline{
+		Inputs: []kio.Reader{&kio.ByteReader{Reader: strings.NewReader(
+			strings.Replace(input, "nginx:1.19.2", "redis:v0.4.0", 1))}},
+		Filters: []kio.Filter{IndexFilter{Index: ix}},
+		Outputs: []kio.Writer{&kio.ByteWriter{Writer: &strings.Builder{}}},
+	}.Execute()
+	if err == nil || err.Error() !=
+		"image redis:v0.4.0 of Deployment web isn't in the image index" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFilterWithIndex(t *testing.T) {
+	input := `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: instance
+spec:
+  image: nginx:1.18.0
+`
+	f := Filter{
+		ImageTag: types.Image{Name: "nginx", TagPolicy: "semver:^1", DigestFrom: "file"},
+		FsSlice:  []types.FieldSpec{{Path: "spec/image"}},
+		Index:    makeIndex(t),
+	}
+	// 1.19.2 is the highest tag, but has no digest.
+	_, err := f.Filter([]*yaml.RNode{yaml.MustParse(input)})
+	if err == nil || !strings.HasSuffix(err.Error(),
+		"image nginx:1.19.2 has no digest in the image index") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f.ImageTag.TagPolicy = "semver:<1.19.2"
+	assert.Equal(t,
+		strings.TrimSpace(strings.Replace(input, ":1.18.0", "@sha256:1191", 1)),
+		strings.TrimSpace(filtertest.RunFilter(t, input, f)))
+}
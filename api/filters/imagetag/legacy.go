@@ -4,6 +4,8 @@
 package imagetag
 
 import (
+	"fmt"
+
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -17,6 +19,9 @@ import (
 // of the image is a match with the provided ImageTag.
 type LegacyFilter struct {
 	ImageTag types.Image `json:"imageTag,omitempty" yaml:"imageTag,omitempty"`
+
+	// Index resolves the TagPolicy and DigestFrom of ImageTag.
+	Index *Index `json:"-" yaml:"-"`
 }
 
 var _ kio.Filter = LegacyFilter{}
@@ -39,7 +44,7 @@ func (lf LegacyFilter) filter(node *yaml.RNode) (*yaml.RNode, error) {
 
 	fff := findFieldsFilter{
 		fields:        []string{"containers", "initContainers"},
-		fieldCallback: checkImageTagsFn(lf.ImageTag),
+		fieldCallback: checkImageTagsFn(lf.ImageTag, lf.Index),
 	}
 	if err := node.PipeE(fff); err != nil {
 		return nil, err
@@ -96,7 +101,7 @@ func contains(slice []string, str string) bool {
 	return false
 }
 
-func checkImageTagsFn(imageTag types.Image) fieldCallback {
+func checkImageTagsFn(imageTag types.Image, index *Index) fieldCallback {
 	return func(node *yaml.RNode) error {
 		if node.YNode().Kind != yaml.SequenceNode {
 			return nil
@@ -107,7 +112,52 @@ func checkImageTagsFn(imageTag types.Image) fieldCallback {
 			// image.
 			return n.PipeE(yaml.Get("image"), imageTagUpdater{
 				ImageTag: imageTag,
+				Index:    index,
 			})
 		})
 	}
 }
+
+// IndexFilter is an implementation of the kio.Filter
+// interface that fails if the image of a container or
+// init container of an object, found as by LegacyFilter,
+// isn't covered by the Index.
+type IndexFilter struct {
+	Index *Index `json:"-" yaml:"-"`
+}
+
+var _ kio.Filter = IndexFilter{}
+
+func (f IndexFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.filter)).Filter(nodes)
+}
+
+func (f IndexFilter) filter(node *yaml.RNode) (*yaml.RNode, error) {
+	meta, err := node.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	fff := findFieldsFilter{
+		fields: []string{"containers", "initContainers"},
+		fieldCallback: func(node *yaml.RNode) error {
+			if node.YNode().Kind != yaml.SequenceNode {
+				return nil
+			}
+			return node.VisitElements(func(n *yaml.RNode) error {
+				img := n.Field("image")
+				if img == nil || img.Value.YNode().Kind != yaml.ScalarNode {
+					return nil
+				}
+				if ref := img.Value.YNode().Value; !f.Index.Covers(ref) {
+					return fmt.Errorf("image %s of %s %s isn't in the image index",
+						ref, meta.Kind, meta.Name)
+				}
+				return nil
+			})
+		},
+	}
+	if err := node.PipeE(fff); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
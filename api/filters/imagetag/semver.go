@@ -0,0 +1,223 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package imagetag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version is a semantic version, e.g. of the tag "v1.2.3".
+// Build metadata is dropped.
+type version struct {
+	major, minor, patch int
+	pre                 string
+}
+
+// parseVersion parses the tag as a semantic version, with
+// an optional "v" prefix.
+func parseVersion(tag string) (version, bool) {
+	s := strings.TrimPrefix(tag, "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	var v version
+	if i := strings.Index(s, "-"); i >= 0 {
+		s, v.pre = s[:i], s[i+1:]
+		if v.pre == "" {
+			return v, false
+		}
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (len(p) > 1 && p[0] == '0') {
+			return v, false
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is lower than, equal to,
+// or higher than w.
+func (v version) compare(w version) int {
+	for _, d := range []int{v.major - w.major, v.minor - w.minor, v.patch - w.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.pre == w.pre:
+		return 0
+	case v.pre == "":
+		return 1
+	case w.pre == "":
+		return -1
+	}
+	return comparePre(v.pre, w.pre)
+}
+
+// comparePre compares pre-release versions, identifier by
+// identifier, numerically where both are numbers.
+func comparePre(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case as[i] != bs[i]:
+			return sign(strings.Compare(as[i], bs[i]))
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// constraint is a test of versions, e.g. ">=1.2.0".
+type constraint func(version) bool
+
+// parseConstraints parses a range of versions: constraints
+// separated by spaces or commas, all of which a version
+// in the range satisfies.  A constraint is a version,
+// possibly partial, e.g. "1.2" or "1.2.x", after one of
+// the operators "=", "!=", ">", ">=", "<", "<=", "~"
+// (patch updates) or "^" (updates not changing the
+// leftmost non-zero part).  "*" is any version.
+func parseConstraints(s string) (constraint, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty version range")
+	}
+	var cs []constraint
+	for _, f := range fields {
+		c, err := parseConstraint(f)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	return func(v version) bool {
+		for _, c := range cs {
+			if !c(v) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseConstraint(s string) (constraint, error) {
+	if s == "*" {
+		return func(version) bool { return true }, nil
+	}
+	op := s[:len(s)-len(strings.TrimLeft(s, "=!<>~^"))]
+	lower, n, err := parsePartial(strings.TrimPrefix(s[len(op):], "v"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q", s)
+	}
+	// upper is the lowest version above those that lower,
+	// a version with n parts, stands for.
+	upper := bump(lower, n)
+	switch op {
+	case "", "=":
+		return func(v version) bool {
+			return v.compare(lower) >= 0 && v.compare(upper) < 0
+		}, nil
+	case "!=":
+		return func(v version) bool {
+			return v.compare(lower) < 0 || v.compare(upper) >= 0
+		}, nil
+	case ">":
+		return func(v version) bool { return v.compare(upper) >= 0 }, nil
+	case ">=":
+		return func(v version) bool { return v.compare(lower) >= 0 }, nil
+	case "<":
+		return func(v version) bool { return v.compare(lower) < 0 }, nil
+	case "<=":
+		return func(v version) bool { return v.compare(upper) < 0 }, nil
+	case "~":
+		if n == 3 {
+			n = 2
+		}
+		upper = bump(lower, n)
+	case "^":
+		switch {
+		case lower.major > 0 || n == 1:
+			upper = bump(lower, 1)
+		case lower.minor > 0 || n == 2:
+			upper = bump(lower, 2)
+		default:
+			upper = bump(lower, 3)
+		}
+	default:
+		return nil, fmt.Errorf("invalid version constraint %q", s)
+	}
+	return func(v version) bool {
+		return v.compare(lower) >= 0 && v.compare(upper) < 0
+	}, nil
+}
+
+// parsePartial parses a version of up to three parts,
+// which, past the first, may be wildcards, returning it
+// and the number of parts given.
+func parsePartial(s string) (version, int, error) {
+	var v version
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("too many parts")
+	}
+	nums := []*int{&v.major, &v.minor, &v.patch}
+	for i, p := range parts {
+		if p == "x" || p == "X" || p == "*" {
+			if i == 0 {
+				return v, 0, fmt.Errorf("wildcard major version")
+			}
+			return v, i, nil
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, 0, fmt.Errorf("invalid part %q", p)
+		}
+		*nums[i] = n
+	}
+	return v, len(parts), nil
+}
+
+// bump returns the version after v in its nth part,
+// e.g. 1.3.0 for 1.2.5 and 2.
+func bump(v version, n int) version {
+	switch n {
+	case 1:
+		return version{major: v.major + 1}
+	case 2:
+		return version{major: v.major, minor: v.minor + 1}
+	}
+	return version{major: v.major, minor: v.minor, patch: v.patch + 1}
+}
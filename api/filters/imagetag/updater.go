@@ -15,6 +15,7 @@ import (
 type imageTagUpdater struct {
 	Kind     string      `yaml:"kind,omitempty"`
 	ImageTag types.Image `yaml:"imageTag,omitempty"`
+	Index    *Index      `yaml:"-"`
 }
 
 func (u imageTagUpdater) Filter(rn *yaml.RNode) (*yaml.RNode, error) {
@@ -38,6 +39,13 @@ func (u imageTagUpdater) Filter(rn *yaml.RNode) (*yaml.RNode, error) {
 	if u.ImageTag.Digest != "" {
 		tag = "@" + u.ImageTag.Digest
 	}
+	if u.ImageTag.TagPolicy != "" || u.ImageTag.DigestFrom != "" {
+		var err error
+		tag, err = u.Index.resolve(u.ImageTag, name, tag)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return rn.Pipe(yaml.FieldSetter{StringValue: name + tag})
 }
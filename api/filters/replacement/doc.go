@@ -0,0 +1,9 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package replacement contains a kio.Filter implementation of the kustomize
+// ReplacementTransformer.
+//
+// Filter copies a value into fields of the resources it is applied to.
+// LookupValue reads such a value out of a source resource.
+package replacement
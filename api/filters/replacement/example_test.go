@@ -0,0 +1,61 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package replacement
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func ExampleFilter() {
+	err := kio.Pipeline{
+		Inputs: []kio.Reader{&kio.ByteReader{Reader: bytes.NewBufferString(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: instance
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9
+      - name: sidecar
+        image: busybox
+`)}},
+		Filters: []kio.Filter{Filter{
+			Value: yaml.NewScalarRNode("1.8.0"),
+			FieldRefs: []string{
+				"spec.template.spec.containers[name=nginx].image",
+			},
+			Options: &types.FieldOptions{
+				Delimiter: ":",
+				Index:     1,
+			},
+		}},
+		Outputs: []kio.Writer{kio.ByteWriter{Writer: os.Stdout}},
+	}.Execute()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// apiVersion: apps/v1
+	// kind: Deployment
+	// metadata:
+	//   name: instance
+	// spec:
+	//   template:
+	//     spec:
+	//       containers:
+	//       - name: nginx
+	//         image: nginx:1.8.0
+	//       - name: sidecar
+	//         image: busybox
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package replacement
+
+import (
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter replaces the value of the fields at FieldRefs with Value.
+//
+// Field references are dot-separated paths, where each path
+// element is one of
+//
+//   - a field name or map key, e.g. 'spec'
+//   - a list entry matching a field value, e.g. '[name=nginx]'
+//   - a list index, e.g. '2'
+//
+// so that 'spec.template.spec.containers[name=nginx].image'
+// and 'spec.template.spec.containers.0.image' both work.
+type Filter struct {
+	// Value is the replacement; it may be a scalar, a map or a list.
+	Value *yaml.RNode `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// FieldRefs are the paths to the fields to replace.
+	FieldRefs []string `json:"fieldrefs,omitempty" yaml:"fieldrefs,omitempty"`
+
+	// Options, if present, select the part of each field
+	// value to replace, and whether to create missing fields.
+	Options *types.FieldOptions `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+// run processes each node individually.
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	for _, fieldRef := range f.FieldRefs {
+		if err := f.replace(node, fieldRef); err != nil {
+			return nil, errors.WrapPrefixf(err, "fieldref %s", fieldRef)
+		}
+	}
+	return node, nil
+}
+
+func (f Filter) replace(node *yaml.RNode, fieldRef string) error {
+	kind := yaml.Kind(0)
+	if f.Options != nil && f.Options.Create {
+		kind = f.Value.YNode().Kind
+	}
+	target, err := lookup(node, SplitFieldRef(fieldRef), kind)
+	if err != nil || target == nil {
+		// A missing field is only replaced if asked to create it.
+		return err
+	}
+	if f.Options == nil || f.Options.Delimiter == "" {
+		*target.YNode() = *copyNode(f.Value.YNode())
+		return nil
+	}
+	if f.Value.YNode().Kind != yaml.ScalarNode {
+		return errors.Errorf("delimiter requires a scalar replacement value")
+	}
+	if target.YNode().Kind != yaml.ScalarNode {
+		return errors.Errorf("delimiter requires a scalar field")
+	}
+	parts := strings.Split(target.YNode().Value, f.Options.Delimiter)
+	if f.Options.Index < 0 || f.Options.Index >= len(parts) {
+		return errors.Errorf(
+			"index %d is out of bounds for value %q",
+			f.Options.Index, target.YNode().Value)
+	}
+	parts[f.Options.Index] = f.Value.YNode().Value
+	target.YNode().Value = strings.Join(parts, f.Options.Delimiter)
+	target.YNode().Tag = yaml.StringTag
+	return nil
+}
+
+// LookupValue returns the value at fieldRef in the node.
+// If options specify a delimiter, only the selected
+// part of the (scalar) value is returned.
+func LookupValue(
+	node *yaml.RNode, fieldRef string,
+	options *types.FieldOptions) (*yaml.RNode, error) {
+	value, err := lookup(node, SplitFieldRef(fieldRef), 0)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "fieldref %s", fieldRef)
+	}
+	if value == nil {
+		return nil, errors.Errorf("unable to find field %s", fieldRef)
+	}
+	if options == nil || options.Delimiter == "" {
+		return value, nil
+	}
+	if value.YNode().Kind != yaml.ScalarNode {
+		return nil, errors.Errorf(
+			"delimiter requires a scalar value at %s", fieldRef)
+	}
+	parts := strings.Split(value.YNode().Value, options.Delimiter)
+	if options.Index < 0 || options.Index >= len(parts) {
+		return nil, errors.Errorf(
+			"index %d is out of bounds for value %q at %s",
+			options.Index, value.YNode().Value, fieldRef)
+	}
+	return yaml.NewScalarRNode(parts[options.Index]), nil
+}
+
+// SplitFieldRef splits a field reference into path elements.
+// Dots inside list entry matchers, e.g. '[name=a.b]', do not
+// split the path.
+func SplitFieldRef(fieldRef string) []string {
+	var result []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			result = append(result, current.String())
+			current.Reset()
+		}
+	}
+	inBrackets := false
+	for _, c := range strings.TrimPrefix(fieldRef, ".") {
+		switch {
+		case inBrackets:
+			current.WriteRune(c)
+			if c == ']' {
+				inBrackets = false
+				flush()
+			}
+		case c == '.':
+			flush()
+		case c == '[':
+			flush()
+			inBrackets = true
+			current.WriteRune(c)
+		default:
+			current.WriteRune(c)
+		}
+	}
+	flush()
+	return result
+}
+
+// lookup walks the path from the node.  If kind is non-zero,
+// missing path elements are created, with the last element
+// created with the given kind.  lookup returns nil if the
+// field doesn't exist and isn't created.
+func lookup(
+	node *yaml.RNode, path []string, kind yaml.Kind) (*yaml.RNode, error) {
+	// yaml.PathGetter knows fields and list entry matchers,
+	// but not list indices, so walk the runs between indices
+	// with PathGetter and handle the indices here.
+	start := 0
+	for i := 0; i <= len(path); i++ {
+		if i < len(path) && !isIndex(path[i]) {
+			continue
+		}
+		if i > start {
+			create := kind
+			if create != 0 && i < len(path) {
+				create = yaml.SequenceNode
+			}
+			var err error
+			node, err = node.Pipe(yaml.PathGetter{
+				Path: path[start:i], Create: create})
+			if err != nil || node == nil {
+				return nil, err
+			}
+		}
+		if i < len(path) {
+			var err error
+			node, err = element(node, path[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		start = i + 1
+	}
+	return node, nil
+}
+
+func isIndex(p string) bool {
+	_, err := strconv.Atoi(p)
+	return err == nil
+}
+
+// element returns the list element at the given index.
+func element(node *yaml.RNode, p string) (*yaml.RNode, error) {
+	if node.YNode().Kind != yaml.SequenceNode {
+		return nil, errors.Errorf("index %s used on a non-list field", p)
+	}
+	i, _ := strconv.Atoi(p)
+	if i < 0 || i >= len(node.Content()) {
+		return nil, errors.Errorf("index %s is out of bounds", p)
+	}
+	return yaml.NewRNode(node.Content()[i]), nil
+}
+
+// copyNode returns a deep copy of the node, so that one
+// value can be written to many fields.
+func copyNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i := range n.Content {
+		c.Content[i] = copyNode(n.Content[i])
+	}
+	return &c
+}
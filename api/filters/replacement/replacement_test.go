@@ -0,0 +1,310 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package replacement
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+		filter   Filter
+	}{
+		"replace scalar": {
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  clusterIP: 1.2.3.4
+`,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  clusterIP: 5.6.7.8
+`,
+			filter: Filter{
+				Value:     yaml.NewScalarRNode("5.6.7.8"),
+				FieldRefs: []string{"spec.clusterIP"},
+			},
+		},
+		"replace by list index": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+spec:
+  template:
+    spec:
+      containers:
+      - name: a
+        image: a
+      - name: b
+        image: b
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+spec:
+  template:
+    spec:
+      containers:
+      - name: a
+        image: a
+      - name: b
+        image: postgres
+`,
+			filter: Filter{
+				Value: yaml.NewScalarRNode("postgres"),
+				FieldRefs: []string{
+					"spec.template.spec.containers.1.image"},
+			},
+		},
+		"replace map": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+spec:
+  selector:
+    matchLabels:
+      app: old
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+spec:
+  selector:
+    matchLabels:
+      app: new
+      tier: backend
+`,
+			filter: Filter{
+				Value: yaml.MustParse(`
+app: new
+tier: backend
+`),
+				FieldRefs: []string{"spec.selector.matchLabels"},
+			},
+		},
+		"missing field is not created by default": {
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`,
+			filter: Filter{
+				Value:     yaml.NewScalarRNode("5.6.7.8"),
+				FieldRefs: []string{"spec.clusterIP"},
+			},
+		},
+		"create missing field": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.0
+`,
+			filter: Filter{
+				Value: yaml.NewScalarRNode("nginx:1.8.0"),
+				FieldRefs: []string{
+					"spec.template.spec.containers[name=nginx].image"},
+				Options: &types.FieldOptions{Create: true},
+			},
+		},
+		"replace part of a value": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  url: https://example.com/old/path
+`,
+			expected: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  url: https://example.com/new/path
+`,
+			filter: Filter{
+				Value:     yaml.NewScalarRNode("new"),
+				FieldRefs: []string{"data.url"},
+				Options: &types.FieldOptions{
+					Delimiter: "/",
+					Index:     3,
+				},
+			},
+		},
+		"dots in list entry matcher": {
+			input: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+  - name: a.b
+    image: old
+`,
+			expected: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+  - name: a.b
+    image: new
+`,
+			filter: Filter{
+				Value:     yaml.NewScalarRNode("new"),
+				FieldRefs: []string{".spec.containers[name=a.b].image"},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			if !assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(
+					filtertest_test.RunFilter(t, tc.input, tc.filter))) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	testCases := map[string]struct {
+		filter   Filter
+		expected string
+	}{
+		"index out of bounds": {
+			filter: Filter{
+				Value:     yaml.NewScalarRNode("x"),
+				FieldRefs: []string{"spec.containers.5.image"},
+			},
+			expected: "index 5 is out of bounds",
+		},
+		"delimiter index out of bounds": {
+			filter: Filter{
+				Value:     yaml.NewScalarRNode("x"),
+				FieldRefs: []string{"spec.containers.0.image"},
+				Options: &types.FieldOptions{
+					Delimiter: ":",
+					Index:     2,
+				},
+			},
+			expected: `index 2 is out of bounds for value "nginx:1.7.9"`,
+		},
+		"delimiter on a map": {
+			filter: Filter{
+				Value:     yaml.NewScalarRNode("x"),
+				FieldRefs: []string{"spec.containers.0"},
+				Options:   &types.FieldOptions{Delimiter: ":"},
+			},
+			expected: "delimiter requires a scalar field",
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			node := yaml.MustParse(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+  - name: nginx
+    image: nginx:1.7.9
+`)
+			_, err := tc.filter.Filter([]*yaml.RNode{node})
+			if !assert.Error(t, err) {
+				t.FailNow()
+			}
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestLookupValue(t *testing.T) {
+	node := yaml.MustParse(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+  - name: nginx
+    image: nginx:1.7.9
+`)
+	v, err := LookupValue(node, "spec.containers[name=nginx].image",
+		&types.FieldOptions{Delimiter: ":", Index: 1})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "1.7.9", v.YNode().Value)
+
+	v, err = LookupValue(node, "spec.containers", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, yaml.SequenceNode, v.YNode().Kind)
+
+	_, err = LookupValue(node, "spec.volumes", nil)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), "unable to find field spec.volumes")
+}
+
+func TestSplitFieldRef(t *testing.T) {
+	assert.Equal(t,
+		[]string{"spec", "containers", "[name=a.b]", "image"},
+		SplitFieldRef(".spec.containers[name=a.b].image"))
+	assert.Equal(t,
+		[]string{"spec", "containers", "0", "image"},
+		SplitFieldRef("spec.containers.0.image"))
+}
@@ -0,0 +1,6 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package scaling contains a kio.Filter implementation of the kustomize
+// ScalingTransformer.
+package scaling
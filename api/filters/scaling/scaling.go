@@ -0,0 +1,145 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaling
+
+import (
+	"strconv"
+
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// HorizontalPodAutoscalerKind is the kind of the objects
+// that the MinReplicas and MaxReplicas of a Scaling bound.
+const HorizontalPodAutoscalerKind = "HorizontalPodAutoscaler"
+
+// Filter applies a Scaling to objects, which it doesn't
+// select: the replica count to the fields that the
+// fieldSpecs locate, the container resources to the pod
+// specs, and the autoscaler bounds to autoscalers.
+type Filter struct {
+	Scaling types.Scaling `json:"scaling,omitempty" yaml:"scaling,omitempty"`
+
+	// FsSlice contains the FieldSpecs to locate the replicas field
+	FsSlice types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	meta, err := node.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	if meta.Kind == HorizontalPodAutoscalerKind {
+		return node, f.setBounds(node)
+	}
+	if f.Scaling.Replicas != nil {
+		err = node.PipeE(fsslice.Filter{
+			FsSlice:    f.FsSlice,
+			SetValue:   fsslice.SetScalar(strconv.FormatInt(*f.Scaling.Replicas, 10)),
+			CreateKind: yaml.ScalarNode,
+			CreateTag:  yaml.IntTag,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, cr := range f.Scaling.Resources {
+		if err = setResources(node, cr); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func (f Filter) setBounds(node *yaml.RNode) error {
+	for _, b := range []struct {
+		field string
+		value *int64
+	}{
+		{"minReplicas", f.Scaling.MinReplicas},
+		{"maxReplicas", f.Scaling.MaxReplicas},
+	} {
+		if b.value == nil {
+			continue
+		}
+		n := yaml.NewScalarRNode(strconv.FormatInt(*b.value, 10))
+		n.YNode().Tag = yaml.IntTag
+		err := node.PipeE(
+			yaml.LookupCreate(yaml.MappingNode, "spec"),
+			yaml.SetField(b.field, n))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setResources sets the resources of the containers, and
+// init containers, named in cr.
+func setResources(node *yaml.RNode, cr types.ContainerResources) error {
+	for _, path := range filtersutil.PodSpecPaths {
+		spec, err := node.Pipe(yaml.Lookup(path...))
+		if err != nil {
+			return err
+		}
+		if spec == nil || spec.YNode().Kind != yaml.MappingNode {
+			continue
+		}
+		for _, list := range []string{"containers", "initContainers"} {
+			containers := spec.Field(list)
+			if containers == nil || containers.Value.YNode().Kind != yaml.SequenceNode {
+				continue
+			}
+			err = containers.Value.VisitElements(func(c *yaml.RNode) error {
+				name := c.Field("name")
+				if name == nil || name.Value.YNode().Value != cr.Container {
+					return nil
+				}
+				if err := setQuantities(c, "requests", cr.Requests); err != nil {
+					return err
+				}
+				return setQuantities(c, "limits", cr.Limits)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setQuantities(container *yaml.RNode, field string, q *types.ResourceQuantities) error {
+	if q == nil {
+		return nil
+	}
+	for _, r := range []struct{ name, value string }{
+		{"cpu", q.CPU},
+		{"memory", q.Memory},
+	} {
+		if r.value == "" {
+			continue
+		}
+		n := yaml.NewScalarRNode(r.value)
+		n.YNode().Tag = yaml.StringTag
+		if yaml.IsYaml1_1NonString(n.YNode()) {
+			n.YNode().Style = yaml.DoubleQuotedStyle
+		}
+		err := container.PipeE(
+			yaml.LookupCreate(yaml.MappingNode, "resources", field),
+			yaml.SetField(r.name, n))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaling
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestFilter(t *testing.T) {
+	three, two, ten := int64(3), int64(2), int64(10)
+	testCases := map[string]struct {
+		input    string
+		expected string
+		scaling  types.Scaling
+	}{
+		"deployment": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: migrate
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 100m
+      - name: proxy
+        image: proxy
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: migrate
+        resources:
+          limits:
+            memory: 1Gi
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 250m
+            memory: 64Mi
+          limits:
+            cpu: "1"
+      - name: proxy
+        image: proxy
+  replicas: 3
+`,
+			scaling: types.Scaling{
+				Replicas: &three,
+				Resources: []types.ContainerResources{
+					{
+						Container: "app",
+						Requests:  &types.ResourceQuantities{CPU: "250m", Memory: "64Mi"},
+						Limits:    &types.ResourceQuantities{CPU: "1"},
+					},
+					{
+						Container: "migrate",
+						Limits:    &types.ResourceQuantities{Memory: "1Gi"},
+					},
+				},
+			},
+		},
+		"cronjob": {
+			input: `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: report
+            image: report
+`,
+			expected: `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: report
+            image: report
+            resources:
+              requests:
+                memory: 2Gi
+`,
+			scaling: types.Scaling{
+				Replicas: &three,
+				Resources: []types.ContainerResources{
+					{
+						Container: "report",
+						Requests:  &types.ResourceQuantities{Memory: "2Gi"},
+					},
+				},
+			},
+		},
+		"autoscaler": {
+			input: `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 1
+  maxReplicas: 5
+`,
+			expected: `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 2
+  maxReplicas: 10
+`,
+			scaling: types.Scaling{
+				Replicas:    &three,
+				MinReplicas: &two,
+				MaxReplicas: &ten,
+			},
+		},
+	}
+	fss := builtinconfig.MakeDefaultConfig().Replicas
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			f := Filter{Scaling: tc.scaling, FsSlice: fss}
+			if !assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(filtertest_test.RunFilter(t, tc.input, f))) {
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ module sigs.k8s.io/kustomize/api
 go 1.14
 
 require (
+	filippo.io/age v1.0.0-rc.3
 	github.com/evanphx/json-patch v4.5.0+incompatible
 	github.com/go-openapi/spec v0.19.5
 	github.com/golangci/golangci-lint v1.21.0
@@ -19,3 +20,5 @@ require (
 	sigs.k8s.io/kustomize/kyaml v0.1.11
 	sigs.k8s.io/yaml v1.2.0
 )
+
+replace sigs.k8s.io/kustomize/kyaml => ../kyaml
@@ -26,6 +26,15 @@ type KvLoader interface {
 	Load(args types.KvPairSources) (all []types.Pair, err error)
 }
 
+// EncryptedKvLoader is a KvLoader that can also read
+// encrypted sources.
+type EncryptedKvLoader interface {
+	KvLoader
+	// LoadEncrypted decrypts the sources, and reads them
+	// as Load does.
+	LoadEncrypted(args types.EncryptedKvPairSources) (all []types.Pair, err error)
+}
+
 // Loader interface exposes methods to read bytes.
 type Loader interface {
 	// Root returns the root location for this Loader.
@@ -38,6 +47,15 @@ type Loader interface {
 	Cleanup() error
 }
 
+// FileLister is a Loader that can also list the files
+// of a directory.
+type FileLister interface {
+	Loader
+	// ListFiles returns the paths of the files in the tree
+	// of the directory at the location, relative to it.
+	ListFiles(location string) ([]string, error)
+}
+
 // Kunstructured allows manipulation of k8s objects
 // that do not have Golang structs.
 type Kunstructured interface {
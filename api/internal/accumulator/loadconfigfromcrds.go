@@ -122,11 +122,30 @@ const (
 func loadCrdIntoConfig(
 	theConfig *builtinconfig.TransformerConfig, theGvk resid.Gvk, theMap nameToApiMap,
 	typeName string, path []string) (err error) {
+	return loadTypeIntoConfig(
+		theConfig, theGvk, theMap, typeName, path, map[string]bool{})
+}
+
+// loadTypeIntoConfig loads the type into the config,
+// skipping the types in seen, which hold it, to stop at
+// recursive types.
+func loadTypeIntoConfig(
+	theConfig *builtinconfig.TransformerConfig, theGvk resid.Gvk, theMap nameToApiMap,
+	typeName string, path []string, seen map[string]bool) (err error) {
 	api, ok := theMap[typeName]
-	if !ok {
+	if !ok || seen[typeName] {
 		return nil
 	}
-	for propName, property := range api.Schema.SchemaProps.Properties {
+	seen[typeName] = true
+	defer delete(seen, typeName)
+	return loadPropertiesIntoConfig(
+		theConfig, theGvk, theMap, api.Schema.SchemaProps.Properties, path, seen)
+}
+
+func loadPropertiesIntoConfig(
+	theConfig *builtinconfig.TransformerConfig, theGvk resid.Gvk, theMap nameToApiMap,
+	properties myProperties, path []string, seen map[string]bool) (err error) {
+	for propName, property := range properties {
 		_, annotate := property.Extensions.GetString(xAnnotation)
 		if annotate {
 			err = theConfig.AddAnnotationFieldSpec(
@@ -169,16 +188,112 @@ func loadCrdIntoConfig(
 					return
 				}
 			}
+		} else if gvk, ok := inferReference(propName, property, theMap); ok {
+			err = theConfig.AddNamereferenceFieldSpec(
+				builtinconfig.NameBackReferences{
+					Gvk: gvk,
+					FieldSpecs: []types.FieldSpec{
+						makeFs(theGvk, append(path, propName, "name"))},
+				})
+			if err != nil {
+				return
+			}
 		}
-		if property.Ref.GetURL() != nil {
-			loadCrdIntoConfig(
+		s := property
+		if s.Items != nil && s.Items.Schema != nil {
+			s = *s.Items.Schema
+		}
+		if s.Ref.GetURL() != nil {
+			err = loadTypeIntoConfig(
+				theConfig, theGvk, theMap,
+				s.Ref.String(), append(path, propName), seen)
+		} else if len(s.Properties) > 0 {
+			err = loadPropertiesIntoConfig(
 				theConfig, theGvk, theMap,
-				property.Ref.String(), append(path, propName))
+				s.Properties, append(path, propName), seen)
+		}
+		if err != nil {
+			return
 		}
 	}
 	return nil
 }
 
+// referredKinds are the kinds that references name,
+// matched against the names of their fields in order.
+var referredKinds = []struct {
+	fragment string
+	gvk      resid.Gvk
+}{
+	{"serviceaccount", resid.Gvk{Version: "v1", Kind: "ServiceAccount"}},
+	{"configmap", resid.Gvk{Version: "v1", Kind: "ConfigMap"}},
+	{"secret", resid.Gvk{Version: "v1", Kind: "Secret"}},
+	{"service", resid.Gvk{Version: "v1", Kind: "Service"}},
+}
+
+// Types that refer to objects by a name field, though
+// their definitions are seldom among those of CRDs.
+var referenceTypeSuffixes = []string{
+	".LocalObjectReference",
+	".ObjectReference",
+	".SecretReference",
+	".SecretKeySelector",
+	".ConfigMapKeySelector",
+}
+
+// inferReference returns the kind that the property, or
+// the items of the list that it is, refers to by name,
+// when no extension says so.  That's when its name
+// mentions a kind, and either its name ends in "Ref" and
+// it has a name field, e.g. spec.tlsSecretRef.name, or it
+// is a LocalObjectReference, e.g. spec.imagePullSecrets.
+func inferReference(
+	propName string, property spec.Schema, theMap nameToApiMap) (resid.Gvk, bool) {
+	lower := strings.ToLower(propName)
+	var gvk resid.Gvk
+	for _, k := range referredKinds {
+		if strings.Contains(lower, k.fragment) {
+			gvk = k.gvk
+			break
+		}
+	}
+	if gvk.Kind == "" {
+		return gvk, false
+	}
+	s := property
+	if property.Items != nil && property.Items.Schema != nil {
+		s = *property.Items.Schema
+	}
+	ref := s.Ref.String()
+	props := s.Properties
+	if ref != "" {
+		api, ok := theMap[ref]
+		if !ok {
+			for _, suffix := range referenceTypeSuffixes {
+				if strings.HasSuffix(ref, suffix) {
+					return gvk, strings.HasSuffix(lower, "ref") ||
+						suffix == ".LocalObjectReference"
+				}
+			}
+			return gvk, false
+		}
+		props = api.Schema.Properties
+	}
+	if _, ok := props["name"]; !ok {
+		return gvk, false
+	}
+	if strings.HasSuffix(lower, "ref") {
+		return gvk, true
+	}
+	// Shaped like a LocalObjectReference.
+	for p := range props {
+		if p != "name" && p != "optional" {
+			return gvk, false
+		}
+	}
+	return gvk, true
+}
+
 func makeFs(in resid.Gvk, path []string) types.FieldSpec {
 	return types.FieldSpec{
 		CreateIfNotPresent: false,
@@ -5,6 +5,7 @@ package accumulator_test
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -179,3 +180,77 @@ func TestLoadCRDs(t *testing.T) {
 		t.Fatalf("expected\n %v\n but got\n %v\n", expectedTc, actualTc)
 	}
 }
+
+func TestLoadCRDsInferringReferences(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/testpath/crd.yaml", []byte(`
+example.com/v1.Proxy:
+  Schema:
+    properties:
+      apiVersion:
+        type: string
+      kind:
+        type: string
+      metadata:
+        $ref: k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta
+      spec:
+        $ref: example.com/v1.ProxySpec
+example.com/v1.ProxySpec:
+  Schema:
+    properties:
+      tlsSecretRef:
+        $ref: example.com/v1.NamedRef
+      upstreamServiceRef:
+        $ref: k8s.io/api/core/v1.ObjectReference
+      settingsConfigMap:
+        $ref: example.com/v1.NamedRef
+      imagePullSecrets:
+        type: array
+        items:
+          $ref: k8s.io/api/core/v1.LocalObjectReference
+      secretNamespace:
+        type: string
+      serviceAccountRef:
+        $ref: k8s.io/api/core/v1.LocalObjectReference
+      certificateSecret:
+        $ref: example.com/v1.Certificate
+      next:
+        $ref: example.com/v1.ProxySpec
+example.com/v1.NamedRef:
+  Schema:
+    properties:
+      name:
+        type: string
+example.com/v1.Certificate:
+  Schema:
+    properties:
+      name:
+        type: string
+      issuer:
+        type: string
+`))
+	ldr, err := loader.NewLoader(loader.RestrictionRootOnly, "/testpath", fSys)
+	if err != nil {
+		t.Fatalf("unexpected error:%v", err)
+	}
+	tc, err := LoadConfigFromCRDs(ldr, []string{"crd.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error:%v", err)
+	}
+	actual := make(map[string][]string)
+	for _, nbr := range tc.NameReference {
+		for _, fs := range nbr.FieldSpecs {
+			actual[nbr.Kind] = append(actual[nbr.Kind], fs.Path)
+		}
+		sort.Strings(actual[nbr.Kind])
+	}
+	expected := map[string][]string{
+		"ConfigMap":      {"spec/settingsConfigMap/name"},
+		"Secret":         {"spec/imagePullSecrets/name", "spec/tlsSecretRef/name"},
+		"Service":        {"spec/upstreamServiceRef/name"},
+		"ServiceAccount": {"spec/serviceAccountRef/name"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected\n %v\n but got\n %v\n", expected, actual)
+	}
+}
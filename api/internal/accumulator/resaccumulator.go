@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/kusterr"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
@@ -21,6 +22,12 @@ type ResAccumulator struct {
 	resMap  resmap.ResMap
 	tConfig *builtinconfig.TransformerConfig
 	varSet  types.VarSet
+	// Contents of the crds files of the kustomizations,
+	// holding the schemas of custom resources.
+	crds [][]byte
+	// Failures of the validations of the bases and
+	// components, reported with those of the build.
+	validationFailures []kusterr.ValidationFailure
 }
 
 func MakeEmptyAccumulator() *ResAccumulator {
@@ -31,6 +38,19 @@ func MakeEmptyAccumulator() *ResAccumulator {
 	return ra
 }
 
+// DeepCopy returns a copy of the accumulator, with
+// copies of its resources.
+func (ra *ResAccumulator) DeepCopy() *ResAccumulator {
+	return &ResAccumulator{
+		resMap:  ra.resMap.DeepCopy(),
+		tConfig: ra.tConfig,
+		varSet:  ra.varSet.Copy(),
+		crds:    append([][]byte(nil), ra.crds...),
+		validationFailures: append(
+			[]kusterr.ValidationFailure(nil), ra.validationFailures...),
+	}
+}
+
 // ResMap returns a copy of the internal resMap.
 func (ra *ResAccumulator) ResMap() resmap.ResMap {
 	return ra.resMap.ShallowCopy()
@@ -57,6 +77,28 @@ func (ra *ResAccumulator) MergeConfig(
 	return err
 }
 
+// AddCrd adds the contents of a crds file.
+func (ra *ResAccumulator) AddCrd(content []byte) {
+	ra.crds = append(ra.crds, content)
+}
+
+// Crds returns the contents of the crds files.
+func (ra *ResAccumulator) Crds() [][]byte {
+	return ra.crds
+}
+
+// AddValidationFailures adds failures of the validations
+// of a base or component.
+func (ra *ResAccumulator) AddValidationFailures(fs []kusterr.ValidationFailure) {
+	ra.validationFailures = append(ra.validationFailures, fs...)
+}
+
+// ValidationFailures returns the failures of the
+// validations of the bases and components.
+func (ra *ResAccumulator) ValidationFailures() []kusterr.ValidationFailure {
+	return ra.validationFailures
+}
+
 func (ra *ResAccumulator) GetTransformerConfig() *builtinconfig.TransformerConfig {
 	return ra.tConfig
 }
@@ -93,6 +135,9 @@ func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
 	if err != nil {
 		return err
 	}
+	ra.crds = append(ra.crds, other.crds...)
+	ra.validationFailures = append(
+		ra.validationFailures, other.validationFailures...)
 	return ra.varSet.MergeSet(other.varSet)
 }
 
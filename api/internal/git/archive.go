@@ -0,0 +1,379 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+// ArchiveCloner returns a Cloner that fetches archives
+// (tarballs and OCI artifacts) with the given http client,
+// and unpacks them into the Dir of the spec, or a new
+// temporary directory.  Git repositories are passed to
+// the given cloner.
+func ArchiveCloner(hc *http.Client, cloner Cloner) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		if repoSpec.Archive == "" {
+			return cloner(repoSpec)
+		}
+		if repoSpec.Dir == "" || repoSpec.Dir == notCloned {
+			dir, err := filesys.NewTmpConfirmedDir()
+			if err != nil {
+				return err
+			}
+			repoSpec.Dir = dir
+		}
+		f := &archiveFetcher{hc: hc, dir: repoSpec.Dir.String()}
+		var err error
+		switch repoSpec.Archive {
+		case Tarball:
+			err = f.fetchTarball(repoSpec.CloneSpec(), repoSpec.Digest)
+		case OCIArtifact:
+			err = f.fetchArtifact(repoSpec)
+		default:
+			err = fmt.Errorf("unknown archive type %q", repoSpec.Archive)
+		}
+		return errors.Wrapf(err, "trouble fetching %s", repoSpec.ArchiveUrl())
+	}
+}
+
+// Limits on what the archives of a spec may extract to,
+// against archives made to fill the disk.  Vars, so that
+// tests can lower them.
+var (
+	maxExtractedSize    int64 = 1 << 30
+	maxExtractedEntries       = 100000
+)
+
+// archiveFetcher downloads archives and unpacks them in dir.
+type archiveFetcher struct {
+	hc  *http.Client
+	dir string
+	// Bearer token got from a registry's token service.
+	token string
+	// What's been extracted so far, held to the limits.
+	size    int64
+	entries int
+}
+
+func (f *archiveFetcher) fetchTarball(url, digest string) error {
+	file, err := f.download(url, "", digest)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	return f.untar(file)
+}
+
+const (
+	ociManifestMediaTypes = "application/vnd.oci.image.manifest.v1+json, " +
+		"application/vnd.docker.distribution.manifest.v2+json"
+	ociTitleAnnotation = "org.opencontainers.image.title"
+)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// fetchArtifact unpacks the layers of an artifact, in
+// order.  Tar layers are extracted; other layers are
+// written to the file named by their title annotation,
+// as done by e.g. 'oras push'.
+func (f *archiveFetcher) fetchArtifact(repoSpec *RepoSpec) error {
+	registry := strings.TrimSuffix(
+		strings.TrimPrefix(repoSpec.Host, ociScheme), "/")
+	base := "https://" + registry + "/v2/" + repoSpec.OrgRepo
+	reference := repoSpec.Ref
+	if repoSpec.Digest != "" {
+		reference = repoSpec.Digest
+	}
+	file, err := f.download(
+		base+"/manifests/"+reference, ociManifestMediaTypes, repoSpec.Digest)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	var m ociManifest
+	if err = json.NewDecoder(file).Decode(&m); err != nil {
+		return errors.Wrap(err, "unable to read manifest")
+	}
+	if len(m.Layers) == 0 {
+		return fmt.Errorf("manifest of %s has no layers", reference)
+	}
+	for _, layer := range m.Layers {
+		if err = f.fetchLayer(base, layer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *archiveFetcher) fetchLayer(base string, layer ociDescriptor) error {
+	// Blobs are addressed by content, so their digest
+	// is always checked.
+	if !strings.HasPrefix(layer.Digest, "sha256:") {
+		return fmt.Errorf("unsupported layer digest '%s'", layer.Digest)
+	}
+	file, err := f.download(base+"/blobs/"+layer.Digest, "", layer.Digest)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if strings.Contains(layer.MediaType, "tar") {
+		return f.untar(file)
+	}
+	title := layer.Annotations[ociTitleAnnotation]
+	if title == "" {
+		return fmt.Errorf(
+			"layer %s is neither a tar nor titled", layer.Digest)
+	}
+	path, err := extractionPath(f.dir, title)
+	if err != nil {
+		return err
+	}
+	if err = f.addEntry(); err != nil {
+		return err
+	}
+	return f.writeFile(path, file, 0644)
+}
+
+// download gets url into a temporary file, which the
+// caller must remove, checking its digest if one's given.
+func (f *archiveFetcher) download(
+	url, accept, digest string) (*os.File, error) {
+	resp, err := f.get(url, accept)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	file, err := ioutil.TempFile("", "kustomize-archive-")
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(file, h), resp.Body); err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err == nil && digest != "" {
+		if actual := fmt.Sprintf("sha256:%x", h.Sum(nil)); actual != digest {
+			err = fmt.Errorf(
+				"digest mismatch for %s: expected %s, got %s",
+				url, digest, actual)
+		}
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return file, nil
+}
+
+// get sends a GET request, answering a registry's
+// challenge for an anonymous bearer token if need be.
+func (f *archiveFetcher) get(url, accept string) (*http.Response, error) {
+	resp, err := f.do(url, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && f.token == "" {
+		challenge := resp.Header.Get("Www-Authenticate")
+		resp.Body.Close()
+		if f.token, err = f.fetchToken(challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = f.do(url, accept); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+func (f *archiveFetcher) do(url, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if f.token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.token)
+	}
+	hc := f.hc
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return hc.Do(req)
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// fetchToken gets a token as directed by a challenge like
+// Bearer realm="https://auth.example.com/token",service="x",scope="y"
+func (f *archiveFetcher) fetchToken(challenge string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+	params := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("bad realm in auth challenge %q", challenge)
+	}
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if v, ok := params[k]; ok {
+			q.Set(k, v)
+		}
+	}
+	realm.RawQuery = q.Encode()
+	resp, err := f.do(realm.String(), "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", realm, resp.Status)
+	}
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", errors.Wrap(err, "unable to read token")
+	}
+	if t.Token == "" {
+		t.Token = t.AccessToken
+	}
+	if t.Token == "" {
+		return "", fmt.Errorf("no token from %s", realm)
+	}
+	return t.Token, nil
+}
+
+// untar extracts a tar, gzipped or not, into dir.  Only
+// directories and regular files are allowed, and none
+// may land outside dir.
+func (f *archiveFetcher) untar(r io.Reader) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil &&
+		magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "unable to read tar")
+		}
+		path, err := extractionPath(f.dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err = f.addEntry(); err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg:
+			err = f.writeFile(path, tr, os.FileMode(hdr.Mode).Perm()|0600)
+		case tar.TypeXGlobalHeader:
+			// pax metadata, e.g. from 'git archive'
+		default:
+			err = fmt.Errorf(
+				"unsupported type of tar entry '%s'", hdr.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractionPath returns the path in dir of an archive
+// entry, refusing entries that would escape dir.
+func extractionPath(dir, name string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", fmt.Errorf(
+			"security; archive entry '%s' is outside the archive", name)
+	}
+	return path, nil
+}
+
+// addEntry counts an extracted entry, failing if there
+// are too many.
+func (f *archiveFetcher) addEntry() error {
+	f.entries++
+	if f.entries > maxExtractedEntries {
+		return fmt.Errorf(
+			"archive has more than %d entries", maxExtractedEntries)
+	}
+	return nil
+}
+
+// writeFile writes the file, failing if it takes what's
+// extracted past the size limit.
+func (f *archiveFetcher) writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(out, io.LimitReader(r, maxExtractedSize-f.size+1))
+	f.size += n
+	if err == nil && f.size > maxExtractedSize {
+		err = fmt.Errorf(
+			"archive extracts to more than %d bytes", maxExtractedSize)
+	}
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,231 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name: name, Mode: 0644, Size: int64(len(content)),
+			Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func digestOf(b []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+}
+
+func fetch(t *testing.T, srv *httptest.Server, url string) (string, error) {
+	rs, err := NewRepoSpecFromUrl(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ArchiveCloner(srv.Client(), nil)(rs)
+	if rs.Dir != notCloned {
+		t.Cleanup(func() { os.RemoveAll(rs.Dir.String()) })
+	}
+	return rs.AbsPath(), err
+}
+
+func readFile(t *testing.T, path string) string {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestArchiveClonerTarball(t *testing.T) {
+	tgz := makeTarGz(t, map[string]string{
+		"base/kustomization.yaml": "resources: []\n",
+	})
+	srv := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/bases/base.tar.gz" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(tgz)
+		}))
+	defer srv.Close()
+
+	path, err := fetch(t, srv, srv.URL+"/bases/base.tar.gz//base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readFile(t, filepath.Join(path, "kustomization.yaml")); got != "resources: []\n" {
+		t.Fatalf("unexpected content: %s", got)
+	}
+
+	_, err = fetch(t, srv, srv.URL+"/bases/base.tar.gz@"+digestOf(tgz)+"//base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = fetch(t, srv, srv.URL+"/bases/base.tar.gz@"+digestOf(nil)+"//base")
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch, got %v", err)
+	}
+	_, err = fetch(t, srv, srv.URL+"/bases/missing.tar.gz")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	// A query, e.g. the token of a signed url, doesn't hide
+	// the extension.
+	path, err = fetch(t, srv, srv.URL+"/bases/base.tar.gz?token=abc//base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readFile(t, filepath.Join(path, "kustomization.yaml")); got != "resources: []\n" {
+		t.Fatalf("unexpected content: %s", got)
+	}
+}
+
+func TestArchiveClonerLimits(t *testing.T) {
+	tgz := makeTarGz(t, map[string]string{
+		"a.yaml": "0123456789",
+		"b.yaml": "0123456789",
+	})
+	srv := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { w.Write(tgz) }))
+	defer srv.Close()
+	defer func(size int64, entries int) {
+		maxExtractedSize, maxExtractedEntries = size, entries
+	}(maxExtractedSize, maxExtractedEntries)
+
+	maxExtractedSize, maxExtractedEntries = 20, 2
+	if _, err := fetch(t, srv, srv.URL+"/base.tgz"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	maxExtractedSize, maxExtractedEntries = 15, 2
+	_, err := fetch(t, srv, srv.URL+"/base.tgz")
+	if err == nil || !strings.Contains(err.Error(), "archive extracts to more than 15 bytes") {
+		t.Fatalf("expected size error, got %v", err)
+	}
+	maxExtractedSize, maxExtractedEntries = 20, 1
+	_, err = fetch(t, srv, srv.URL+"/base.tgz")
+	if err == nil || !strings.Contains(err.Error(), "archive has more than 1 entries") {
+		t.Fatalf("expected entries error, got %v", err)
+	}
+}
+
+func TestArchiveClonerRejectsEscapingEntries(t *testing.T) {
+	tgz := makeTarGz(t, map[string]string{"../evil.yaml": "x"})
+	srv := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { w.Write(tgz) }))
+	defer srv.Close()
+	_, err := fetch(t, srv, srv.URL+"/base.tgz")
+	if err == nil || !strings.Contains(err.Error(), "outside the archive") {
+		t.Fatalf("expected security error, got %v", err)
+	}
+}
+
+// A registry stand-in serving one artifact, which
+// demands a token as e.g. ghcr.io does for anonymous pulls.
+func makeRegistry(t *testing.T) (*httptest.Server, string) {
+	layer := makeTarGz(t, map[string]string{
+		"app/kustomization.yaml": "namePrefix: app-\n",
+	})
+	readme := []byte("hello\n")
+	var manifest []byte
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				if r.URL.Query().Get("scope") != "repository:bases/app:pull" {
+					http.Error(w, "bad scope", http.StatusBadRequest)
+					return
+				}
+				w.Write([]byte(`{"token": "t0k3n"}`))
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer t0k3n" {
+				w.Header().Set("Www-Authenticate", `Bearer realm="`+srv.URL+
+					`/token",service="registry",scope="repository:bases/app:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch r.URL.Path {
+			case "/v2/bases/app/manifests/v1",
+				"/v2/bases/app/manifests/" + digestOf(manifest),
+				// A lie, to be caught by the digest check.
+				"/v2/bases/app/manifests/" + digestOf(nil):
+				w.Write(manifest)
+			case "/v2/bases/app/blobs/" + digestOf(layer):
+				w.Write(layer)
+			case "/v2/bases/app/blobs/" + digestOf(readme):
+				w.Write(readme)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	manifest = []byte(fmt.Sprintf(`{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "layers": [
+    {"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip",
+     "digest": %q},
+    {"mediaType": "text/plain", "digest": %q,
+     "annotations": {"org.opencontainers.image.title": "app/README"}}
+  ]
+}`, digestOf(layer), digestOf(readme)))
+	return srv, digestOf(manifest)
+}
+
+func TestArchiveClonerOCIArtifact(t *testing.T) {
+	srv, digest := makeRegistry(t)
+	defer srv.Close()
+	registry := "oci://" + strings.TrimPrefix(srv.URL, "https://")
+
+	for _, ref := range []string{":v1", "@" + digest, ":v1@" + digest} {
+		path, err := fetch(t, srv, registry+"/bases/app"+ref+"//app")
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", ref, err)
+		}
+		if got := readFile(t, filepath.Join(path, "kustomization.yaml")); got != "namePrefix: app-\n" {
+			t.Fatalf("unexpected content: %s", got)
+		}
+		if got := readFile(t, filepath.Join(path, "README")); got != "hello\n" {
+			t.Fatalf("unexpected content: %s", got)
+		}
+	}
+
+	_, err := fetch(t, srv, registry+"/bases/app:v1@"+digestOf(nil))
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch, got %v", err)
+	}
+	_, err = fetch(t, srv, registry+"/bases/app:v2")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
@@ -4,8 +4,13 @@
 package git
 
 import (
+	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
 	"os/exec"
+	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -16,15 +21,19 @@ type Cloner func(repoSpec *RepoSpec) error
 
 // ClonerUsingGitExec uses a local git install, as opposed
 // to say, some remote API, to obtain a local clone of
-// a remote repo.
+// a remote repo.  The clone is made in the repoSpec's
+// directory if one was already chosen, else in a new
+// temporary directory.
 func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	gitProgram, err := exec.LookPath("git")
 	if err != nil {
 		return errors.Wrap(err, "no 'git' program on path")
 	}
-	repoSpec.Dir, err = filesys.NewTmpConfirmedDir()
-	if err != nil {
-		return err
+	if repoSpec.Dir == "" || repoSpec.Dir == notCloned {
+		repoSpec.Dir, err = filesys.NewTmpConfirmedDir()
+		if err != nil {
+			return err
+		}
 	}
 
 	if repoSpec.Ref == "" {
@@ -85,6 +94,87 @@ func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	return nil
 }
 
+var (
+	commitHash      = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	abbreviatedHash = regexp.MustCompile(`^[0-9a-f]{4,39}$`)
+)
+
+// CommitOf uses a local git install to find the commit
+// that the repoSpec's ref (a branch, tag or commit, or
+// HEAD if empty) names in the remote repo.  A ref that
+// names no branch or tag, but looks like an abbreviated
+// commit, is expanded in a bare clone of the repo, since
+// remotes only list their refs.
+func CommitOf(repoSpec *RepoSpec) (string, error) {
+	if commitHash.MatchString(repoSpec.Ref) {
+		return repoSpec.Ref, nil
+	}
+	gitProgram, err := exec.LookPath("git")
+	if err != nil {
+		return "", errors.Wrap(err, "no 'git' program on path")
+	}
+	ref := repoSpec.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	// Ask for the peeled ref too, which for an annotated
+	// tag is the commit rather than the tag object.
+	out, err := exec.Command(
+		gitProgram, "ls-remote", repoSpec.CloneSpec(),
+		ref, ref+"^{}").Output()
+	if err != nil {
+		return "", errors.Wrapf(
+			err, "trouble listing refs of %s", repoSpec.CloneSpec())
+	}
+	var commit string
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if commit == "" || strings.HasSuffix(fields[1], "^{}") {
+			commit = fields[0]
+		}
+	}
+	if commit == "" && abbreviatedHash.MatchString(ref) {
+		return expandCommit(gitProgram, repoSpec.CloneSpec(), ref)
+	}
+	if commit == "" {
+		return "", fmt.Errorf(
+			"no ref %s in %s", ref, repoSpec.CloneSpec())
+	}
+	return commit, nil
+}
+
+// expandCommit returns the full hash of the abbreviated
+// commit in the repo.
+func expandCommit(gitProgram, repo, abbreviated string) (string, error) {
+	dir, err := ioutil.TempDir("", "kustomize-commit-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	// Servers that can't filter out blobs ignore the
+	// filter and send them anyway.
+	out, err := exec.Command(
+		gitProgram, "clone", "--bare", "--filter=blob:none",
+		repo, dir).CombinedOutput()
+	if err != nil {
+		log.Printf("Error cloning git repo: %s", out)
+		return "", errors.Wrapf(err, "trouble cloning git repo %s", repo)
+	}
+	cmd := exec.Command(
+		gitProgram, "rev-parse", "--verify", "--quiet",
+		abbreviated+"^{commit}")
+	cmd.Dir = dir
+	out, err = cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf(
+			"no ref or commit %s in %s", abbreviated, repo)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // DoNothingCloner returns a cloner that only sets
 // cloneDir field in the repoSpec.  It's assumed that
 // the cloneDir is associated with some fake filesystem
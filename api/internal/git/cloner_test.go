@@ -0,0 +1,64 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// makeRepo makes a git repository with one commit, and
+// returns its directory and the commit.
+func makeRepo(t *testing.T) (string, string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("no git program on path")
+	}
+	dir, err := ioutil.TempDir("", "kustomize-git-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "--quiet", "--allow-empty", "-m", "first"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			os.RemoveAll(dir)
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return dir, strings.TrimSpace(string(out))
+}
+
+func TestCommitOf(t *testing.T) {
+	dir, commit := makeRepo(t)
+	defer os.RemoveAll(dir)
+
+	for _, ref := range []string{"", "v1", commit, commit[:7]} {
+		actual, err := CommitOf(&RepoSpec{Host: "file://", OrgRepo: dir, Ref: ref})
+		if err != nil {
+			t.Fatalf("ref %q: unexpected error: %v", ref, err)
+		}
+		if actual != commit {
+			t.Fatalf("ref %q: expected %s, got %s", ref, commit, actual)
+		}
+	}
+	_, err := CommitOf(&RepoSpec{Host: "file://", OrgRepo: dir, Ref: "abcdef0"})
+	if err == nil || !strings.Contains(err.Error(), "no ref or commit abcdef0") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+// RepoCache holds copies of remote repositories in a
+// directory on disk, in entries named by a hash of what
+// was copied, e.g. a repository and ref.
+//
+// A RepoCache is meant to live for one build: an entry
+// is filled at most once per RepoCache, however many times
+// it's asked for.  An entry left in the directory by an
+// earlier build is reused if it's younger than the TTL.
+//
+// An entry is a directory of copies, each named by when
+// it was made, of which the newest is used.  Other builds
+// may be reading any of them, so a copy is never changed
+// or removed once made; remove the directory to reclaim
+// the space.  One process at a time makes a copy for an
+// entry, holding its lock file, and meanwhile others use
+// the newest copy, however old.
+type RepoCache struct {
+	dir string
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	once sync.Once
+	dir  string
+	err  error
+}
+
+const (
+	// lockFileName is the name of the lock file of an entry.
+	lockFileName = ".lock"
+
+	// staleLockAge is the age past which the lock file of
+	// an entry is taken to be left by a process that died.
+	staleLockAge = time.Hour
+
+	// fillPrefix begins the names of the directories in
+	// which copies are made, before they're published.
+	fillPrefix = ".fill-"
+)
+
+// NewRepoCache returns a RepoCache keeping its entries in dir.
+func NewRepoCache(dir string, ttl time.Duration) *RepoCache {
+	return &RepoCache{
+		dir:     dir,
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Get returns the directory of the entry for the given
+// key.  If the entry is missing or stale, fill is called
+// with an empty directory to populate, which becomes the
+// newest copy of the entry once fill succeeds.
+func (c *RepoCache) Get(
+	key string, fill func(dir string) error) (string, error) {
+	name := fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	if !ok {
+		e = &cacheEntry{}
+		c.entries[name] = e
+	}
+	c.mu.Unlock()
+	e.once.Do(func() {
+		e.dir, e.err = c.fill(name, fill)
+	})
+	return e.dir, e.err
+}
+
+func (c *RepoCache) fill(
+	name string, fill func(dir string) error) (string, error) {
+	entry := filepath.Join(c.dir, name)
+	newest, made := newestCopy(entry)
+	if newest != "" && time.Since(made) < c.ttl {
+		return newest, nil
+	}
+	if err := os.MkdirAll(entry, 0700); err != nil {
+		return "", errors.Wrapf(err, "unable to make cache dir %s", entry)
+	}
+	unlock, err := lockEntry(entry)
+	if err != nil {
+		return "", err
+	}
+	if unlock != nil {
+		defer unlock()
+		// Another process may have made a copy meanwhile.
+		newest, made = newestCopy(entry)
+		if newest != "" && time.Since(made) < c.ttl {
+			return newest, nil
+		}
+	} else if newest != "" {
+		// Another process is making a copy.
+		return newest, nil
+	}
+	// Copies are made under unique names, so that a copy
+	// made without the lock, for want of any other, is safe.
+	tmp, err := ioutil.TempDir(entry, fillPrefix)
+	if err != nil {
+		return "", err
+	}
+	if err = fill(tmp); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	dir := filepath.Join(entry, fmt.Sprintf("%020d-%s",
+		time.Now().UnixNano(),
+		strings.TrimPrefix(filepath.Base(tmp), fillPrefix)))
+	if err = os.Rename(tmp, dir); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return dir, nil
+}
+
+// newestCopy returns the newest copy in the entry, and
+// when it was made, or an empty path if there's none.
+func newestCopy(entry string) (string, time.Time) {
+	infos, err := ioutil.ReadDir(entry)
+	if err != nil {
+		return "", time.Time{}
+	}
+	var newest string
+	var made int64
+	for _, info := range infos {
+		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		i := strings.Index(info.Name(), "-")
+		if i < 0 {
+			continue
+		}
+		t, err := strconv.ParseInt(info.Name()[:i], 10, 64)
+		if err != nil || t < made {
+			continue
+		}
+		newest, made = filepath.Join(entry, info.Name()), t
+	}
+	return newest, time.Unix(0, made)
+}
+
+// lockEntry takes the lock of the entry, returning the
+// function releasing it, or nil if another process holds
+// it.  A stale lock is taken over.
+func lockEntry(entry string) (func(), error) {
+	path := filepath.Join(entry, lockFileName)
+	for i := 0; i < 2; i++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrapf(err, "unable to lock cache entry %s", entry)
+		}
+		info, err := os.Stat(path)
+		if err == nil && time.Since(info.ModTime()) < staleLockAge {
+			return nil, nil
+		}
+		os.Remove(path)
+	}
+	return nil, nil
+}
+
+// Cloner returns a Cloner that keeps one clone per
+// repository and ref (or archive) in the cache, made
+// with the given cloner.  The given cloner must clone
+// into the Dir of the spec it's passed.
+func (c *RepoCache) Cloner(cloner Cloner) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		key := repoSpec.CloneSpec() + refQuery + repoSpec.Ref
+		if repoSpec.Archive != "" {
+			key = repoSpec.ArchiveUrl()
+		}
+		dir, err := c.Get(
+			key,
+			func(dir string) error {
+				clone := *repoSpec
+				clone.Dir = filesys.ConfirmedDir(dir)
+				return cloner(&clone)
+			})
+		if err != nil {
+			return err
+		}
+		repoSpec.Dir = filesys.ConfirmedDir(dir)
+		repoSpec.Cached = true
+		return nil
+	}
+}
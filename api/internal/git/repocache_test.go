@@ -0,0 +1,178 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingCloner writes the ref of the spec into the clone
+// directory, and counts how often it's called.
+type countingCloner struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *countingCloner) clone(rs *RepoSpec) error {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+	return ioutil.WriteFile(
+		filepath.Join(rs.Dir.String(), "ref"), []byte(rs.Ref), 0600)
+}
+
+func makeCacheDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "kustomize-repocache-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func readRef(t *testing.T, rs *RepoSpec) string {
+	b, err := ioutil.ReadFile(filepath.Join(rs.Dir.String(), "ref"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRepoCacheClonesOncePerRepoAndRef(t *testing.T) {
+	dir := makeCacheDir(t)
+	defer os.RemoveAll(dir)
+	var cc countingCloner
+	cloner := NewRepoCache(dir, 0).Cloner(cc.clone)
+
+	var wg sync.WaitGroup
+	specs := make([]*RepoSpec, 10)
+	for i := range specs {
+		ref := "v1"
+		if i%2 == 1 {
+			ref = "v2"
+		}
+		rs, err := NewRepoSpecFromUrl(
+			"github.com/someOrg/someRepo/path" + refQuery + ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specs[i] = rs
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cloner(rs); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cc.count != 2 {
+		t.Fatalf("expected 2 clones, got %d", cc.count)
+	}
+	for _, rs := range specs {
+		if got := readRef(t, rs); got != rs.Ref {
+			t.Fatalf("expected clone of %s, got %s", rs.Ref, got)
+		}
+		if !rs.Cached {
+			t.Fatalf("expected %s to be marked as cached", rs.Dir)
+		}
+		if filepath.Dir(filepath.Dir(rs.Dir.String())) != dir {
+			t.Fatalf("expected %s to be in %s", rs.Dir, dir)
+		}
+	}
+	if specs[0].Dir == specs[1].Dir {
+		t.Fatalf("expected distinct clones of distinct refs")
+	}
+}
+
+func TestRepoCacheTTL(t *testing.T) {
+	dir := makeCacheDir(t)
+	defer os.RemoveAll(dir)
+	var cc countingCloner
+	clone := func(ttl time.Duration) string {
+		rs, err := NewRepoSpecFromUrl(
+			"github.com/someOrg/someRepo" + refQuery + "v1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = NewRepoCache(dir, ttl).Cloner(cc.clone)(rs); err != nil {
+			t.Fatal(err)
+		}
+		if got := readRef(t, rs); got != "v1" {
+			t.Fatalf("expected clone of v1, got %s", got)
+		}
+		return rs.Dir.String()
+	}
+
+	// Each RepoCache is one build.
+	first := clone(time.Hour)
+	if clone(time.Hour) != first {
+		t.Fatalf("expected the clone to be reused")
+	}
+	if cc.count != 1 {
+		t.Fatalf("expected the clone to be reused, got %d clones", cc.count)
+	}
+	if clone(0) == first {
+		t.Fatalf("expected a stale clone to be replaced")
+	}
+	if cc.count != 2 {
+		t.Fatalf("expected a stale clone to be replaced, got %d clones", cc.count)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one cache entry, got %d", len(entries))
+	}
+	// A build may still be reading the stale clone.
+	if _, err = os.Stat(filepath.Join(first, "ref")); err != nil {
+		t.Fatalf("expected the stale clone to be kept: %v", err)
+	}
+}
+
+func TestRepoCacheLocked(t *testing.T) {
+	dir := makeCacheDir(t)
+	defer os.RemoveAll(dir)
+	var cc countingCloner
+	clone := func() string {
+		rs, err := NewRepoSpecFromUrl(
+			"github.com/someOrg/someRepo" + refQuery + "v1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = NewRepoCache(dir, 0).Cloner(cc.clone)(rs); err != nil {
+			t.Fatal(err)
+		}
+		if got := readRef(t, rs); got != "v1" {
+			t.Fatalf("expected clone of v1, got %s", got)
+		}
+		return rs.Dir.String()
+	}
+	first := clone()
+	lock := filepath.Join(filepath.Dir(first), lockFileName)
+	if err := ioutil.WriteFile(lock, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Another process is refilling the entry.
+	if clone() != first || cc.count != 1 {
+		t.Fatalf("expected the stale clone to be used while locked")
+	}
+	// That process died long ago.
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if clone() == first || cc.count != 2 {
+		t.Fatalf("expected a stale lock to be taken over")
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Fatalf("expected the lock to be released, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ package git
 
 import (
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -44,8 +45,34 @@ type RepoSpec struct {
 
 	// e.g. .git or empty in case of _git is present
 	GitSuffix string
+
+	// Cached is true if Dir belongs to a RepoCache, and
+	// so must outlive the loaders that read from it.
+	Cached bool
+
+	// Archive is the kind of archive holding the files,
+	// or empty if the repository is a git repository.
+	Archive ArchiveType
+
+	// Digest, e.g. sha256:abc..., pins the content of an
+	// archive; the archive is rejected if it doesn't match.
+	Digest string
 }
 
+// ArchiveType is a kind of archive, as opposed to a
+// git repository, from which files can be fetched.
+type ArchiveType string
+
+const (
+	// Tarball is a tar file, perhaps gzipped, fetched
+	// with http(s), e.g. https://example.com/base.tar.gz
+	Tarball ArchiveType = "tarball"
+
+	// OCIArtifact is an artifact in an OCI registry,
+	// e.g. oci://registry.example.com/bases/app:v1
+	OCIArtifact ArchiveType = "oci"
+)
+
 // CloneSpec returns a string suitable for "git clone {spec}".
 func (x *RepoSpec) CloneSpec() string {
 	if isAzureHost(x.Host) || isAWSHost(x.Host) {
@@ -54,6 +81,19 @@ func (x *RepoSpec) CloneSpec() string {
 	return x.Host + x.OrgRepo + x.GitSuffix
 }
 
+// ArchiveUrl returns the archive's url without the path
+// within the archive, e.g. oci://example.com/app:v1.
+func (x *RepoSpec) ArchiveUrl() string {
+	url := x.CloneSpec()
+	if x.Ref != "" {
+		url += ":" + x.Ref
+	}
+	if x.Digest != "" {
+		url += "@" + x.Digest
+	}
+	return url
+}
+
 func (x *RepoSpec) CloneDir() filesys.ConfirmedDir {
 	return x.Dir
 }
@@ -67,16 +107,26 @@ func (x *RepoSpec) AbsPath() string {
 }
 
 func (x *RepoSpec) Cleaner(fSys filesys.FileSystem) func() error {
-	return func() error { return fSys.RemoveAll(x.Dir.String()) }
+	return func() error {
+		if x.Cached {
+			return nil
+		}
+		return fSys.RemoveAll(x.Dir.String())
+	}
 }
 
 // From strings like git@github.com:someOrg/someRepo.git or
 // https://github.com/someOrg/someRepo?ref=someHash, extract
-// the parts.
+// the parts.  Archives are specified by strings like
+// https://example.com/base.tar.gz//someDir or
+// oci://example.com/someRepo:someTag@sha256:someHash//someDir.
 func NewRepoSpecFromUrl(n string) (*RepoSpec, error) {
 	if filepath.IsAbs(n) {
 		return nil, fmt.Errorf("uri looks like abs path: %s", n)
 	}
+	if isArchiveUrl(n) {
+		return newRepoSpecFromArchiveUrl(n)
+	}
 	host, orgRepo, path, gitRef, gitSuffix := parseGitUrl(n)
 	if orgRepo == "" {
 		return nil, fmt.Errorf("url lacks orgRepo: %s", n)
@@ -90,6 +140,7 @@ func NewRepoSpecFromUrl(n string) (*RepoSpec, error) {
 }
 
 const (
+	ociScheme     = "oci://"
 	refQuery      = "?ref="
 	refQueryRegex = "\\?(version|ref)="
 	gitSuffix     = ".git"
@@ -217,3 +268,89 @@ func isAzureHost(host string) bool {
 func isAWSHost(host string) bool {
 	return strings.Contains(host, "amazonaws.com")
 }
+
+var tarballSuffixes = []string{".tar.gz", ".tgz", ".tar"}
+
+// isArchiveUrl is true for oci:// urls, and http(s)
+// urls of tarballs.
+func isArchiveUrl(n string) bool {
+	if strings.HasPrefix(n, ociScheme) {
+		return true
+	}
+	if !strings.HasPrefix(n, "https://") &&
+		!strings.HasPrefix(n, "http://") {
+		return false
+	}
+	archive, _ := splitArchivePath(n)
+	archive, _ = peelDigest(archive)
+	// The path, less e.g. a query holding a token.
+	u, err := url.Parse(archive)
+	if err != nil {
+		return false
+	}
+	for _, s := range tarballSuffixes {
+		if strings.HasSuffix(u.Path, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func newRepoSpecFromArchiveUrl(n string) (*RepoSpec, error) {
+	archive, path := splitArchivePath(n)
+	archive, digest := peelDigest(archive)
+	if digest != "" && !strings.HasPrefix(digest, "sha256:") {
+		return nil, fmt.Errorf(
+			"unsupported digest '%s' in %s; want sha256:<hex>", digest, n)
+	}
+	rs := &RepoSpec{
+		raw: n, Dir: notCloned, Path: path,
+		Archive: Tarball, Digest: digest}
+	if strings.HasPrefix(archive, ociScheme) {
+		rs.Archive = OCIArtifact
+		archive = archive[len(ociScheme):]
+		// A tag follows the last colon after the last slash,
+		// unlike the colon of a port.
+		if i := strings.LastIndex(archive, ":"); i > strings.LastIndex(archive, "/") {
+			archive, rs.Ref = archive[:i], archive[i+1:]
+		}
+		if rs.Ref == "" && digest == "" {
+			rs.Ref = "latest"
+		}
+		rs.Host = ociScheme
+	} else {
+		i := strings.Index(archive, "://") + len("://")
+		rs.Host, archive = archive[:i], archive[i:]
+	}
+	i := strings.Index(archive, "/")
+	if i < 1 || i == len(archive)-1 {
+		return nil, fmt.Errorf("url lacks orgRepo: %s", n)
+	}
+	rs.Host += archive[:i+1]
+	rs.OrgRepo = archive[i+1:]
+	return rs, nil
+}
+
+// splitArchivePath splits an archive url from the
+// path within the archive that follows a double slash.
+func splitArchivePath(n string) (string, string) {
+	i := strings.Index(n, "://")
+	if i < 0 {
+		return n, ""
+	}
+	i += len("://")
+	j := strings.Index(n[i:], "//")
+	if j < 0 {
+		return n, ""
+	}
+	return n[:i+j], n[i+j+2:]
+}
+
+// peelDigest splits off a trailing @sha256:... digest.
+func peelDigest(n string) (string, string) {
+	i := strings.LastIndex(n, "@")
+	if i < 0 || i < strings.LastIndex(n, "/") {
+		return n, ""
+	}
+	return n[:i], n[i+1:]
+}
@@ -286,3 +286,87 @@ func TestIsAWSHost(t *testing.T) {
 		}
 	}
 }
+
+func TestNewRepoSpecFromUrl_Archives(t *testing.T) {
+	testcases := []struct {
+		input      string
+		archive    ArchiveType
+		cloneSpec  string
+		ref        string
+		digest     string
+		absPath    string
+		archiveUrl string
+	}{
+		{
+			input:      "https://example.com/bases/base.tar.gz//app",
+			archive:    Tarball,
+			cloneSpec:  "https://example.com/bases/base.tar.gz",
+			absPath:    notCloned.Join("app"),
+			archiveUrl: "https://example.com/bases/base.tar.gz",
+		},
+		{
+			input:      "http://example.com:8080/base.tgz@sha256:abc",
+			archive:    Tarball,
+			cloneSpec:  "http://example.com:8080/base.tgz",
+			digest:     "sha256:abc",
+			absPath:    notCloned.String(),
+			archiveUrl: "http://example.com:8080/base.tgz@sha256:abc",
+		},
+		{
+			input:      "https://example.com/base.tar.gz?token=abc//app",
+			archive:    Tarball,
+			cloneSpec:  "https://example.com/base.tar.gz?token=abc",
+			absPath:    notCloned.Join("app"),
+			archiveUrl: "https://example.com/base.tar.gz?token=abc",
+		},
+		{
+			input:      "oci://registry.example.com/bases/app:v1//overlays/prod",
+			archive:    OCIArtifact,
+			cloneSpec:  "oci://registry.example.com/bases/app",
+			ref:        "v1",
+			absPath:    notCloned.Join("overlays/prod"),
+			archiveUrl: "oci://registry.example.com/bases/app:v1",
+		},
+		{
+			input:      "oci://localhost:5000/app",
+			archive:    OCIArtifact,
+			cloneSpec:  "oci://localhost:5000/app",
+			ref:        "latest",
+			absPath:    notCloned.String(),
+			archiveUrl: "oci://localhost:5000/app:latest",
+		},
+		{
+			input:      "oci://localhost:5000/app@sha256:abc//base",
+			archive:    OCIArtifact,
+			cloneSpec:  "oci://localhost:5000/app",
+			digest:     "sha256:abc",
+			absPath:    notCloned.Join("base"),
+			archiveUrl: "oci://localhost:5000/app@sha256:abc",
+		},
+	}
+	for _, tc := range testcases {
+		rs, err := NewRepoSpecFromUrl(tc.input)
+		if err != nil {
+			t.Fatalf("unexpected error on %s: %v", tc.input, err)
+		}
+		if rs.Archive != tc.archive || rs.CloneSpec() != tc.cloneSpec ||
+			rs.Ref != tc.ref || rs.Digest != tc.digest ||
+			rs.AbsPath() != tc.absPath || rs.ArchiveUrl() != tc.archiveUrl {
+			t.Errorf("unexpected spec from %s: %+v", tc.input, rs)
+		}
+	}
+
+	for _, input := range []string{
+		"oci://registry.example.com",
+		"https://example.com/base.tar.gz@md5:abc",
+	} {
+		if _, err := NewRepoSpecFromUrl(input); err == nil {
+			t.Errorf("expected error on %s", input)
+		}
+	}
+	// Git repositories aren't archives.
+	rs, err := NewRepoSpecFromUrl("https://github.com/someOrg/someRepo//base")
+	if err != nil || rs.Archive != "" {
+		t.Errorf("unexpected spec %+v, %v", rs, err)
+	}
+}
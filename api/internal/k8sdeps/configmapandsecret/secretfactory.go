@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/types"
 )
 
@@ -31,6 +32,11 @@ func (f *Factory) MakeSecret(args *types.SecretArgs) (*corev1.Secret, error) {
 	if err != nil {
 		return nil, err
 	}
+	decrypted, err := f.loadEncrypted(args.EncryptedKvPairSources)
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, decrypted...)
 	s := makeFreshSecret(args)
 	for _, p := range all {
 		err = f.addKvToSecret(s, p.Key, p.Value)
@@ -42,6 +48,22 @@ func (f *Factory) MakeSecret(args *types.SecretArgs) (*corev1.Secret, error) {
 	return s, nil
 }
 
+// loadEncrypted reads the encrypted sources, if any,
+// failing if the KvLoader of the factory can't decrypt.
+func (f *Factory) loadEncrypted(
+	args types.EncryptedKvPairSources) ([]types.Pair, error) {
+	if len(args.EncryptedEnvSources) == 0 &&
+		len(args.EncryptedFileSources) == 0 {
+		return nil, nil
+	}
+	ldr, ok := f.kvLdr.(ifc.EncryptedKvLoader)
+	if !ok {
+		return nil, fmt.Errorf(
+			"the kv loader %T can't read encrypted sources", f.kvLdr)
+	}
+	return ldr.LoadEncrypted(args)
+}
+
 func (f *Factory) addKvToSecret(secret *corev1.Secret, keyName, data string) error {
 	if err := f.kvLdr.Validator().ErrIfInvalidKey(keyName); err != nil {
 		return err
@@ -5,11 +5,13 @@ package configmapandsecret
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/kv"
 	"sigs.k8s.io/kustomize/api/loader"
 	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
@@ -152,3 +154,33 @@ func TestConstructSecret(t *testing.T) {
 		}
 	}
 }
+
+// plainKvLoader is a KvLoader that can't decrypt.
+type plainKvLoader struct {
+	ifc.KvLoader
+}
+
+func TestMakeSecretEncryptedNeedsEncryptedKvLoader(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/secret/app.env", []byte("DB_USERNAME=admin\n"))
+	f := NewFactory(plainKvLoader{kv.NewLoader(
+		loader.NewFileLoaderAtRoot(fSys),
+		valtest_test.MakeFakeValidator())})
+	args := types.SecretArgs{
+		GeneratorArgs: types.GeneratorArgs{
+			Name: "plain",
+			KvPairSources: types.KvPairSources{
+				EnvSources: []string{"/secret/app.env"},
+			},
+		},
+	}
+	if _, err := f.MakeSecret(&args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args.EncryptedKvPairSources.EncryptedEnvSources =
+		[]string{"/secret/app.env.age"}
+	_, err := f.MakeSecret(&args)
+	if err == nil || !strings.Contains(err.Error(), "can't read encrypted sources") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
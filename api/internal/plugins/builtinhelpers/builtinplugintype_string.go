@@ -10,24 +10,30 @@ func _() {
 	var x [1]struct{}
 	_ = x[Unknown-0]
 	_ = x[AnnotationsTransformer-1]
-	_ = x[ConfigMapGenerator-2]
-	_ = x[HashTransformer-3]
-	_ = x[ImageTagTransformer-4]
-	_ = x[LabelTransformer-5]
-	_ = x[LegacyOrderTransformer-6]
-	_ = x[NamespaceTransformer-7]
-	_ = x[PatchJson6902Transformer-8]
-	_ = x[PatchStrategicMergeTransformer-9]
-	_ = x[PatchTransformer-10]
-	_ = x[PrefixSuffixTransformer-11]
-	_ = x[ReplicaCountTransformer-12]
-	_ = x[SecretGenerator-13]
-	_ = x[ValueAddTransformer-14]
+	_ = x[ChecksumTransformer-2]
+	_ = x[ConfigMapGenerator-3]
+	_ = x[FieldTransformer-4]
+	_ = x[HashTransformer-5]
+	_ = x[HelmChartInflationGenerator-6]
+	_ = x[ImageTagTransformer-7]
+	_ = x[InventoryTransformer-8]
+	_ = x[LabelTransformer-9]
+	_ = x[LegacyOrderTransformer-10]
+	_ = x[NamespaceTransformer-11]
+	_ = x[PatchJson6902Transformer-12]
+	_ = x[PatchStrategicMergeTransformer-13]
+	_ = x[PatchTransformer-14]
+	_ = x[PrefixSuffixTransformer-15]
+	_ = x[ReplacementTransformer-16]
+	_ = x[ReplicaCountTransformer-17]
+	_ = x[ScalingTransformer-18]
+	_ = x[SecretGenerator-19]
+	_ = x[ValueAddTransformer-20]
 }
 
-const _BuiltinPluginType_name = "UnknownAnnotationsTransformerConfigMapGeneratorHashTransformerImageTagTransformerLabelTransformerLegacyOrderTransformerNamespaceTransformerPatchJson6902TransformerPatchStrategicMergeTransformerPatchTransformerPrefixSuffixTransformerReplicaCountTransformerSecretGeneratorValueAddTransformer"
+const _BuiltinPluginType_name = "UnknownAnnotationsTransformerChecksumTransformerConfigMapGeneratorFieldTransformerHashTransformerHelmChartInflationGeneratorImageTagTransformerInventoryTransformerLabelTransformerLegacyOrderTransformerNamespaceTransformerPatchJson6902TransformerPatchStrategicMergeTransformerPatchTransformerPrefixSuffixTransformerReplacementTransformerReplicaCountTransformerScalingTransformerSecretGeneratorValueAddTransformer"
 
-var _BuiltinPluginType_index = [...]uint16{0, 7, 29, 47, 62, 81, 97, 119, 139, 163, 193, 209, 232, 255, 270, 289}
+var _BuiltinPluginType_index = [...]uint16{0, 7, 29, 48, 66, 82, 97, 124, 143, 163, 179, 201, 221, 245, 275, 291, 314, 336, 359, 377, 392, 411}
 
 func (i BuiltinPluginType) String() string {
 	if i < 0 || i >= BuiltinPluginType(len(_BuiltinPluginType_index)-1) {
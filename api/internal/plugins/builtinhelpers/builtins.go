@@ -14,9 +14,13 @@ type BuiltinPluginType int
 const (
 	Unknown BuiltinPluginType = iota
 	AnnotationsTransformer
+	ChecksumTransformer
 	ConfigMapGenerator
+	FieldTransformer
 	HashTransformer
+	HelmChartInflationGenerator
 	ImageTagTransformer
+	InventoryTransformer
 	LabelTransformer
 	LegacyOrderTransformer
 	NamespaceTransformer
@@ -24,7 +28,9 @@ const (
 	PatchStrategicMergeTransformer
 	PatchTransformer
 	PrefixSuffixTransformer
+	ReplacementTransformer
 	ReplicaCountTransformer
+	ScalingTransformer
 	SecretGenerator
 	ValueAddTransformer
 )
@@ -55,14 +61,18 @@ func GetBuiltinPluginType(n string) BuiltinPluginType {
 }
 
 var GeneratorFactories = map[BuiltinPluginType]func() resmap.GeneratorPlugin{
-	ConfigMapGenerator: builtins.NewConfigMapGeneratorPlugin,
-	SecretGenerator:    builtins.NewSecretGeneratorPlugin,
+	ConfigMapGenerator:          builtins.NewConfigMapGeneratorPlugin,
+	HelmChartInflationGenerator: builtins.NewHelmChartInflationGeneratorPlugin,
+	SecretGenerator:             builtins.NewSecretGeneratorPlugin,
 }
 
 var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin{
 	AnnotationsTransformer:         builtins.NewAnnotationsTransformerPlugin,
+	ChecksumTransformer:            builtins.NewChecksumTransformerPlugin,
+	FieldTransformer:               builtins.NewFieldTransformerPlugin,
 	HashTransformer:                builtins.NewHashTransformerPlugin,
 	ImageTagTransformer:            builtins.NewImageTagTransformerPlugin,
+	InventoryTransformer:           builtins.NewInventoryTransformerPlugin,
 	LabelTransformer:               builtins.NewLabelTransformerPlugin,
 	LegacyOrderTransformer:         builtins.NewLegacyOrderTransformerPlugin,
 	NamespaceTransformer:           builtins.NewNamespaceTransformerPlugin,
@@ -70,6 +80,8 @@ var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin
 	PatchStrategicMergeTransformer: builtins.NewPatchStrategicMergeTransformerPlugin,
 	PatchTransformer:               builtins.NewPatchTransformerPlugin,
 	PrefixSuffixTransformer:        builtins.NewPrefixSuffixTransformerPlugin,
+	ReplacementTransformer:         builtins.NewReplacementTransformerPlugin,
 	ReplicaCountTransformer:        builtins.NewReplicaCountTransformerPlugin,
+	ScalingTransformer:             builtins.NewScalingTransformerPlugin,
 	ValueAddTransformer:            builtins.NewValueAddTransformerPlugin,
 }
@@ -72,6 +72,10 @@ func (b *Compiler) Compile() error {
 		"plugin",
 		"-o", b.objFile(),
 	}
+	if raceEnabled {
+		// A plugin must be built like the program loading it.
+		commands = append(commands, "-race")
+	}
 	goBin := utils.GoBin()
 	if !utils.FileExists(goBin) {
 		return fmt.Errorf(
@@ -0,0 +1,8 @@
+// +build !race
+
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package compiler
+
+const raceEnabled = false
@@ -0,0 +1,10 @@
+// +build race
+
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package compiler
+
+// raceEnabled is true when kustomize is built with the race
+// detector, which plugins must then be built with too.
+const raceEnabled = true
@@ -22,7 +22,7 @@ import (
 )
 
 const (
-	idAnnotation        = "kustomize.config.k8s.io/id"
+	IdAnnotation        = "kustomize.config.k8s.io/id"
 	HashAnnotation      = "kustomize.config.k8s.io/needs-hash"
 	BehaviorAnnotation  = "kustomize.config.k8s.io/behavior"
 	tmpConfigFilePrefix = "kust-plugin-config-"
@@ -197,7 +197,7 @@ func (p *ExecPlugin) getResMapWithIdAnnotation(rm resmap.ResMap) (resmap.ResMap,
 		if annotations == nil {
 			annotations = make(map[string]string)
 		}
-		annotations[idAnnotation] = string(idString)
+		annotations[IdAnnotation] = string(idString)
 		r.SetAnnotations(annotations)
 	}
 	return inputRM, nil
@@ -214,10 +214,10 @@ func (p *ExecPlugin) updateResMapValues(output []byte, rm resmap.ResMap) error {
 		// for each emitted Resource, find the matching Resource in the original ResMap
 		// using its id
 		annotations := r.GetAnnotations()
-		idString, ok := annotations[idAnnotation]
+		idString, ok := annotations[IdAnnotation]
 		if !ok {
 			return fmt.Errorf("the transformer %s should not remove annotation %s",
-				p.path, idAnnotation)
+				p.path, IdAnnotation)
 		}
 		id := resid.ResId{}
 		err := yaml.Unmarshal([]byte(idString), &id)
@@ -229,7 +229,7 @@ func (p *ExecPlugin) updateResMapValues(output []byte, rm resmap.ResMap) error {
 			return fmt.Errorf("unable to find unique match to %s", id.String())
 		}
 		// remove the annotation set by Kustomize to track the resource
-		delete(annotations, idAnnotation)
+		delete(annotations, IdAnnotation)
 		if len(annotations) == 0 {
 			annotations = nil
 		}
@@ -244,6 +244,13 @@ func (p *ExecPlugin) updateResMapValues(output []byte, rm resmap.ResMap) error {
 // updateResourceOptions updates the generator options for each resource in the
 // given ResMap based on plugin provided annotations.
 func (p *ExecPlugin) UpdateResourceOptions(rm resmap.ResMap) (resmap.ResMap, error) {
+	return UpdateResourceOptions(rm)
+}
+
+// UpdateResourceOptions updates the generator options of the
+// generated resources in the given ResMap from the annotations
+// that the generator set on them.
+func UpdateResourceOptions(rm resmap.ResMap) (resmap.ResMap, error) {
 	for _, r := range rm.Resources() {
 		// Disable name hashing by default and require plugin to explicitly
 		// request it for each resource.
@@ -0,0 +1,255 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fnplugin runs KRM functions, configs annotated
+// with config.kubernetes.io/function, as generators,
+// transformers and validators.
+package fnplugin
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// FnPlugin runs a function as a plugin.  Its config is
+// passed to the function as the functionConfig of the
+// ResourceList.
+type FnPlugin struct {
+	// The function config.
+	cfg *yaml.RNode
+
+	// The runtime of the function, from the annotation.
+	spec *runtimeutil.FunctionSpec
+
+	// PluginHelpers
+	h *resmap.PluginHelpers
+}
+
+func NewFnPlugin() *FnPlugin {
+	return &FnPlugin{}
+}
+
+// IsFnConfig returns whether the resource is the config
+// of a function.
+func IsFnConfig(res *resource.Resource) bool {
+	y, err := res.AsYAML()
+	if err != nil {
+		return false
+	}
+	n, err := yaml.Parse(string(y))
+	if err != nil {
+		return false
+	}
+	return runtimeutil.GetFunctionSpec(n) != nil
+}
+
+func (p *FnPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
+	p.h = h
+	cfg, err := yaml.Parse(string(config))
+	if err != nil {
+		return err
+	}
+	p.spec = runtimeutil.GetFunctionSpec(cfg)
+	if p.spec == nil {
+		return fmt.Errorf("missing annotation %s", runtimeutil.FunctionAnnotationKey)
+	}
+	p.cfg = cfg
+	_, err = p.filter()
+	return err
+}
+
+// filter returns a new filter that runs the function.
+func (p *FnPlugin) filter() (kio.Filter, error) {
+	switch {
+	case p.spec.Container.Image != "":
+		f := &container.Filter{Image: p.spec.Container.Image}
+		f.Exec.FunctionConfig = p.cfg
+		f.Exec.GlobalScope = true
+		return f, nil
+	case p.spec.Starlark.Path != "" || p.spec.Starlark.URL != "":
+		f := &starlark.Filter{
+			Name: p.spec.Starlark.Name,
+			URL:  p.spec.Starlark.URL,
+			Path: p.spec.Starlark.Path,
+			Args: p.spec.Starlark.Args,
+		}
+		// read the program, and the modules it loads, through
+		// the loader, which keeps them within the load restrictions
+		f.ReadFile = p.h.Loader().Load
+		f.FunctionConfig = p.cfg
+		f.GlobalScope = true
+		return f, nil
+	case p.spec.Exec.Path != "":
+		path := p.spec.Exec.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(p.h.Loader().Root(), path)
+		}
+		f := &exec.Filter{Path: path}
+		f.FunctionConfig = p.cfg
+		f.GlobalScope = true
+		return f, nil
+	}
+	return nil, fmt.Errorf(
+		"function has no container, starlark or exec runtime")
+}
+
+// run runs the function on the nodes.
+func (p *FnPlugin) run(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	f, err := p.filter()
+	if err != nil {
+		return nil, err
+	}
+	return f.Filter(nodes)
+}
+
+func (p *FnPlugin) Generate() (resmap.ResMap, error) {
+	output, err := p.run(nil)
+	if err != nil {
+		return nil, err
+	}
+	rm := resmap.New()
+	if err = p.updateResMap(rm, output); err != nil {
+		return nil, err
+	}
+	return execplugin.UpdateResourceOptions(rm)
+}
+
+// Transform replaces the resources of the ResMap with the
+// output of the function, which may change, add and remove
+// resources.  The resources that it changes keep what
+// kustomize knows about them, e.g. their original names.
+func (p *FnPlugin) Transform(rm resmap.ResMap) error {
+	input, err := toNodes(rm)
+	if err != nil {
+		return err
+	}
+	output, err := p.run(input)
+	if err != nil {
+		return err
+	}
+	return p.updateResMap(rm, output)
+}
+
+// toNodes returns the resources of the ResMap as nodes,
+// annotated with their ResIds so that the function output
+// can be matched with them.
+func toNodes(rm resmap.ResMap) ([]*yaml.RNode, error) {
+	var nodes []*yaml.RNode
+	for _, r := range rm.Resources() {
+		y, err := r.AsYAML()
+		if err != nil {
+			return nil, err
+		}
+		n, err := yaml.Parse(string(y))
+		if err != nil {
+			return nil, err
+		}
+		id, err := sigsyaml.Marshal(r.CurId())
+		if err != nil {
+			return nil, err
+		}
+		err = n.PipeE(yaml.SetAnnotation(execplugin.IdAnnotation, string(id)))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// updateResMap sets the resources of the ResMap to the
+// function output.
+func (p *FnPlugin) updateResMap(rm resmap.ResMap, output []*yaml.RNode) error {
+	var resources []*resource.Resource
+	matched := map[*resource.Resource]bool{}
+	for _, n := range output {
+		id, err := n.Pipe(yaml.GetAnnotation(execplugin.IdAnnotation))
+		if err != nil {
+			return err
+		}
+		// remove the annotations set to track the resources
+		for _, a := range []string{
+			execplugin.IdAnnotation, kioutil.IndexAnnotation, kioutil.PathAnnotation} {
+			if err = n.PipeE(yaml.ClearAnnotation(a)); err != nil {
+				return err
+			}
+		}
+		y, err := n.String()
+		if err != nil {
+			return err
+		}
+		r, err := p.h.ResmapFactory().RF().FromBytes([]byte(y))
+		if err != nil {
+			return err
+		}
+		if len(r.GetAnnotations()) == 0 {
+			r.SetAnnotations(nil)
+		}
+		if id != nil {
+			orig, err := originalResource(rm, id.YNode().Value)
+			if err != nil {
+				return err
+			}
+			if matched[orig] {
+				// the function copied the resource
+				orig = orig.DeepCopy()
+			}
+			matched[orig] = true
+			orig.Kunstructured = r.Kunstructured
+			r = orig
+		}
+		resources = append(resources, r)
+	}
+	rm.Clear()
+	for _, r := range resources {
+		if err := rm.Append(r); err != nil {
+			return errors.Wrap(err, "function output")
+		}
+	}
+	return nil
+}
+
+func originalResource(rm resmap.ResMap, idString string) (*resource.Resource, error) {
+	id := resid.ResId{}
+	if err := sigsyaml.Unmarshal([]byte(idString), &id); err != nil {
+		return nil, err
+	}
+	r, err := rm.GetByCurrentId(id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find unique match to %s", id.String())
+	}
+	return r, nil
+}
+
+// String returns a description of the function for
+// error messages.
+func (p *FnPlugin) String() string {
+	var b bytes.Buffer
+	switch {
+	case p.spec == nil:
+	case p.spec.Container.Image != "":
+		fmt.Fprintf(&b, "container %s", p.spec.Container.Image)
+	case p.spec.Starlark.Path != "":
+		fmt.Fprintf(&b, "starlark %s", p.spec.Starlark.Path)
+	case p.spec.Starlark.URL != "":
+		fmt.Fprintf(&b, "starlark %s", p.spec.Starlark.URL)
+	case p.spec.Exec.Path != "":
+		fmt.Fprintf(&b, "exec %s", p.spec.Exec.Path)
+	}
+	return b.String()
+}
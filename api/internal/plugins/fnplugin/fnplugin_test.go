@@ -0,0 +1,118 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fnplugin_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	. "sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
+	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+)
+
+const fnConfig = `apiVersion: example.com/v1
+kind: Rename
+metadata:
+  name: rename
+  annotations:
+    config.kubernetes.io/function: |
+      starlark: {path: rename.star, name: rename}
+`
+
+func makeHelpers(t *testing.T, program string) *resmap.PluginHelpers {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("rename.star", []byte(program))
+	ldr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, filesys.Separator, fSys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf := resmap.NewFactory(
+		resource.NewFactory(
+			kunstruct.NewKunstructuredFactoryImpl()), nil)
+	return resmap.NewPluginHelpers(ldr, valtest_test.MakeFakeValidator(), rf)
+}
+
+func TestIsFnConfig(t *testing.T) {
+	h := makeHelpers(t, "")
+	r, err := h.ResmapFactory().RF().FromBytes([]byte(fnConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsFnConfig(r) {
+		t.Fatalf("expected a function config")
+	}
+	r.SetAnnotations(nil)
+	if IsFnConfig(r) {
+		t.Fatalf("expected no function config")
+	}
+}
+
+func TestFnPluginTransform(t *testing.T) {
+	h := makeHelpers(t, `
+def run(items):
+  for r in items:
+    r["metadata"]["name"] = "renamed-" + r["metadata"]["name"]
+  items.append({"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "added"}})
+
+run(ctx.resource_list["items"])
+`)
+	p := NewFnPlugin()
+	if err := p.Config(h, []byte(fnConfig)); err != nil {
+		t.Fatal(err)
+	}
+	m, err := h.ResmapFactory().NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Transform(m); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := m.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+kind: Service
+metadata:
+  name: renamed-web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added
+`
+	if string(actual) != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, actual)
+	}
+	// the renamed resource is still known by its original name
+	if m.Resources()[0].OrgId().Name != "web" {
+		t.Fatalf("unexpected original id %v", m.Resources()[0].OrgId())
+	}
+}
+
+func TestFnPluginConfigNoRuntime(t *testing.T) {
+	err := NewFnPlugin().Config(makeHelpers(t, ""), []byte(`
+apiVersion: example.com/v1
+kind: Rename
+metadata:
+  name: rename
+  annotations:
+    config.kubernetes.io/function: |
+      starlark: {name: rename}
+`))
+	if err == nil || !strings.Contains(err.Error(), "no container, starlark or exec") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -11,11 +11,13 @@ import (
 	"plugin"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resid"
@@ -35,6 +37,11 @@ func NewLoader(
 	return &Loader{pc: pc, rf: rf}
 }
 
+// Config returns the plugin configuration of the loader.
+func (l *Loader) Config() *types.PluginConfig {
+	return l.pc
+}
+
 func (l *Loader) LoadGenerators(
 	ldr ifc.Loader, v ifc.Validator, rm resmap.ResMap) ([]resmap.Generator, error) {
 	var result []resmap.Generator
@@ -131,7 +138,11 @@ func (l *Loader) loadAndConfigurePlugin(
 	} else {
 		switch l.pc.PluginRestrictions {
 		case types.PluginRestrictionsNone:
-			c, err = l.loadPlugin(res.OrgId())
+			if fnplugin.IsFnConfig(res) {
+				c = fnplugin.NewFnPlugin()
+			} else {
+				c, err = l.loadPlugin(res.OrgId())
+			}
 		case types.PluginRestrictionsBuiltinsOnly:
 			err = types.NewErrOnlyBuiltinPluginsAllowed(res.OrgId().Kind)
 		default:
@@ -147,7 +158,7 @@ func (l *Loader) loadAndConfigurePlugin(
 	if err != nil {
 		return nil, errors.Wrapf(err, "marshalling yaml from res %s", res.OrgId())
 	}
-	err = c.Config(resmap.NewPluginHelpers(ldr, v, l.rf), yaml)
+	err = c.Config(resmap.NewPluginHelpersWithConfig(ldr, v, l.rf, l.pc), yaml)
 	if err != nil {
 		return nil, errors.Wrapf(
 			err, "plugin %s fails configuration", res.OrgId())
@@ -197,10 +208,17 @@ func (l *Loader) loadPlugin(resId resid.ResId) (resmap.Configurable, error) {
 // but the loaded .so files are in shared memory, so one will get
 // "this plugin already loaded" errors if the registry is maintained
 // as a Loader instance variable.  So make it a package variable.
-var registry = make(map[string]resmap.Configurable)
+// Sibling bases are accumulated concurrently, so it's guarded by
+// registryMu.
+var (
+	registry   = make(map[string]resmap.Configurable)
+	registryMu sync.Mutex
+)
 
 func (l *Loader) loadGoPlugin(id resid.ResId) (resmap.Configurable, error) {
 	regId := relativePluginPath(id)
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	if c, ok := registry[regId]; ok {
 		return copyPlugin(c), nil
 	}
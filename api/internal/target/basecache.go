@@ -0,0 +1,190 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+)
+
+// BaseCache remembers the accumulated resources of
+// bases, so that targets sharing a base (e.g. the many
+// overlays of one repository) only accumulate it once.
+//
+// All the targets using one BaseCache must be built with
+// the same options and plugin configuration.
+type BaseCache struct {
+	mu      sync.Mutex
+	entries map[string]*baseCacheEntry
+	// waits counts, by key, the keys it waits on: those of
+	// the bases that its accumulation is getting.
+	waits map[string]map[string]int
+}
+
+type baseCacheEntry struct {
+	done chan struct{}
+	ra   *accumulator.ResAccumulator
+	err  error
+}
+
+// NewBaseCache returns an empty BaseCache.
+func NewBaseCache() *BaseCache {
+	return &BaseCache{
+		entries: make(map[string]*baseCacheEntry),
+		waits:   make(map[string]map[string]int),
+	}
+}
+
+// UseBaseCache makes the target (and the targets of its
+// bases) reuse the accumulated bases held in c.
+func (kt *KustTarget) UseBaseCache(c *BaseCache) {
+	kt.bases = c
+}
+
+// get returns a copy of the accumulation stored at key,
+// calling accumulate to make it if needed.  Failures
+// aren't remembered, since they may depend on the path
+// that led to the base (e.g. a repository cycle).
+//
+// The chain holds the keys of the bases being accumulated
+// on the path to this one.  Getting a key on the chain, or
+// one whose accumulation waits, however indirectly, on a
+// key on the chain, is a cycle, which fails rather than
+// waiting forever.
+func (c *BaseCache) get(
+	key string, chain []string,
+	accumulate func() (*accumulator.ResAccumulator, error)) (
+	*accumulator.ResAccumulator, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if c.reaches(key, chain) {
+		c.mu.Unlock()
+		return nil, fmt.Errorf(
+			"cycle detected: base '%s' depends on itself", baseRoot(key))
+	}
+	if !ok {
+		e = &baseCacheEntry{done: make(chan struct{})}
+		c.entries[key] = e
+	}
+	var parent string
+	if len(chain) > 0 {
+		parent = chain[len(chain)-1]
+		c.addWait(parent, key, 1)
+	}
+	c.mu.Unlock()
+	if parent != "" {
+		defer func() {
+			c.mu.Lock()
+			c.addWait(parent, key, -1)
+			c.mu.Unlock()
+		}()
+	}
+	if ok {
+		<-e.done
+		if e.err != nil {
+			return nil, e.err
+		}
+		return e.ra.DeepCopy(), nil
+	}
+	e.ra, e.err = accumulate()
+	if e.err != nil {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+		close(e.done)
+		return nil, e.err
+	}
+	close(e.done)
+	return e.ra.DeepCopy(), nil
+}
+
+// reaches is true if the key is on the chain, or waits on
+// a key that is.  The caller must hold c.mu.
+func (c *BaseCache) reaches(key string, chain []string) bool {
+	onChain := make(map[string]bool, len(chain))
+	for _, k := range chain {
+		onChain[k] = true
+	}
+	seen := make(map[string]bool)
+	keys := []string{key}
+	for len(keys) > 0 {
+		k := keys[len(keys)-1]
+		keys = keys[:len(keys)-1]
+		if onChain[k] {
+			return true
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		for next := range c.waits[k] {
+			keys = append(keys, next)
+		}
+	}
+	return false
+}
+
+// addWait adds n to the count of the waits of from on to.
+// The caller must hold c.mu.
+func (c *BaseCache) addWait(from, to string, n int) {
+	w, ok := c.waits[from]
+	if !ok {
+		w = make(map[string]int)
+		c.waits[from] = w
+	}
+	w[to] += n
+	if w[to] == 0 {
+		delete(w, to)
+	}
+	if len(w) == 0 {
+		delete(c.waits, from)
+	}
+}
+
+// baseRoot returns the root of the base of the key.
+func baseRoot(key string) string {
+	return strings.SplitN(key, "\x00", 2)[0]
+}
+
+// accumulateBase returns the accumulated resources of
+// the base target, from the target's BaseCache if any.
+func (kt *KustTarget) accumulateBase(
+	subKt *KustTarget) (*accumulator.ResAccumulator, error) {
+	if kt.bases == nil {
+		return subKt.accumulateValidatedBase()
+	}
+	// Recorded origins are relative to the build root,
+	// so bases are only shared by builds with one root.
+	key := subKt.ldr.Root()
+	if kt.origins != nil {
+		key += "\x00" + kt.origins.root
+	}
+	// Bases built with other parameters may differ.
+	key += "\x00" + paramsKey(subKt.params)
+	return kt.bases.get(key, kt.baseChain,
+		func() (*accumulator.ResAccumulator, error) {
+			subKt.baseChain = append(
+				append([]string(nil), kt.baseChain...), key)
+			return subKt.accumulateValidatedBase()
+		})
+}
+
+// paramsKey returns a string that equals that of other
+// params only if they have the same values.
+func paramsKey(params map[string]string) string {
+	var names []string
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, k := range names {
+		fmt.Fprintf(&b, "%q=%q,", k, params[k])
+	}
+	return b.String()
+}
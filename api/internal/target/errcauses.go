@@ -0,0 +1,34 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+)
+
+// errCauses is an error made of the messages of others,
+// e.g. of the failures to load a path both as a file and
+// as a base.  It keeps them, so that kusterr.Details can
+// find which of them explains it.
+type errCauses struct {
+	format string
+	causes []error
+}
+
+// causesf formats the causes as fmt.Errorf would.
+func causesf(format string, causes ...error) error {
+	return &errCauses{format: format, causes: causes}
+}
+
+func (e *errCauses) Error() string {
+	args := make([]interface{}, len(e.causes))
+	for i, c := range e.causes {
+		args[i] = c
+	}
+	return fmt.Sprintf(e.format, args...)
+}
+
+func (e *errCauses) Unwrap() []error {
+	return e.causes
+}
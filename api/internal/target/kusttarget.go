@@ -4,22 +4,27 @@
 package target
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/builtins"
+	"sigs.k8s.io/kustomize/api/filters/imagetag"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/accumulator"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/kusterr"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/schema"
 	"sigs.k8s.io/kustomize/api/transform"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filtersutil"
 	"sigs.k8s.io/yaml"
 )
 
@@ -31,6 +36,19 @@ type KustTarget struct {
 	rFactory      *resmap.Factory
 	tFactory      resmap.PatchFactory
 	pLdr          *loader.Loader
+	kustFileName  string
+	origins       *originTracker
+	bases         *BaseCache
+	strict        bool
+	schemas       bool
+	crds          [][]byte
+	params        map[string]string
+	// Failures of the validations of the bases and
+	// components, found as they're accumulated.
+	subFailures []kusterr.ValidationFailure
+	// The keys in bases of the bases being accumulated on
+	// the path to this target.
+	baseChain []string
 }
 
 // NewKustTarget returns a new instance of KustTarget.
@@ -51,30 +69,126 @@ func NewKustTarget(
 
 // Load attempts to load the target's kustomization file.
 func (kt *KustTarget) Load() error {
-	content, err := loadKustFile(kt.ldr)
+	content, kustFileName, err := loadKustFile(kt.ldr)
 	if err != nil {
 		return err
 	}
+	path := filepath.Join(kt.ldr.Root(), kustFileName)
 	content, err = types.FixKustomizationPreUnmarshalling(content)
 	if err != nil {
-		return err
+		return &kusterr.BuildError{Kustomization: path, Err: err}
 	}
 	var k types.Kustomization
 	err = unmarshal(content, &k)
+	if err != nil || kt.strict {
+		// The schema explains what's wrong better than the
+		// decoder, and is stricter about the names of fields.
+		if vs, ok := checkKustomization(content).(schema.Violations); ok {
+			return errInvalidKustomization(path, vs)
+		}
+	}
 	if err != nil {
-		return err
+		return &kusterr.BuildError{Kustomization: path, Err: err}
 	}
 	k.FixKustomizationPostUnmarshalling()
 	errs := k.EnforceFields()
 	if len(errs) > 0 {
-		return fmt.Errorf(
+		return &kusterr.BuildError{Kustomization: path, Err: fmt.Errorf(
 			"Failed to read kustomization file under %s:\n"+
-				strings.Join(errs, "\n"), kt.ldr.Root())
+				strings.Join(errs, "\n"), kt.ldr.Root())}
 	}
 	kt.kustomization = &k
+	kt.kustFileName = kustFileName
 	return nil
 }
 
+// ValidateStrictly makes the target (and the targets of
+// its bases and components) check kustomization files
+// against their schema as they're loaded, rejecting
+// fields that the decoder would accept although their
+// names differ in case from those of the schema.
+// Call it before Load.
+func (kt *KustTarget) ValidateStrictly() {
+	kt.strict = true
+}
+
+// ValidateSchemas makes CheckValidations also check
+// every resource against its OpenAPI schema.
+func (kt *KustTarget) ValidateSchemas() {
+	kt.schemas = true
+}
+
+var (
+	kustomizationSchema     *schema.Schema
+	kustomizationSchemaOnce sync.Once
+)
+
+func checkKustomization(content []byte) error {
+	kustomizationSchemaOnce.Do(func() {
+		kustomizationSchema = schema.Kustomization()
+	})
+	return kustomizationSchema.Check(content)
+}
+
+// errInvalidKustomization returns the error of a
+// kustomization file that doesn't match its schema,
+// located at its first violation.
+func errInvalidKustomization(path string, vs schema.Violations) error {
+	return &kusterr.BuildError{
+		Kustomization: path,
+		Line:          vs[0].Line,
+		Column:        vs[0].Column,
+		Err: fmt.Errorf("invalid kustomization file %s:\n  %s",
+			path, strings.Join(strings.Split(vs.Error(), "\n"), "\n  ")),
+	}
+}
+
+// UseParams sets the build parameters that when
+// expressions refer to.  They override the defaults in
+// the params fields of the kustomization files.
+func (kt *KustTarget) UseParams(params map[string]string) {
+	kt.params = params
+}
+
+// paramValues returns the build parameters of the target:
+// the given ones, else the defaults of its kustomization.
+func (kt *KustTarget) paramValues() map[string]string {
+	result := make(map[string]string)
+	for k, v := range kt.kustomization.Params {
+		result[k] = v
+	}
+	for k, v := range kt.params {
+		result[k] = v
+	}
+	return result
+}
+
+// included returns whether an entry with the when
+// expression is part of the build.
+func (kt *KustTarget) included(when string) (bool, error) {
+	if when == "" {
+		return true, nil
+	}
+	return evalWhen(when, kt.paramValues())
+}
+
+// includedPaths returns the paths whose when expressions,
+// in conditions, hold.
+func (kt *KustTarget) includedPaths(
+	paths []string, conditions map[string]string) ([]string, error) {
+	var result []string
+	for _, p := range paths {
+		ok, err := kt.included(conditions[p])
+		if err != nil {
+			return nil, errors.Wrapf(err, "path '%s'", p)
+		}
+		if ok {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
 // Kustomization returns a copy of the immutable, internal kustomization object.
 func (kt *KustTarget) Kustomization() types.Kustomization {
 	var result types.Kustomization
@@ -83,35 +197,44 @@ func (kt *KustTarget) Kustomization() types.Kustomization {
 	return result
 }
 
-func loadKustFile(ldr ifc.Loader) ([]byte, error) {
+// annotate notes that the error happened building
+// this target.
+func (kt *KustTarget) annotate(err error) error {
+	return &kusterr.BuildError{
+		Kustomization: filepath.Join(kt.ldr.Root(), kt.kustFileName),
+		Err:           err,
+	}
+}
+
+func loadKustFile(ldr ifc.Loader) ([]byte, string, error) {
 	var content []byte
+	var fileName string
 	match := 0
 	for _, kf := range konfig.RecognizedKustomizationFileNames() {
 		c, err := ldr.Load(kf)
 		if err == nil {
 			match += 1
 			content = c
+			fileName = kf
 		}
 	}
 	switch match {
 	case 0:
-		return nil, NewErrMissingKustomization(ldr.Root())
+		return nil, "", NewErrMissingKustomization(ldr.Root())
 	case 1:
-		return content, nil
+		return content, fileName, nil
 	default:
-		return nil, fmt.Errorf(
+		return nil, "", fmt.Errorf(
 			"Found multiple kustomization files under: %s\n", ldr.Root())
 	}
 }
 
-func unmarshal(y []byte, o interface{}) error {
+func unmarshal(y []byte, k *types.Kustomization) error {
 	j, err := yaml.YAMLToJSON(y)
 	if err != nil {
 		return err
 	}
-	dec := json.NewDecoder(bytes.NewReader(j))
-	dec.DisallowUnknownFields()
-	return dec.Decode(o)
+	return k.UnmarshalStrictJSON(j)
 }
 
 // MakeCustomizedResMap creates a fully customized ResMap
@@ -131,22 +254,30 @@ func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 
 	err = kt.addHashesToNames(ra)
 	if err != nil {
-		return nil, err
+		return nil, kt.annotate(err)
 	}
 
 	// Given that names have changed (prefixs/suffixes added),
 	// fix all the back references to those names.
 	err = ra.FixBackReferences()
 	if err != nil {
-		return nil, err
+		return nil, kt.annotate(err)
 	}
 
 	// With all the back references fixed, it's OK to resolve Vars.
 	err = ra.ResolveVars()
 	if err != nil {
-		return nil, err
+		return nil, kt.annotate(err)
+	}
+
+	// Checksums are of the final contents, under the final names.
+	err = kt.addChecksums(ra)
+	if err != nil {
+		return nil, kt.annotate(err)
 	}
 
+	kt.crds = ra.Crds()
+	kt.subFailures = ra.ValidationFailures()
 	return ra.ResMap(), nil
 }
 
@@ -157,7 +288,72 @@ func (kt *KustTarget) addHashesToNames(
 	if err != nil {
 		return err
 	}
-	return ra.Transform(p)
+	return ra.Transform(
+		kt.trackTransformer(p, builtinPluginRef(builtinhelpers.HashTransformer)))
+}
+
+// rolloutMark marks, while a build is in progress, the
+// resources of kustomizations asking for rollouts on
+// change.
+const rolloutMark = "kustomize.config.k8s.io/rollout-on-change"
+
+// markForChecksums marks the resources of ra, if the
+// kustomization asks for rollouts on change, for
+// addChecksums to annotate once the build is complete.
+// ConfigMaps and Secrets aren't marked, lest the mark
+// change their hashes.
+func (kt *KustTarget) markForChecksums(ra *accumulator.ResAccumulator) {
+	if !kt.kustomization.RolloutOnChange {
+		return
+	}
+	for _, r := range ra.ResMap().Resources() {
+		if r.GetKind() == "ConfigMap" || r.GetKind() == "Secret" {
+			continue
+		}
+		annotations := r.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[rolloutMark] = "true"
+		r.SetAnnotations(annotations)
+	}
+}
+
+// addChecksums removes the marks of markForChecksums,
+// and annotates the pod templates of the marked
+// workloads with checksums of the ConfigMaps and
+// Secrets of the output.
+func (kt *KustTarget) addChecksums(
+	ra *accumulator.ResAccumulator) error {
+	m := resmap.New()
+	marked := false
+	for _, r := range ra.ResMap().Resources() {
+		annotations := r.GetAnnotations()
+		_, ok := annotations[rolloutMark]
+		if ok {
+			delete(annotations, rolloutMark)
+			if len(annotations) == 0 {
+				annotations = nil
+			}
+			r.SetAnnotations(annotations)
+			marked = true
+		}
+		if ok || r.GetKind() == "ConfigMap" || r.GetKind() == "Secret" {
+			if err := m.Append(r); err != nil {
+				return err
+			}
+		}
+	}
+	if !marked {
+		return nil
+	}
+	p := builtins.NewChecksumTransformerPlugin()
+	err := kt.configureBuiltinPlugin(p, nil, builtinhelpers.ChecksumTransformer)
+	if err != nil {
+		return err
+	}
+	return kt.trackTransformer(
+		p, builtinPluginRef(builtinhelpers.ChecksumTransformer)).Transform(m)
 }
 
 // AccumulateTarget returns a new ResAccumulator,
@@ -173,11 +369,26 @@ func (kt *KustTarget) AccumulateTarget() (
 // (or empty if the Component does not have a parent).
 func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	resRa *accumulator.ResAccumulator, err error) {
-	ra, err = kt.accumulateResources(ra, kt.kustomization.Resources)
+	defer func() {
+		if err != nil {
+			err = kt.annotate(err)
+		}
+	}()
+	resources, err := kt.includedPaths(
+		kt.kustomization.Resources, kt.kustomization.ResourceConditions)
+	if err != nil {
+		return nil, errors.Wrap(err, "accumulating resources")
+	}
+	ra, err = kt.accumulateResources(ra, resources)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating resources")
 	}
-	ra, err = kt.accumulateComponents(ra, kt.kustomization.Components)
+	components, err := kt.includedPaths(
+		kt.kustomization.Components, kt.kustomization.ComponentConditions)
+	if err != nil {
+		return nil, errors.Wrap(err, "accumulating components")
+	}
+	ra, err = kt.accumulateComponents(ra, components)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating components")
 	}
@@ -201,6 +412,13 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 		return nil, errors.Wrapf(
 			err, "merging CRDs %v", crdTc)
 	}
+	for _, path := range kt.kustomization.Crds {
+		content, err := kt.ldr.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		ra.AddCrd(content)
+	}
 	err = kt.runGenerators(ra)
 	if err != nil {
 		return nil, err
@@ -209,6 +427,15 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	if err != nil {
 		return nil, err
 	}
+	err = kt.runValidators(ra)
+	if err != nil {
+		return nil, err
+	}
+	err = kt.checkImageIndex(ra.ResMap())
+	if err != nil {
+		return nil, err
+	}
+	kt.markForChecksums(ra)
 	err = ra.MergeVars(kt.kustomization.Vars)
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -249,7 +476,14 @@ func (kt *KustTarget) configureExternalGenerators() ([]resmap.Generator, error)
 	if err != nil {
 		return nil, err
 	}
-	return kt.pLdr.LoadGenerators(kt.ldr, kt.validator, ra.ResMap())
+	gs, err := kt.pLdr.LoadGenerators(kt.ldr, kt.validator, ra.ResMap())
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range ra.ResMap().Resources() {
+		gs[i] = kt.trackGenerator(gs[i], configPluginRef(r))
+	}
+	return gs, nil
 }
 
 func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
@@ -269,32 +503,199 @@ func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
 	return ra.Transform(t)
 }
 
+// checkImageIndex fails if the kustomization has a strict
+// image index and the image of a container in m isn't in it.
+func (kt *KustTarget) checkImageIndex(m resmap.ResMap) error {
+	ii := kt.kustomization.ImageIndex
+	if ii == nil || !ii.Strict {
+		return nil
+	}
+	content, err := kt.ldr.Load(ii.Path)
+	if err != nil {
+		return err
+	}
+	ix, err := imagetag.ParseIndex(content)
+	if err != nil {
+		return errors.Wrapf(err, "image index %s", ii.Path)
+	}
+	for _, r := range m.Resources() {
+		err = filtersutil.ApplyToJSON(imagetag.IndexFilter{Index: ix}, r.Kunstructured)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (kt *KustTarget) configureExternalTransformers() ([]resmap.Transformer, error) {
 	ra := accumulator.MakeEmptyAccumulator()
 	ra, err := kt.accumulateResources(ra, kt.kustomization.Transformers)
 	if err != nil {
 		return nil, err
 	}
-	return kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	ts, err := kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range ra.ResMap().Resources() {
+		ts[i] = kt.trackTransformer(ts[i], configPluginRef(r))
+	}
+	return ts, nil
 }
 
+// runValidators runs the validators on a copy of the
+// resources, so that they can fail the build but can't
+// change its output.
+func (kt *KustTarget) runValidators(ra *accumulator.ResAccumulator) error {
+	ts, err := kt.configureValidators()
+	if err != nil {
+		return errors.Wrap(err, "loading validators")
+	}
+	for _, t := range ts {
+		err = t.Transform(ra.ResMap().DeepCopy())
+		if err != nil {
+			return errors.Wrap(err, "validation failed")
+		}
+	}
+	return nil
+}
+
+func (kt *KustTarget) configureValidators() ([]resmap.Transformer, error) {
+	ra := accumulator.MakeEmptyAccumulator()
+	ra, err := kt.accumulateResources(ra, kt.kustomization.Validators)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range ra.ResMap().Resources() {
+		ts[i] = kt.trackTransformer(ts[i], configPluginRef(r))
+	}
+	return ts, nil
+}
+
+// maxLoaders bounds the number of goroutines loading
+// paths, across all the targets of all builds.
+const maxLoaders = 8
+
+// loaderSlots holds a token for each running loader.
+var loaderSlots = make(chan struct{}, maxLoaders)
+
 // accumulateResources fills the given resourceAccumulator
 // with resources read from the given list of paths.
+// Each path is independent of its siblings, so they're
+// loaded concurrently, but their resources are added to
+// the accumulator in the order of the paths.
 func (kt *KustTarget) accumulateResources(
 	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
-	for _, path := range paths {
-		// try loading resource as file then as base (directory or git repository)
-		if errF := kt.accumulateFile(ra, path); errF != nil {
-			ldr, errL := kt.ldr.New(path)
-			if errL != nil {
-				return nil, fmt.Errorf("accumulateFile %q, loader.New %q", errF, errL)
-			}
-			var errD error
-			ra, errD = kt.accumulateDirectory(ra, ldr, false)
-			if errD != nil {
-				return nil, fmt.Errorf("accumulateFile %q, accumulateDirector: %q", errF, errD)
-			}
+	loaded := make([]*loadedResources, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		select {
+		case loaderSlots <- struct{}{}:
+			wg.Add(1)
+			go func(i int, path string) {
+				defer func() {
+					<-loaderSlots
+					wg.Done()
+				}()
+				loaded[i] = kt.loadResources(path)
+			}(i, path)
+		default:
+			// No slot is free, so load the path here
+			// rather than wait for one, which could
+			// deadlock, as loaders wait for the loaders
+			// of their own bases.
+			loaded[i] = kt.loadResources(path)
+		}
+	}
+	wg.Wait()
+	for i, l := range loaded {
+		var err error
+		ra, err = kt.mergeLoadedResources(ra, paths[i], l)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ra, nil
+}
+
+// loadedResources holds what was loaded from one path
+// of the resources field: either the resources of a file,
+// or the accumulated resources of a directory.
+type loadedResources struct {
+	// Why the path couldn't be loaded as a file.
+	errF error
+	// The resources read from the file.
+	resources resmap.ResMap
+	// The accumulated directory, and its root.
+	subRa *accumulator.ResAccumulator
+	root  string
+	// Why the path couldn't be loaded at all.
+	err error
+}
+
+// loadResources tries loading the path as a file, then
+// as a base (directory or git repository).
+func (kt *KustTarget) loadResources(path string) *loadedResources {
+	resources, errF := kt.loadFile(path)
+	if errF == nil {
+		return &loadedResources{resources: resources}
+	}
+	ldr, errL := kt.ldr.New(path)
+	if errL != nil {
+		return &loadedResources{
+			err: causesf("accumulateFile %q, loader.New %q", errF, errL)}
+	}
+	defer ldr.Cleanup()
+	subKt, errD := kt.makeSubTarget(ldr, false)
+	var subRa *accumulator.ResAccumulator
+	if errD == nil {
+		subRa, errD = kt.accumulateBase(subKt)
+		if errD != nil {
+			errD = errors.Wrapf(
+				errD, "recursed accumulation of path '%s'", ldr.Root())
+		}
+	}
+	if errD != nil {
+		return &loadedResources{
+			err: causesf("accumulateFile %q, accumulateDirector: %q", errF, errD)}
+	}
+	return &loadedResources{errF: errF, subRa: subRa, root: ldr.Root()}
+}
+
+// mergeLoadedResources adds what was loaded from the
+// path to the accumulator.
+func (kt *KustTarget) mergeLoadedResources(
+	ra *accumulator.ResAccumulator, path string,
+	l *loadedResources) (*accumulator.ResAccumulator, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	if l.subRa != nil {
+		if err := ra.MergeAccumulator(l.subRa); err != nil {
+			return nil, causesf(
+				"accumulateFile %q, accumulateDirector: %q", l.errF,
+				errors.Wrapf(err, "recursed merging from path '%s'", l.root))
 		}
+		return ra, nil
+	}
+	errF := ra.AppendAll(l.resources)
+	if errF == nil {
+		return ra, nil
+	}
+	// As when loading the file fails, fall back to
+	// trying the path as a base.
+	errF = errors.Wrapf(errF, "merging resources from '%s'", path)
+	ldr, errL := kt.ldr.New(path)
+	if errL != nil {
+		return nil, causesf("accumulateFile %q, loader.New %q", errF, errL)
+	}
+	ra, errD := kt.accumulateDirectory(ra, ldr, false)
+	if errD != nil {
+		return nil, causesf("accumulateFile %q, accumulateDirector: %q", errF, errD)
 	}
 	return ra, nil
 }
@@ -307,22 +708,28 @@ func (kt *KustTarget) accumulateComponents(
 		// Components always refer to directories
 		ldr, errL := kt.ldr.New(path)
 		if errL != nil {
-			return nil, fmt.Errorf("loader.New %q", errL)
+			return nil, causesf("loader.New %q", errL)
 		}
 		var errD error
 		ra, errD = kt.accumulateDirectory(ra, ldr, true)
 		if errD != nil {
-			return nil, fmt.Errorf("accumulateDirectory: %q", errD)
+			return nil, causesf("accumulateDirectory: %q", errD)
 		}
 	}
 	return ra, nil
 }
 
-func (kt *KustTarget) accumulateDirectory(
-	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (*accumulator.ResAccumulator, error) {
-	defer ldr.Cleanup()
+// makeSubTarget returns a loaded target for the
+// kustomization (or component) at the loader's root.
+func (kt *KustTarget) makeSubTarget(
+	ldr ifc.Loader, isComponent bool) (*KustTarget, error) {
 	subKt := NewKustTarget(
 		ldr, kt.validator, kt.rFactory, kt.tFactory, kt.pLdr)
+	subKt.origins = kt.origins
+	subKt.bases = kt.bases
+	subKt.baseChain = kt.baseChain
+	subKt.strict = kt.strict
+	subKt.params = kt.paramValues()
 	err := subKt.Load()
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -335,16 +742,29 @@ func (kt *KustTarget) accumulateDirectory(
 		return nil, fmt.Errorf(
 			"expected kind != '%s' for path '%s'", types.ComponentKind, ldr.Root())
 	}
+	return subKt, nil
+}
+
+func (kt *KustTarget) accumulateDirectory(
+	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (*accumulator.ResAccumulator, error) {
+	defer ldr.Cleanup()
+	subKt, err := kt.makeSubTarget(ldr, isComponent)
+	if err != nil {
+		return nil, err
+	}
 
 	var subRa *accumulator.ResAccumulator
 	if isComponent {
 		// Components don't create a new accumulator: the kustomization directives are added to the current accumulator
 		subRa, err = subKt.accumulateTarget(ra)
+		if err == nil {
+			err = subKt.addValidationFailures(subRa)
+		}
 		ra = accumulator.MakeEmptyAccumulator()
 	} else {
 		// Child Kustomizations create a new accumulator which resolves their kustomization directives, which will later
 		// be merged into the current accumulator.
-		subRa, err = subKt.AccumulateTarget()
+		subRa, err = kt.accumulateBase(subKt)
 	}
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -358,17 +778,18 @@ func (kt *KustTarget) accumulateDirectory(
 	return ra, nil
 }
 
-func (kt *KustTarget) accumulateFile(
-	ra *accumulator.ResAccumulator, path string) error {
+// loadFile reads the resources in the file, and
+// records their origin.
+func (kt *KustTarget) loadFile(path string) (resmap.ResMap, error) {
 	resources, err := kt.rFactory.FromFile(kt.ldr, path)
 	if err != nil {
-		return errors.Wrapf(err, "accumulating resources from '%s'", path)
+		return nil, errors.Wrapf(err, "accumulating resources from '%s'", path)
 	}
-	err = ra.AppendAll(resources)
+	err = kt.recordFileOrigin(resources, path)
 	if err != nil {
-		return errors.Wrapf(err, "merging resources from '%s'", path)
+		return nil, errors.Wrapf(err, "recording origin of '%s'", path)
 	}
-	return nil
+	return resources, nil
 }
 
 func (kt *KustTarget) configureBuiltinPlugin(
@@ -381,7 +802,8 @@ func (kt *KustTarget) configureBuiltinPlugin(
 				err, "builtin %s marshal", bpt)
 		}
 	}
-	err = p.Config(resmap.NewPluginHelpers(kt.ldr, kt.validator, kt.rFactory), y)
+	err = p.Config(resmap.NewPluginHelpersWithConfig(
+		kt.ldr, kt.validator, kt.rFactory, kt.pLdr.Config()), y)
 	if err != nil {
 		return errors.Wrapf(
 			err, "trouble configuring builtin %s with config: `\n%s`", bpt, string(y))
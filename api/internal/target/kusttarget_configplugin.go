@@ -32,13 +32,16 @@ func (kt *KustTarget) configureBuiltinGenerators() (
 	for _, bpt := range []builtinhelpers.BuiltinPluginType{
 		builtinhelpers.ConfigMapGenerator,
 		builtinhelpers.SecretGenerator,
+		builtinhelpers.HelmChartInflationGenerator,
 	} {
 		r, err := generatorConfigurators[bpt](
 			kt, bpt, builtinhelpers.GeneratorFactories[bpt])
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, r...)
+		for _, g := range r {
+			result = append(result, kt.trackGenerator(g, builtinPluginRef(bpt)))
+		}
 	}
 	return result, nil
 }
@@ -55,14 +58,18 @@ func (kt *KustTarget) configureBuiltinTransformers(
 		builtinhelpers.AnnotationsTransformer,
 		builtinhelpers.PatchJson6902Transformer,
 		builtinhelpers.ReplicaCountTransformer,
+		builtinhelpers.ScalingTransformer,
 		builtinhelpers.ImageTagTransformer,
+		builtinhelpers.ReplacementTransformer,
 	} {
 		r, err := transformerConfigurators[bpt](
 			kt, bpt, builtinhelpers.TransformerFactories[bpt], tc)
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, r...)
+		for _, t := range r {
+			result = append(result, kt.trackTransformer(t, builtinPluginRef(bpt)))
+		}
 	}
 	return result, nil
 }
@@ -110,6 +117,23 @@ var generatorConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		}
 		return
 	},
+
+	builtinhelpers.HelmChartInflationGenerator: func(kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f gFactory) (
+		result []resmap.Generator, err error) {
+		var c struct {
+			types.HelmChartArgs
+		}
+		for _, args := range kt.kustomization.HelmCharts {
+			c.HelmChartArgs = args
+			p := f()
+			err := kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		return
+	},
 }
 
 type tFactory func() resmap.TransformerPlugin
@@ -189,6 +213,13 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
 		}
 		for _, pc := range kt.kustomization.Patches {
+			ok, err := kt.included(pc.When)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
 			c.Target = pc.Target
 			c.Patch = pc.Patch
 			c.Path = pc.Path
@@ -260,6 +291,10 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		var c struct {
 			ImageTag   types.Image
 			FieldSpecs []types.FieldSpec
+			ImageIndex string
+		}
+		if kt.kustomization.ImageIndex != nil {
+			c.ImageIndex = kt.kustomization.ImageIndex.Path
 		}
 		for _, args := range kt.kustomization.Images {
 			c.ImageTag = args
@@ -292,6 +327,43 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		}
 		return
 	},
+	builtinhelpers.ScalingTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		var c struct {
+			Scaling    types.Scaling
+			FieldSpecs []types.FieldSpec
+		}
+		for _, args := range kt.kustomization.Scaling {
+			c.Scaling = args
+			c.FieldSpecs = tc.Replicas
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		return
+	},
+	builtinhelpers.ReplacementTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, _ *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		if len(kt.kustomization.Replacements) == 0 {
+			return
+		}
+		var c struct {
+			Replacements []types.Replacement
+		}
+		c.Replacements = kt.kustomization.Replacements
+		p := f()
+		err = kt.configureBuiltinPlugin(p, c, bpt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+		return
+	},
 	// No kustomization file keyword for this yet.
 	builtinhelpers.ValueAddTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
@@ -299,3 +371,37 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return nil, fmt.Errorf("valueadd keyword not yet defined")
 	},
 }
+
+// AddInventory appends to m the inventory object that
+// the kustomization's inventory field asks for, if any,
+// listing the objects in m.  Only the inventory of the
+// kustomization being built counts; those of its bases
+// are ignored.
+func (kt *KustTarget) AddInventory(m resmap.ResMap) error {
+	inv := kt.kustomization.Inventory
+	if inv == nil {
+		return nil
+	}
+	var c struct {
+		Type      string
+		Name      string
+		Namespace string
+	}
+	c.Type = inv.Type
+	switch inv.Type {
+	case types.InventoryTypeResourceGroup:
+		c.Name, c.Namespace = inv.ResourceGroup.Name, inv.ResourceGroup.Namespace
+	default:
+		c.Name, c.Namespace = inv.ConfigMap.Name, inv.ConfigMap.Namespace
+	}
+	bpt := builtinhelpers.InventoryTransformer
+	p := builtinhelpers.TransformerFactories[bpt]()
+	err := kt.configureBuiltinPlugin(p, c, bpt)
+	if err == nil {
+		err = kt.trackTransformer(p, builtinPluginRef(bpt)).Transform(m)
+	}
+	if err != nil {
+		return kt.annotate(err)
+	}
+	return nil
+}
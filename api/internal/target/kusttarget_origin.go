@@ -0,0 +1,220 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/kusterr"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Functions dedicated to build provenance: when enabled,
+// every resource is annotated with its origin (the file
+// it was read from, or the generator that made it), and
+// with the list of transformers that changed it.  Errors
+// of generators and transformers always name them.
+
+// originTracker holds the state shared by all the
+// targets of one build that records origins.
+type originTracker struct {
+	// Absolute path of the root of the build; recorded
+	// paths are relative to it.
+	root string
+}
+
+// repoLoader is implemented by loaders that know
+// whether their files came from a remote repository.
+type repoLoader interface {
+	Repo() (url string, cloneRoot string)
+}
+
+// TrackOrigins makes the target (and the targets of its
+// bases and components) record the origin of resources,
+// and the transformations of them, in annotations.
+// Call it before making the customized ResMap.
+func (kt *KustTarget) TrackOrigins() {
+	kt.origins = &originTracker{root: kt.ldr.Root()}
+}
+
+// origin returns an Origin holding the path of the
+// given file, relative to the build root or, for
+// remote files, to the root of their repository.
+func (kt *KustTarget) origin(path string) *resource.Origin {
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" {
+		return &resource.Origin{Path: path}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(kt.ldr.Root(), path)
+	}
+	root := kt.origins.root
+	var repo string
+	if rl, ok := kt.ldr.(repoLoader); ok {
+		if u, cloneRoot := rl.Repo(); u != "" {
+			repo, root = u, cloneRoot
+		}
+	}
+	if rel, err := filepath.Rel(root, path); err == nil {
+		path = rel
+	}
+	return &resource.Origin{Path: path, Repo: repo}
+}
+
+// configuredOrigin returns an Origin for a generator or
+// transformer configured in this target's kustomization.
+func (kt *KustTarget) configuredOrigin(
+	ref *resource.PluginRef) *resource.Origin {
+	o := kt.origin(kt.kustFileName)
+	o.ConfiguredIn, o.Path = o.Path, ""
+	o.ConfiguredBy = ref
+	return o
+}
+
+func builtinPluginRef(
+	bpt builtinhelpers.BuiltinPluginType) *resource.PluginRef {
+	return &resource.PluginRef{
+		APIVersion: konfig.BuiltinPluginApiVersion,
+		Kind:       bpt.String(),
+	}
+}
+
+func configPluginRef(config *resource.Resource) *resource.PluginRef {
+	apiVersion, _ := config.GetString("apiVersion")
+	return &resource.PluginRef{
+		APIVersion: apiVersion,
+		Kind:       config.GetKind(),
+		Name:       config.GetName(),
+	}
+}
+
+// recordFileOrigin records the file that the
+// resources were read from.
+func (kt *KustTarget) recordFileOrigin(
+	m resmap.ResMap, path string) error {
+	if kt.origins == nil {
+		return nil
+	}
+	o := kt.origin(path)
+	for _, r := range m.Resources() {
+		if err := r.SetOrigin(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackGenerator returns the generator, wrapped so
+// that its errors name it, and, if tracking origins,
+// so that it records its origin in what it generates.
+func (kt *KustTarget) trackGenerator(
+	g resmap.Generator, ref *resource.PluginRef) resmap.Generator {
+	g = &blamedGenerator{Generator: g, name: pluginName(ref)}
+	if kt.origins == nil {
+		return g
+	}
+	return &originGenerator{Generator: g, origin: kt.configuredOrigin(ref)}
+}
+
+// trackTransformer returns the transformer, wrapped so
+// that its errors name it, and, if tracking origins, so
+// that it records itself in the resources it changes.
+func (kt *KustTarget) trackTransformer(
+	t resmap.Transformer, ref *resource.PluginRef) resmap.Transformer {
+	t = &blamedTransformer{Transformer: t, name: pluginName(ref)}
+	if kt.origins == nil {
+		return t
+	}
+	return &originTransformer{Transformer: t, origin: kt.configuredOrigin(ref)}
+}
+
+// pluginName names a plugin in errors: by kind, and by
+// the name of its configuration, if any.
+func pluginName(ref *resource.PluginRef) string {
+	if ref.Name == "" {
+		return ref.Kind
+	}
+	return ref.Kind + "/" + ref.Name
+}
+
+type blamedGenerator struct {
+	resmap.Generator
+	name string
+}
+
+func (g *blamedGenerator) Generate() (resmap.ResMap, error) {
+	m, err := g.Generator.Generate()
+	if err != nil {
+		return nil, &kusterr.BuildError{Transformer: g.name, Err: err}
+	}
+	return m, nil
+}
+
+type blamedTransformer struct {
+	resmap.Transformer
+	name string
+}
+
+func (t *blamedTransformer) Transform(m resmap.ResMap) error {
+	if err := t.Transformer.Transform(m); err != nil {
+		return &kusterr.BuildError{Transformer: t.name, Err: err}
+	}
+	return nil
+}
+
+type originGenerator struct {
+	resmap.Generator
+	origin *resource.Origin
+}
+
+func (g *originGenerator) Generate() (resmap.ResMap, error) {
+	m, err := g.Generator.Generate()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range m.Resources() {
+		if err = r.SetOrigin(g.origin); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+type originTransformer struct {
+	resmap.Transformer
+	origin *resource.Origin
+}
+
+// Transform runs the transformer, and records it in
+// each resource that it added or changed.
+func (t *originTransformer) Transform(m resmap.ResMap) error {
+	before := make(map[*resource.Resource][]byte, m.Size())
+	for _, r := range m.Resources() {
+		b, err := r.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		before[r] = b
+	}
+	if err := t.Transformer.Transform(m); err != nil {
+		return err
+	}
+	for _, r := range m.Resources() {
+		b, err := r.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(before[r], b) {
+			continue
+		}
+		if err = r.AppendTransformation(t.origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
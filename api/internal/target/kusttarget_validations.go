@@ -0,0 +1,325 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/validate"
+	"sigs.k8s.io/kustomize/api/kusterr"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// CheckValidations checks the resources of the build
+// output against the validations of the kustomization,
+// and their schemas if asked to, returning a
+// *kusterr.ValidationError if any break them, or if the
+// resources of its bases and components broke theirs.
+func (kt *KustTarget) CheckValidations(m resmap.ResMap) error {
+	var failures []kusterr.ValidationFailure
+	for i, v := range kt.kustomization.Validations {
+		fs, err := checkValidation(m, v)
+		if err != nil {
+			return kt.annotate(fmt.Errorf("validation %d: %v", i, err))
+		}
+		failures = append(failures, fs...)
+	}
+	failures = append(failures, kt.subFailures...)
+	if kt.schemas {
+		fs, err := kt.checkSchemas(m)
+		if err != nil {
+			return kt.annotate(err)
+		}
+		failures = append(failures, fs...)
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &kusterr.ValidationError{
+		Kustomization: filepath.Join(kt.ldr.Root(), kt.kustFileName),
+		Failures:      failures,
+	}
+}
+
+// accumulateValidatedBase accumulates the target, a base,
+// and adds the failures of its validations to the result.
+func (kt *KustTarget) accumulateValidatedBase() (
+	*accumulator.ResAccumulator, error) {
+	ra, err := kt.AccumulateTarget()
+	if err != nil {
+		return nil, err
+	}
+	if err = kt.addValidationFailures(ra); err != nil {
+		return nil, err
+	}
+	return ra, nil
+}
+
+// addValidationFailures checks the resources of ra, as
+// the target, a base or component, leaves them, against
+// the validations of its kustomization, adding the
+// failures to ra, so that the build reports them with
+// its own.
+func (kt *KustTarget) addValidationFailures(
+	ra *accumulator.ResAccumulator) error {
+	path := filepath.Join(kt.ldr.Root(), kt.kustFileName)
+	for i, v := range kt.kustomization.Validations {
+		fs, err := checkValidation(ra.ResMap(), v)
+		if err != nil {
+			return kt.annotate(fmt.Errorf("validation %d: %v", i, err))
+		}
+		for j := range fs {
+			fs[j].Kustomization = path
+		}
+		ra.AddValidationFailures(fs)
+	}
+	return nil
+}
+
+// checkSchemas checks the resources against their
+// OpenAPI schemas: those of the Kubernetes kinds, of the
+// crds of the kustomizations of the build, and of the
+// CustomResourceDefinitions among the resources.
+func (kt *KustTarget) checkSchemas(
+	m resmap.ResMap) ([]kusterr.ValidationFailure, error) {
+	schemas := validate.NewSchemas()
+	for _, content := range kt.crds {
+		if err := schemas.AddDefinitions(content); err != nil {
+			return nil, fmt.Errorf("loading schemas of crds: %v", err)
+		}
+	}
+	for _, r := range m.Resources() {
+		if validate.IsCRD(r.GetGvk()) {
+			if err := schemas.AddCRD(r.Map()); err != nil {
+				return nil, fmt.Errorf("loading schemas of %s: %v", r.CurId(), err)
+			}
+		}
+	}
+	var result []kusterr.ValidationFailure
+	for _, r := range m.Resources() {
+		result = append(result, schemas.Check(r.CurId(), r.Map())...)
+	}
+	return result, nil
+}
+
+// A rule returns the failures of one resource, and
+// remembers what it saw, if needed, in seen.
+type rule func(r *resource.Resource, seen map[string]*resource.Resource) []string
+
+func checkValidation(
+	m resmap.ResMap, v types.Validation) ([]kusterr.ValidationFailure, error) {
+	name, check, err := ruleOf(v)
+	if err != nil {
+		return nil, err
+	}
+	if v.Name != "" {
+		name = v.Name
+	}
+	resources := m.Resources()
+	if v.Target != nil {
+		resources, err = m.Select(*v.Target)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var result []kusterr.ValidationFailure
+	seen := make(map[string]*resource.Resource)
+	for _, r := range resources {
+		for _, msg := range check(r, seen) {
+			result = append(result, kusterr.ValidationFailure{
+				Rule: name, ResId: r.CurId(), Message: msg})
+		}
+	}
+	return result, nil
+}
+
+// ruleOf returns the name and the check of the one
+// rule field that the validation has.
+func ruleOf(v types.Validation) (string, rule, error) {
+	var names []string
+	var check rule
+	if v.RequiredLabel != "" {
+		names = append(names, "requiredLabel")
+		check = func(r *resource.Resource, _ map[string]*resource.Resource) []string {
+			if _, ok := r.GetLabels()[v.RequiredLabel]; !ok {
+				return []string{fmt.Sprintf("missing label '%s'", v.RequiredLabel)}
+			}
+			return nil
+		}
+	}
+	if v.RequiredAnnotation != "" {
+		names = append(names, "requiredAnnotation")
+		check = func(r *resource.Resource, _ map[string]*resource.Resource) []string {
+			if _, ok := r.GetAnnotations()[v.RequiredAnnotation]; !ok {
+				return []string{fmt.Sprintf("missing annotation '%s'", v.RequiredAnnotation)}
+			}
+			return nil
+		}
+	}
+	if v.ForbiddenValue != nil {
+		if v.ForbiddenValue.Path == "" || len(v.ForbiddenValue.Values) == 0 {
+			return "", nil, fmt.Errorf("forbiddenValue needs a path and values")
+		}
+		names = append(names, "forbiddenValue")
+		check = func(r *resource.Resource, _ map[string]*resource.Resource) []string {
+			var result []string
+			for _, value := range valuesAt(r.Map(), v.ForbiddenValue.Path) {
+				for _, f := range v.ForbiddenValue.Values {
+					if value == f {
+						result = append(result, fmt.Sprintf(
+							"forbidden value '%s' at %s", value, v.ForbiddenValue.Path))
+					}
+				}
+			}
+			return result
+		}
+	}
+	if len(v.AllowedRegistries) > 0 {
+		names = append(names, "allowedRegistries")
+		check = func(r *resource.Resource, _ map[string]*resource.Resource) []string {
+			var result []string
+			for _, c := range containersOf(r.Map()) {
+				image, _ := c["image"].(string)
+				if image != "" && !fromRegistries(image, v.AllowedRegistries) {
+					result = append(result, fmt.Sprintf(
+						"image '%s' of container '%v' isn't from an allowed registry",
+						image, c["name"]))
+				}
+			}
+			return result
+		}
+	}
+	if len(v.RequiredLimits) > 0 {
+		names = append(names, "requiredLimits")
+		check = func(r *resource.Resource, _ map[string]*resource.Resource) []string {
+			var result []string
+			for _, c := range containersOf(r.Map()) {
+				resources, _ := c["resources"].(map[string]interface{})
+				limits, _ := resources["limits"].(map[string]interface{})
+				for _, l := range v.RequiredLimits {
+					if _, ok := limits[l]; !ok {
+						result = append(result, fmt.Sprintf(
+							"container '%v' has no %s limit", c["name"], l))
+					}
+				}
+			}
+			return result
+		}
+	}
+	if v.UniqueField != "" {
+		names = append(names, "uniqueField")
+		check = func(r *resource.Resource, seen map[string]*resource.Resource) []string {
+			var result []string
+			for _, value := range valuesAt(r.Map(), v.UniqueField) {
+				if other, ok := seen[value]; ok {
+					result = append(result, fmt.Sprintf(
+						"value '%s' at %s is also that of %s",
+						value, v.UniqueField, other.CurId()))
+					continue
+				}
+				seen[value] = r
+			}
+			return result
+		}
+	}
+	if len(names) != 1 {
+		return "", nil, fmt.Errorf(
+			"expected exactly one rule, got %d %v", len(names), names)
+	}
+	return names[0], check, nil
+}
+
+// valuesAt returns the values of the field at the path,
+// descending into the items of lists, as YAML would
+// write scalars.
+func valuesAt(obj interface{}, path string) []string {
+	var result []string
+	var walk func(obj interface{}, fields []string)
+	walk = func(obj interface{}, fields []string) {
+		if l, ok := obj.([]interface{}); ok {
+			for _, item := range l {
+				walk(item, fields)
+			}
+			return
+		}
+		if len(fields) == 0 {
+			if obj != nil {
+				result = append(result, fmt.Sprint(obj))
+			}
+			return
+		}
+		if m, ok := obj.(map[string]interface{}); ok {
+			walk(m[fields[0]], fields[1:])
+		}
+	}
+	walk(obj, strings.Split(strings.Trim(path, "/"), "/"))
+	return result
+}
+
+// containersOf returns the containers, and init
+// containers, of the workload.
+func containersOf(obj map[string]interface{}) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, path := range filtersutil.PodSpecPaths {
+		var spec interface{} = obj
+		for _, f := range path {
+			m, _ := spec.(map[string]interface{})
+			spec = m[f]
+		}
+		m, ok := spec.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, list := range []string{"initContainers", "containers"} {
+			items, _ := m[list].([]interface{})
+			for _, item := range items {
+				if c, ok := item.(map[string]interface{}); ok {
+					result = append(result, c)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// fromRegistries returns whether the image is from one
+// of the registries, or registry paths.
+func fromRegistries(image string, registries []string) bool {
+	name := qualifiedImage(image)
+	for _, r := range registries {
+		if strings.HasPrefix(name, strings.TrimSuffix(r, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerHub is the registry of images that name none.
+const dockerHub = "docker.io"
+
+// qualifiedImage returns the image with the registry and
+// path docker pulls it from: images without a registry
+// are from docker.io, and those named by one component
+// are official images, under docker.io/library, e.g.
+// nginx is docker.io/library/nginx.
+func qualifiedImage(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 || (!strings.ContainsAny(image[:i], ".:") && image[:i] != "localhost") {
+		image = dockerHub + "/" + image
+	} else if image[:i] == "index."+dockerHub {
+		image = dockerHub + image[i:]
+	}
+	if strings.HasPrefix(image, dockerHub+"/") {
+		if rest := image[len(dockerHub)+1:]; !strings.Contains(rest, "/") {
+			image = dockerHub + "/library/" + rest
+		}
+	}
+	return image
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"testing"
+)
+
+func TestQualifiedImage(t *testing.T) {
+	testCases := map[string]string{
+		"nginx":                         "docker.io/library/nginx",
+		"nginx:1.19@sha256:abc":         "docker.io/library/nginx:1.19@sha256:abc",
+		"bitnami/nginx":                 "docker.io/bitnami/nginx",
+		"docker.io/nginx":               "docker.io/library/nginx",
+		"index.docker.io/nginx":         "docker.io/library/nginx",
+		"docker.io/library/nginx":       "docker.io/library/nginx",
+		"gcr.io/my-project/app":         "gcr.io/my-project/app",
+		"localhost/app":                 "localhost/app",
+		"registry.example.com:5000/app": "registry.example.com:5000/app",
+	}
+	for image, expected := range testCases {
+		if actual := qualifiedImage(image); actual != expected {
+			t.Errorf("%s: expected %s, got %s", image, expected, actual)
+		}
+	}
+}
+
+func TestFromRegistries(t *testing.T) {
+	testCases := []struct {
+		image      string
+		registries []string
+		expected   bool
+	}{
+		{"nginx", []string{"docker.io/library"}, true},
+		{"nginx", []string{"docker.io/library/"}, true},
+		{"nginx", []string{"docker.io"}, true},
+		{"nginx", []string{"docker.io/nginx"}, false},
+		{"bitnami/nginx", []string{"docker.io/library"}, false},
+		{"bitnami/nginx", []string{"docker.io/bitnami"}, true},
+		{"gcr.io/my-project/app", []string{"gcr.io/my-project"}, true},
+		{"gcr.io/my-project-2/app", []string{"gcr.io/my-project"}, false},
+	}
+	for _, tc := range testCases {
+		if actual := fromRegistries(tc.image, tc.registries); actual != tc.expected {
+			t.Errorf("%s from %v: expected %v, got %v",
+				tc.image, tc.registries, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// requirement is one comma-separated part of a when
+// expression: a parameter, an operator, and the values
+// that it's compared to.
+type requirement struct {
+	param  string
+	op     string
+	values []string
+}
+
+// holds returns true if the parameter, which is missing
+// if ok is false, meets the requirement.  A missing
+// parameter equals no value.
+func (r requirement) holds(value string, ok bool) bool {
+	in := false
+	for _, v := range r.values {
+		if ok && v == value {
+			in = true
+		}
+	}
+	switch r.op {
+	case "==", "in":
+		return in
+	default:
+		return !in
+	}
+}
+
+// evalWhen returns whether the when expression holds for
+// the parameters.  Expressions are written as label
+// selectors are, less existence tests, e.g.
+//   env == prod, region in (eu, us)
+// and hold if all their requirements hold.
+func evalWhen(expr string, params map[string]string) (bool, error) {
+	rs, err := parseWhen(expr)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range rs {
+		v, ok := params[r.param]
+		if !r.holds(v, ok) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func parseWhen(expr string) ([]requirement, error) {
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("invalid when expression '%s': %s",
+			expr, fmt.Sprintf(format, args...))
+	}
+	var result []requirement
+	rest := expr
+	for {
+		var r requirement
+		r.param, rest = word(rest)
+		if r.param == "" {
+			return nil, errorf("expected a parameter name")
+		}
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(rest, "=="), strings.HasPrefix(rest, "!="):
+			r.op, rest = rest[:2], rest[2:]
+		case strings.HasPrefix(rest, "="):
+			r.op, rest = "==", rest[1:]
+		default:
+			r.op, rest = word(rest)
+			if r.op != "in" && r.op != "notin" {
+				return nil, errorf(
+					"expected ==, !=, in or notin after '%s'", r.param)
+			}
+		}
+		if r.op == "in" || r.op == "notin" {
+			rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+			if !strings.HasPrefix(rest, "(") {
+				return nil, errorf("expected ( after %s", r.op)
+			}
+			end := strings.Index(rest, ")")
+			if end < 0 {
+				return nil, errorf("missing )")
+			}
+			for _, v := range strings.Split(rest[1:end], ",") {
+				v = strings.TrimSpace(v)
+				if v == "" || !isWord(v) {
+					return nil, errorf("invalid value '%s' in set", v)
+				}
+				r.values = append(r.values, v)
+			}
+			rest = rest[end+1:]
+		} else {
+			var v string
+			v, rest = word(rest)
+			if v == "" {
+				return nil, errorf("expected a value after %s", r.op)
+			}
+			r.values = []string{v}
+		}
+		result = append(result, r)
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return result, nil
+		}
+		if !strings.HasPrefix(rest, ",") {
+			return nil, errorf("unexpected '%s'", rest)
+		}
+		rest = rest[1:]
+	}
+}
+
+// word splits s, less leading spaces, into the parameter
+// name or value that it starts with, and the rest.
+func word(s string) (string, string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	i := strings.IndexFunc(s, func(r rune) bool { return !isWordRune(r) })
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func isWord(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !isWordRune(r) }) < 0
+}
+
+// isWordRune returns true for the runes of label values.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) ||
+		r == '-' || r == '_' || r == '.' || r == '/'
+}
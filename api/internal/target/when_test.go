@@ -0,0 +1,59 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"testing"
+)
+
+func TestEvalWhen(t *testing.T) {
+	params := map[string]string{"env": "prod", "region": "eu"}
+	testCases := map[string]bool{
+		"env == prod":                    true,
+		"env=prod":                       true,
+		"env != prod":                    false,
+		"env in (staging, prod)":         true,
+		"env notin (staging,prod)":       false,
+		"env == prod, region in (us)":    false,
+		"env == prod, region notin (us)": true,
+		"tier == web":                    false,
+		"tier != web":                    true,
+		"tier notin (web)":               true,
+	}
+	for expr, expected := range testCases {
+		t.Run(expr, func(t *testing.T) {
+			actual, err := evalWhen(expr, params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != expected {
+				t.Fatalf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestEvalWhenErrors(t *testing.T) {
+	testCases := map[string]string{
+		"":                 "expected a parameter name",
+		"env":              "expected ==, !=, in or notin after 'env'",
+		"env > 1":          "expected ==, !=, in or notin after 'env'",
+		"env ==":           "expected a value after ==",
+		"env in prod":      "expected ( after in",
+		"env in (prod":     "missing )",
+		"env in (a,,b)":    "invalid value '' in set",
+		"env == prod || x": "unexpected '|| x'",
+	}
+	for expr, expected := range testCases {
+		t.Run(expr, func(t *testing.T) {
+			_, err := evalWhen(expr, nil)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if err.Error() != "invalid when expression '"+expr+"': "+expected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package validate checks resources against their
+// OpenAPI schemas, without a cluster.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"k8s.io/kube-openapi/pkg/common"
+	"sigs.k8s.io/kustomize/api/kusterr"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Rules that resources may break.
+const (
+	RuleUnknownField  = "unknownField"
+	RuleTypeMismatch  = "typeMismatch"
+	RuleRequiredField = "requiredField"
+	RuleEnum          = "enum"
+)
+
+const (
+	definitionsPrefix  = "#/definitions/"
+	xIntOrString       = "x-kubernetes-int-or-string"
+	xPreserveUnknown   = "x-kubernetes-preserve-unknown-fields"
+	formatIntOrString  = "int-or-string"
+	crdKind            = "CustomResourceDefinition"
+	crdOpenAPIV3Field  = "openAPIV3Schema"
+	metadataField      = "metadata"
+	objectType         = "object"
+	arrayType          = "array"
+	stringType         = "string"
+	integerType        = "integer"
+	numberType         = "number"
+	booleanType        = "boolean"
+	quantityTypeSuffix = ".Quantity"
+	intOrStrTypeSuffix = ".IntOrString"
+	apiVersionField    = "apiVersion"
+	kindField          = "kind"
+)
+
+// Schemas holds the OpenAPI schemas that resources are
+// checked against: those of the Kubernetes kinds, which
+// kyaml embeds, and those of custom resources.
+type Schemas struct {
+	// Schemas of custom resources, from CRDs.
+	byGvk map[resid.Gvk]*spec.Schema
+	// Schemas of custom resources from the OpenAPI
+	// definitions of crds entries, which only say the
+	// kind, and the definitions that they refer to.
+	byKind      map[string]*spec.Schema
+	definitions map[string]spec.Schema
+}
+
+// NewSchemas returns Schemas of the Kubernetes kinds.
+func NewSchemas() *Schemas {
+	return &Schemas{
+		byGvk:       make(map[resid.Gvk]*spec.Schema),
+		byKind:      make(map[string]*spec.Schema),
+		definitions: make(map[string]spec.Schema),
+	}
+}
+
+// AddDefinitions adds the schemas of the OpenAPI
+// definitions in content, the format of the crds field
+// of kustomizations, by the kind that their names end in.
+func (s *Schemas) AddDefinitions(content []byte) error {
+	var defs map[string]common.OpenAPIDefinition
+	if err := yaml.Unmarshal(content, &defs); err != nil {
+		return err
+	}
+	for name, d := range defs {
+		s.definitions[name] = d.Schema
+		p := d.Schema.Properties
+		if _, ok := p[kindField]; !ok {
+			continue
+		}
+		if _, ok := p[metadataField]; !ok {
+			continue
+		}
+		sc := d.Schema
+		s.byKind[name[strings.LastIndex(name, ".")+1:]] = &sc
+	}
+	return nil
+}
+
+// AddCRD adds the schemas of the versions of the
+// CustomResourceDefinition.
+func (s *Schemas) AddCRD(crd map[string]interface{}) error {
+	var c struct {
+		Spec struct {
+			Group string `json:"group"`
+			Names struct {
+				Kind string `json:"kind"`
+			} `json:"names"`
+			Version    string                 `json:"version"`
+			Validation map[string]interface{} `json:"validation"`
+			Versions   []struct {
+				Name   string                 `json:"name"`
+				Schema map[string]interface{} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	b, err := json.Marshal(crd)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, &c); err != nil {
+		return err
+	}
+	parse := func(v map[string]interface{}) (*spec.Schema, error) {
+		if v[crdOpenAPIV3Field] == nil {
+			return nil, nil
+		}
+		b, err := json.Marshal(v[crdOpenAPIV3Field])
+		if err != nil {
+			return nil, err
+		}
+		sc := &spec.Schema{}
+		return sc, sc.UnmarshalJSON(b)
+	}
+	// Schemas of apiextensions.k8s.io/v1beta1 apply to
+	// all the versions.
+	common, err := parse(c.Spec.Validation)
+	if err != nil {
+		return err
+	}
+	versions := []string{c.Spec.Version}
+	for _, v := range c.Spec.Versions {
+		versions = append(versions, v.Name)
+		sc, err := parse(v.Schema)
+		if err != nil {
+			return err
+		}
+		if sc != nil {
+			s.byGvk[resid.Gvk{
+				Group: c.Spec.Group, Version: v.Name, Kind: c.Spec.Names.Kind}] = sc
+		}
+	}
+	if common != nil {
+		for _, v := range versions {
+			if v != "" {
+				s.byGvk[resid.Gvk{
+					Group: c.Spec.Group, Version: v, Kind: c.Spec.Names.Kind}] = common
+			}
+		}
+	}
+	return nil
+}
+
+// IsCRD returns whether the resource is a
+// CustomResourceDefinition.
+func IsCRD(gvk resid.Gvk) bool {
+	return gvk.Kind == crdKind && gvk.Group == "apiextensions.k8s.io"
+}
+
+// Check returns the ways in which the object, of the
+// resource with the id, breaks its schema.  Objects of
+// kinds without a schema aren't checked.
+func (s *Schemas) Check(id resid.ResId, obj map[string]interface{}) []kusterr.ValidationFailure {
+	sc := s.schemaOf(id.Gvk)
+	if sc == nil {
+		return nil
+	}
+	c := &checker{schemas: s, id: id}
+	c.check(sc, obj, "", true)
+	return c.failures
+}
+
+func (s *Schemas) schemaOf(gvk resid.Gvk) *spec.Schema {
+	if sc, ok := s.byGvk[gvk]; ok {
+		return sc
+	}
+	apiVersion := gvk.Version
+	if gvk.Group != "" {
+		apiVersion = gvk.Group + "/" + gvk.Version
+	}
+	if rs := openapi.SchemaForResourceType(
+		kyaml.TypeMeta{APIVersion: apiVersion, Kind: gvk.Kind}); rs != nil {
+		return rs.Schema
+	}
+	return s.byKind[gvk.Kind]
+}
+
+// resolve follows the references of the schema.  It
+// returns nil for a reference that it can't follow, which
+// anything matches, and the name of the last definition.
+func (s *Schemas) resolve(sc *spec.Schema) (*spec.Schema, string) {
+	name := ""
+	for i := 0; sc != nil && sc.Ref.String() != ""; i++ {
+		if i > 32 {
+			return nil, name
+		}
+		name = sc.Ref.String()
+		if d, ok := s.definitions[name]; ok {
+			sc = &d
+			continue
+		}
+		if !strings.HasPrefix(name, definitionsPrefix) {
+			return nil, name
+		}
+		r, err := openapi.Resolve(&sc.Ref)
+		if err != nil {
+			return nil, name
+		}
+		sc = r
+	}
+	return sc, name
+}
+
+type checker struct {
+	schemas  *Schemas
+	id       resid.ResId
+	failures []kusterr.ValidationFailure
+}
+
+func (c *checker) fail(rule, path, format string, args ...interface{}) {
+	c.failures = append(c.failures, kusterr.ValidationFailure{
+		Rule:    rule,
+		ResId:   c.id,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) check(sc *spec.Schema, value interface{}, path string, top bool) {
+	sc, name := c.schemas.resolve(sc)
+	if sc == nil || value == nil {
+		return
+	}
+	got := typeOf(value)
+	if !conforms(sc, name, got) {
+		c.fail(RuleTypeMismatch, path,
+			"expected %s, got %s", strings.Join(sc.Type, " or "), got)
+		return
+	}
+	if len(sc.Enum) > 0 && !inEnum(sc.Enum, value) {
+		c.fail(RuleEnum, path,
+			"value '%v' isn't one of %s", value, enumString(sc.Enum))
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c.checkObject(sc, v, path, top)
+	case []interface{}:
+		if sc.Items == nil || sc.Items.Schema == nil {
+			return
+		}
+		for i, item := range v {
+			c.check(sc.Items.Schema, item, fmt.Sprintf("%s[%d]", path, i), false)
+		}
+	}
+}
+
+func (c *checker) checkObject(
+	sc *spec.Schema, obj map[string]interface{}, path string, top bool) {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	preserve, _ := sc.Extensions.GetBool(xPreserveUnknown)
+	for _, k := range keys {
+		fieldPath := k
+		if path != "" {
+			fieldPath = path + "." + k
+		}
+		if p, ok := sc.Properties[k]; ok {
+			c.check(&p, obj[k], fieldPath, false)
+			continue
+		}
+		if ap := sc.AdditionalProperties; ap != nil {
+			if ap.Schema != nil {
+				c.check(ap.Schema, obj[k], fieldPath, false)
+			}
+			continue
+		}
+		if top && (k == apiVersionField || k == kindField || k == metadataField) {
+			// Often left out of the schemas of CRDs.
+			continue
+		}
+		if len(sc.Properties) == 0 || preserve {
+			// A free-form object.
+			continue
+		}
+		c.fail(RuleUnknownField, fieldPath, "unknown field")
+	}
+	for _, r := range sc.Required {
+		if _, ok := obj[r]; !ok {
+			c.fail(RuleRequiredField, path, "missing required field '%s'", r)
+		}
+	}
+}
+
+// typeOf returns the JSON type of the value.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return objectType
+	case []interface{}:
+		return arrayType
+	case string:
+		return stringType
+	case bool:
+		return booleanType
+	case int, int32, int64:
+		return integerType
+	case float64:
+		if v == float64(int64(v)) {
+			return integerType
+		}
+		return numberType
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return integerType
+		}
+		return numberType
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// conforms returns whether a value of the type got
+// matches the types of the schema, named after the
+// definition name.
+func conforms(sc *spec.Schema, name string, got string) bool {
+	if len(sc.Type) == 0 {
+		return true
+	}
+	intOrString, _ := sc.Extensions.GetBool(xIntOrString)
+	if intOrString || sc.Format == formatIntOrString ||
+		strings.HasSuffix(name, quantityTypeSuffix) ||
+		strings.HasSuffix(name, intOrStrTypeSuffix) {
+		if got == stringType || got == integerType || got == numberType {
+			return true
+		}
+	}
+	for _, t := range sc.Type {
+		if t == got || (t == numberType && got == integerType) {
+			return true
+		}
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumString(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = fmt.Sprintf("'%v'", e)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/yaml"
+)
+
+func parse(t *testing.T, s string) map[string]interface{} {
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(s), &obj); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return obj
+}
+
+func TestCheckBuiltin(t *testing.T) {
+	s := NewSchemas()
+	id := resid.NewResId(resid.Gvk{Version: "v1", Kind: "Service"}, "web")
+	failures := s.Check(id, parse(t, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+    targetPort: http
+  - port: 443
+    targetPort: 8443
+    protocol: [TCP]
+`))
+	if len(failures) != 1 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	if f := failures[0].String(); f !=
+		"~G_v1_Service|~X|web: typeMismatch: spec.ports[1].protocol: expected string, got array" {
+		t.Fatalf("unexpected failure: %s", f)
+	}
+}
+
+func TestCheckV1beta1CRD(t *testing.T) {
+	s := NewSchemas()
+	err := s.AddCRD(parse(t, `
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+  - name: v1
+  - name: v2
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            size:
+              x-kubernetes-int-or-string: true
+            extra:
+              type: object
+              x-kubernetes-preserve-unknown-fields: true
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, v := range []string{"v1", "v2"} {
+		id := resid.NewResId(
+			resid.Gvk{Group: "example.com", Version: v, Kind: "Widget"}, "w")
+		failures := s.Check(id, parse(t, `
+apiVersion: example.com/`+v+`
+kind: Widget
+metadata:
+  name: w
+spec:
+  size: 3
+  extra:
+    anything: goes
+  colour: red
+`))
+		if len(failures) != 1 || failures[0].Rule != RuleUnknownField ||
+			failures[0].Path != "spec.colour" {
+			t.Fatalf("unexpected failures of %s: %v", v, failures)
+		}
+	}
+}
@@ -3,6 +3,11 @@
 
 package konfig
 
+import (
+	"os"
+	"path/filepath"
+)
+
 // RecognizedKustomizationFileNames is a list of file names
 // that kustomize recognizes.
 // To avoid ambiguity, a kustomization directory may not
@@ -19,6 +24,10 @@ func DefaultKustomizationFileName() string {
 	return RecognizedKustomizationFileNames()[0]
 }
 
+// LockFileName is the name of the file, next to a
+// kustomization file, pinning its remote bases.
+const LockFileName = "kustomization.lock"
+
 const (
 	// An environment variable to consult for kustomization
 	// configuration data.  See:
@@ -28,6 +37,17 @@ const (
 	// Use this when XdgConfigHomeEnv not defined.
 	XdgConfigHomeEnvDefault = ".config"
 
+	// An environment variable to consult for where
+	// to cache data, e.g. clones of remote repositories.
+	XdgCacheHomeEnv = "XDG_CACHE_HOME"
+
+	// Use this when XdgCacheHomeEnv not defined.
+	XdgCacheHomeEnvDefault = ".cache"
+
+	// Relative path below XDG_CACHE_HOME/kustomize
+	// of the cache of remote repositories.
+	RelRepoCacheDir = "repos"
+
 	// A program name, for use in help, finding the XDG_CONFIG_DIR, etc.
 	ProgramName = "kustomize"
 
@@ -36,4 +56,32 @@ const (
 
 	// An environment variable to turn on/off adding the ManagedByLabelKey
 	EnableManagedbyLabelEnv = "KUSTOMIZE_ENABLE_MANAGEDBY_LABEL"
+
+	// Annotation key recording the file, or the generator,
+	// that a resource came from.
+	OriginAnnotationKey = "config.kubernetes.io/origin"
+
+	// Annotation key recording the transformers that
+	// changed a resource, in the order they ran.
+	TransformationsAnnotationKey = "alpha.config.kubernetes.io/transformations"
+
+	// Label key identifying an inventory object to the
+	// tools that prune with it.
+	InventoryIdLabelKey = "cli-utils.sigs.k8s.io/inventory-id"
+
+	// Annotation key recording a hash of the content of
+	// the objects listed in an inventory object.
+	InventoryHashAnnotationKey = "kustomize.config.k8s.io/inventory-hash"
 )
+
+// DefaultRepoCacheDir returns the conventional directory
+// in which to cache copies of remote repositories, in the
+// XDG cache directory.  Nothing is cached across builds
+// unless a directory is given, e.g. this one.
+func DefaultRepoCacheDir() string {
+	if dir := os.Getenv(XdgCacheHomeEnv); dir != "" {
+		return filepath.Join(dir, ProgramName, RelRepoCacheDir)
+	}
+	return filepath.Join(
+		HomeDir(), XdgCacheHomeEnvDefault, ProgramName, RelRepoCacheDir)
+}
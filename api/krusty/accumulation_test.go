@@ -4,6 +4,7 @@
 package krusty_test
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -93,3 +94,48 @@ resources:
 		t.Fatalf("unexpected error: %q", err)
 	}
 }
+
+// Sibling resources are loaded concurrently, but the
+// output must keep their order.
+func TestSiblingResourcesKeepTheirOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	var resources, expected strings.Builder
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("cm%02d", i)
+		if i%2 == 0 {
+			th.WriteK("/app/"+name, `
+resources:
+- cm.yaml
+`)
+			th.WriteF("/app/"+name+"/cm.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: `+name+`
+`)
+			resources.WriteString("- " + name + "\n")
+		} else {
+			th.WriteF("/app/"+name+".yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: `+name+`
+`)
+			resources.WriteString("- " + name + ".yaml\n")
+		}
+		if i > 0 {
+			expected.WriteString("---\n")
+		}
+		expected.WriteString(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ` + name + "\n")
+	}
+	th.WriteK("/app", "resources:\n"+resources.String())
+	options := th.MakeDefaultOptions()
+	options.DoLegacyResourceSort = false
+	for i := 0; i < 5; i++ {
+		m := th.Run("/app", options)
+		th.AssertActualEqualsExpected(m, expected.String())
+	}
+}
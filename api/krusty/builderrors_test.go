@@ -0,0 +1,81 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/kusterr"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestBuildErrorOfBadYamlInBase(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/app/base/deployment.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+	labels: {}
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	d := kusterr.Details(err)
+	if d.Kustomization != "/app/base/kustomization.yaml" ||
+		d.File != "/app/base/deployment.yaml" || d.Line != 10 {
+		t.Fatalf("unexpected details: %s, %s:%d",
+			d.Kustomization, d.File, d.Line)
+	}
+	if _, ok := d.Err.(kusterr.YamlFormatError); !ok {
+		t.Fatalf("expected a YamlFormatError, got %v", d.Err)
+	}
+}
+
+func TestBuildErrorOfPatchWithoutTarget(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 3
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	d := kusterr.Details(err)
+	if d.Kustomization != "/app/kustomization.yaml" ||
+		d.Transformer != "PatchStrategicMergeTransformer" ||
+		d.ResId == nil || d.ResId.Name != "api" {
+		t.Fatalf("unexpected details: %#v", d)
+	}
+}
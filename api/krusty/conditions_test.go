@@ -0,0 +1,164 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeConditionalApp(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+params:
+  env: dev
+resources:
+- deployment.yaml
+- path: monitor.yaml
+  when: env in (prod, staging)
+components:
+- path: ../debug
+  when: env == dev
+patches:
+- when: env == prod
+  target:
+    kind: Deployment
+  patch: |-
+    - op: add
+      path: /spec/template/spec/containers/-
+      value:
+        name: exporter
+        image: exporter
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+`)
+	th.WriteF("/app/base/monitor.yaml", `
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+`)
+	th.WriteF("/app/debug/kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+commonLabels:
+  debug: "true"
+`)
+}
+
+func TestConditionsDefaults(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConditionalApp(th)
+	m := th.Run("/app/base", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    debug: "true"
+  name: web
+spec:
+  selector:
+    matchLabels:
+      debug: "true"
+  template:
+    metadata:
+      labels:
+        debug: "true"
+    spec:
+      containers:
+      - image: app
+        name: app
+`)
+}
+
+func TestConditionsParams(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConditionalApp(th)
+	opts := th.MakeDefaultOptions()
+	opts.Params = map[string]string{"env": "prod"}
+	m := th.Run("/app/base", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+      - image: exporter
+        name: exporter
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+`)
+}
+
+// The params of an overlay override the defaults of its
+// bases.
+func TestConditionsOverlayParams(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConditionalApp(th)
+	th.WriteK("/app/staging", `
+params:
+  env: staging
+namePrefix: staging-
+resources:
+- ../base
+`)
+	m := th.Run("/app/staging", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: staging-web
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: staging-web
+`)
+}
+
+func TestConditionsInvalidExpression(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- path: deployment.yaml
+  when: env > 2
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"invalid when expression 'env > 2': expected ==, !=, in or notin after 'env'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
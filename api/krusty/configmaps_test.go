@@ -288,3 +288,56 @@ metadata:
   name: cm-o2-gfcc59fg5m
 `)
 }
+
+func TestGeneratorStructuredAndDirSources(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+configMapGenerator:
+- name: settings
+  structured:
+  - settings.yaml
+  - legacy=legacy.json
+- name: conf
+  keySeparator: _
+  dirs:
+  - path: conf
+    exclude:
+    - "*.bak"
+`)
+	th.WriteF("/app/settings.yaml", `
+db:
+  host: db.example.com
+  port: 5432
+features: [search, chat]
+`)
+	th.WriteF("/app/legacy.json", `{"timeout": 30}`)
+	th.WriteF("/app/conf/nginx.conf", "worker_processes 1;\n")
+	th.WriteF("/app/conf/sites/a.conf", "server a;\n")
+	th.WriteF("/app/conf/sites/a.conf.bak", "server old;\n")
+	th.WriteF("/app/conf/favicon.ico", "\x00\x01\xff")
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  db.host: db.example.com
+  db.port: "5432"
+  features.0: search
+  features.1: chat
+  legacy.timeout: "30"
+kind: ConfigMap
+metadata:
+  name: settings-h7ccfc896h
+---
+apiVersion: v1
+binaryData:
+  favicon.ico: AAH/
+data:
+  nginx.conf: |
+    worker_processes 1;
+  sites_a.conf: |
+    server a;
+kind: ConfigMap
+metadata:
+  name: conf-8k77ctcc4c
+`)
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFromCRDs returns, as a configurations file, the
+// transformer configuration that builds learn from the
+// OpenAPI definitions in the files, as listed in the crds
+// field of kustomizations.  That includes the name
+// references of custom resources that are inferred from
+// the names and shapes of their fields.
+func ConfigFromCRDs(fSys filesys.FileSystem, paths []string) ([]byte, error) {
+	ldr, err := loader.NewLoader(
+		loader.RestrictionNone, filesys.SelfDir, fSys)
+	if err != nil {
+		return nil, err
+	}
+	defer ldr.Cleanup()
+	tc, err := accumulator.LoadConfigFromCRDs(ldr, paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, nbr := range tc.NameReference {
+		// FsSlice orders by kind only, so order
+		// the paths of a kind too.
+		fs := nbr.FieldSpecs
+		sort.Slice(fs, func(i, j int) bool {
+			if fs[i].Gvk.Equals(fs[j].Gvk) {
+				return fs[i].Path < fs[j].Path
+			}
+			return fs[i].Gvk.IsLessThan(fs[j].Gvk)
+		})
+	}
+	sort.Sort(tc.NameReference)
+	return yaml.Marshal(tc)
+}
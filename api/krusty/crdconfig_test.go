@@ -0,0 +1,125 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const proxyCrds = `
+example.com/v1.Proxy:
+  Schema:
+    properties:
+      apiVersion:
+        type: string
+      kind:
+        type: string
+      metadata:
+        $ref: k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta
+      spec:
+        $ref: example.com/v1.ProxySpec
+example.com/v1.ProxySpec:
+  Schema:
+    properties:
+      tlsSecretRef:
+        $ref: k8s.io/api/core/v1.LocalObjectReference
+      settingsConfigMap:
+        $ref: k8s.io/api/core/v1.LocalObjectReference
+      imagePullSecrets:
+        type: array
+        items:
+          $ref: k8s.io/api/core/v1.LocalObjectReference
+`
+
+func TestCrdInferredReferences(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+crds:
+- crds.yaml
+namePrefix: p-
+resources:
+- proxy.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - mode=fast
+secretGenerator:
+- name: tls
+  literals:
+  - key=secret
+`)
+	th.WriteF("/app/crds.yaml", proxyCrds)
+	th.WriteF("/app/proxy.yaml", `
+apiVersion: example.com/v1
+kind: Proxy
+metadata:
+  name: proxy
+spec:
+  tlsSecretRef:
+    name: tls
+  settingsConfigMap:
+    name: settings
+  imagePullSecrets:
+  - name: tls
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: example.com/v1
+kind: Proxy
+metadata:
+  name: p-proxy
+spec:
+  imagePullSecrets:
+  - name: p-tls-ckd2h57b75
+  settingsConfigMap:
+    name: p-settings-hdc45chm7c
+  tlsSecretRef:
+    name: p-tls-ckd2h57b75
+---
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  name: p-settings-hdc45chm7c
+---
+apiVersion: v1
+data:
+  key: c2VjcmV0
+kind: Secret
+metadata:
+  name: p-tls-ckd2h57b75
+type: Opaque
+`)
+}
+
+func TestConfigFromCRDs(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/crds.yaml", []byte(proxyCrds))
+	b, err := krusty.ConfigFromCRDs(fSys, []string{"/app/crds.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `nameReference:
+- fieldSpecs:
+  - kind: Proxy
+    path: spec/settingsConfigMap/name
+  kind: ConfigMap
+  version: v1
+- fieldSpecs:
+  - kind: Proxy
+    path: spec/imagePullSecrets/name
+  - kind: Proxy
+    path: spec/tlsSecretRef/name
+  kind: Secret
+  version: v1
+`
+	if string(b) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, string(b))
+	}
+}
@@ -92,3 +92,67 @@ metadata:
   name: zzz-myService
 `)
 }
+
+// A FieldTransformer in place of an exec plugin running sed.
+func TestFieldTransformers(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+transformers:
+- fields.yaml
+`)
+	th.WriteF("/app/fields.yaml", `
+apiVersion: builtin
+kind: FieldTransformer
+metadata:
+  name: registry
+fieldSpecs:
+- path: spec/template/spec/containers/image
+operation: replace
+regex: ^docker\.io/
+value: mirror.example.com/
+---
+apiVersion: builtin
+kind: FieldTransformer
+metadata:
+  name: component
+fieldSpecs:
+- path: spec/template/metadata/labels/component
+  create: true
+operation: template
+value: '{{.metadata.name}}-{{index .metadata.labels "tier"}}'
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: docker.io/nginx:1.19
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: frontend
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        component: web-frontend
+    spec:
+      containers:
+      - image: mirror.example.com/nginx:1.19
+        name: web
+`)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/kv"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// Encrypted with age to the public key of testAgeKey.
+const (
+	testAgeKey = "AGE-SECRET-KEY-1FDQAVSDXEVEJWC5XRQSJR9SYVECUGSWHVJRASVUUEYRJ75KF5UPQ5UMF3V"
+
+	encryptedDbEnv = `
+-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBkeE54QklQSHlCQmxCaUg4
+cktReUhIbTVhRFdkVDg4RWF5Z1ArN3pPMFcwCm1lNjBvZmZuVVZrdVZpZXZaSXBI
+dkFqUVBHTHo0OWZjWTVzM1VsYWxCOE0KLS0tIFFQb3MrSEJ6VmlZZmZ5ajd6K3FG
+eDhUMHhDWTcwZDNteXpXdUpxUXo3OHMKOD3crA1F7NgMGEodhkClh//74lJUhB5J
+9mZqOk3pNMoSrbg5xG3JOB5EKtvcJfzpXAJTITRhn78N3q1t
+-----END AGE ENCRYPTED FILE-----
+`
+	encryptedTlsKey = `
+-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSArK2FXTkdaU2hYU3c1WUc1
+UWEyRzR2YXU0Sk4vdjVueGhpMHliUDVWeDBFCmZmVnpMWlhZK3NwNmZHSVpNNFZn
+UmViYytubmtYWWNCTXcrU2FPdkpPSlUKLS0tIEJYN2k0Rkp0blc3VXNiSi9BWFVY
+K2Ixdm9LQTlia3BLN0R3bVJoZnhORG8KTFypgn/g9n5WTnwlS8mfrkx2lOhaNW0C
+nz2O5bawfG4FmmSZDSBt9/lkyRdetdVcsNXavw==
+-----END AGE ENCRYPTED FILE-----
+`
+)
+
+func writeEncryptedSecret(th kusttest_test.Harness) {
+	th.WriteF("/app/db.env.age", encryptedDbEnv)
+	th.WriteF("/app/tls.key.age", encryptedTlsKey)
+	th.WriteK("/app", `
+secretGenerator:
+- name: db
+  literals:
+  - HOST=db.example.com
+  encryptedEnvs:
+  - db.env.age
+  encryptedFiles:
+  - tls.key.age
+`)
+}
+
+func TestEncryptedSecretSources(t *testing.T) {
+	defer os.Unsetenv(kv.AgeKeyEnv)
+	os.Setenv(kv.AgeKeyEnv, testAgeKey)
+	th := kusttest_test.MakeHarness(t)
+	writeEncryptedSecret(th)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  HOST: ZGIuZXhhbXBsZS5jb20=
+  PASSWORD: aHVudGVyMg==
+  USER: YWRtaW4=
+  tls.key: LS0tLS1CRUdJTiBLRVktLS0tLQo=
+kind: Secret
+metadata:
+  name: db-g2bg9h5mhd
+type: Opaque
+`)
+}
+
+func TestEncryptedSecretSourcesWithoutKey(t *testing.T) {
+	os.Unsetenv(kv.AgeKeyEnv)
+	os.Unsetenv(kv.AgeKeyFileEnv)
+	th := kusttest_test.MakeHarness(t)
+	writeEncryptedSecret(th)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || err.Error() != "encrypted env source files: [db.env.age]: "+
+		"decrypting db.env.age: no age key to decrypt with; "+
+		"set KUSTOMIZE_AGE_KEY or KUSTOMIZE_AGE_KEY_FILE" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// makeFunctionOptions returns options allowing
+// non-builtin plugins, which functions are.
+func makeFunctionOptions(th kusttest_test.Harness) krusty.Options {
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig = konfig.MakePluginConfig(
+		types.PluginRestrictionsNone, types.BploUseStaticallyLinked, "")
+	return opts
+}
+
+func writeFunctionBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- settings.yaml
+- deployment.yaml
+transformers:
+- team.yaml
+`)
+	th.WriteF("/app/base/settings.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: fast
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      volumes:
+      - name: settings
+        configMap:
+          name: settings
+      containers:
+      - name: app
+        image: app
+`)
+	th.WriteF("/app/base/team.yaml", `
+apiVersion: example.com/v1
+kind: Team
+metadata:
+  name: team
+  annotations:
+    config.kubernetes.io/function: |
+      starlark: {path: team.star, name: team}
+spec:
+  team: payments
+`)
+	th.WriteF("/app/base/team.star", `
+def run(items, team):
+  for r in items:
+    r["metadata"].setdefault("labels", {})["team"] = team
+
+run(ctx.resource_list["items"], ctx.resource_list["functionConfig"]["spec"]["team"])
+`)
+}
+
+func TestFunctionTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFunctionBase(th)
+	th.WriteK("/app/prod", `
+namePrefix: prod-
+resources:
+- ../base
+`)
+	m := th.Run("/app/prod", makeFunctionOptions(th))
+	// The names changed by the overlay are updated in the
+	// deployment, so kustomize still knows the resources
+	// after the function ran.
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  labels:
+    team: payments
+  name: prod-settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: payments
+  name: prod-web
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+      volumes:
+      - configMap:
+          name: prod-settings
+        name: settings
+`)
+}
+
+func TestFunctionTransformerRemovesResources(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFunctionBase(th)
+	th.WriteK("/app/prod", `
+resources:
+- ../base
+transformers:
+- drop.yaml
+`)
+	th.WriteF("/app/prod/drop.yaml", `
+apiVersion: example.com/v1
+kind: Drop
+metadata:
+  name: drop
+  annotations:
+    config.kubernetes.io/function: |
+      starlark: {path: drop.star, name: drop}
+`)
+	th.WriteF("/app/prod/drop.star", `
+ctx.resource_list["items"] = [
+  r for r in ctx.resource_list["items"] if r["kind"] != "ConfigMap"]
+`)
+	m := th.Run("/app/prod", makeFunctionOptions(th))
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: payments
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+      volumes:
+      - configMap:
+          name: settings
+        name: settings
+`)
+}
+
+func TestFunctionGenerator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: p-
+generators:
+- gen.yaml
+`)
+	th.WriteF("/app/gen.yaml", `
+apiVersion: example.com/v1
+kind: Settings
+metadata:
+  name: gen
+  annotations:
+    config.kubernetes.io/function: |
+      starlark: {path: gen.star, name: gen}
+data:
+  mode: fast
+`)
+	th.WriteF("/app/gen.star", `
+fc = ctx.resource_list["functionConfig"]
+ctx.resource_list["items"] = [{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {"name": "settings"},
+  "data": fc["data"],
+}]
+`)
+	m := th.Run("/app", makeFunctionOptions(th))
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  name: p-settings
+`)
+}
+
+func TestFunctionArgsAndLoad(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+generators:
+- gen.yaml
+`)
+	th.WriteF("/app/gen.yaml", `
+apiVersion: example.com/v1
+kind: Settings
+metadata:
+  name: gen
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: fn/gen.star
+        name: gen
+        args:
+          mode: fast
+`)
+	th.WriteF("/app/fn/gen.star", `
+load("lib/names.star", "named")
+
+ctx.resource_list["items"] = [named("settings", {"mode": ctx.args["mode"]})]
+`)
+	th.WriteF("/app/fn/lib/names.star", `
+def named(name, data):
+  return {
+    "apiVersion": "v1",
+    "kind": "ConfigMap",
+    "metadata": {"name": "team-" + name},
+    "data": data,
+  }
+`)
+	m := th.Run("/app", makeFunctionOptions(th))
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  name: team-settings
+`)
+}
+
+func writeFunctionValidator(th kusttest_test.Harness) {
+	th.WriteK("/app/prod", `
+resources:
+- ../base
+validators:
+- check.yaml
+`)
+	th.WriteF("/app/prod/check.yaml", `
+apiVersion: example.com/v1
+kind: Check
+metadata:
+  name: check
+  annotations:
+    config.kubernetes.io/function: |
+      starlark: {path: check.star, name: check}
+`)
+	th.WriteF("/app/prod/check.star", `
+def run(items):
+  for r in items:
+    if r["kind"] == "Deployment" and "replicas" not in r["spec"]:
+      fail("deployment " + r["metadata"]["name"] + " has no replicas")
+    # changes made by validators are discarded
+    r["metadata"]["name"] = "changed"
+
+run(ctx.resource_list["items"])
+`)
+}
+
+func TestFunctionValidator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFunctionBase(th)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`)
+	writeFunctionValidator(th)
+	m := th.Run("/app/prod", makeFunctionOptions(th))
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  labels:
+    team: payments
+  name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: payments
+  name: web
+spec:
+  replicas: 2
+`)
+}
+
+func TestFunctionValidatorFails(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFunctionBase(th)
+	writeFunctionValidator(th)
+	err := th.RunWithErr("/app/prod", makeFunctionOptions(th))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "deployment web has no replicas") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestFunctionNeedsPluginsEnabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFunctionBase(th)
+	err := th.RunWithErr("/app/base", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !types.IsErrOnlyBuiltinPluginsAllowed(err) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
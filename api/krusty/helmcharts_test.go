@@ -0,0 +1,173 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// A stand-in for 'helm template' that emits a
+// Deployment named after the release.
+const fakeHelmTemplate = `#!/bin/bash
+cat <<EOF
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: $2-server
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: server
+        image: server:1.0
+EOF
+`
+
+// writeFakeHelm writes the fake helm command to dir.
+func writeFakeHelm(t *testing.T, dir string) string {
+	helm := filepath.Join(dir, "helm")
+	err := ioutil.WriteFile(helm, []byte(fakeHelmTemplate), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return helm
+}
+
+// optionsWithHelm returns the default options of the
+// harness, with helm enabled.
+func optionsWithHelm(th kusttest_test.Harness, helm string) krusty.Options {
+	options := th.MakeDefaultOptions()
+	options.PluginConfig.HelmConfig.Enabled = true
+	options.PluginConfig.HelmConfig.Command = helm
+	return options
+}
+
+// The chart output is a normal operand: the namePrefix,
+// commonLabels and patches of the kustomization apply to it.
+func TestHelmChartsAreCustomized(t *testing.T) {
+	dir := makeTmpDir(t)
+	defer os.RemoveAll(dir)
+	helm := writeFakeHelm(t, dir)
+
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/charts/server/Chart.yaml", `
+name: server
+`)
+	th.WriteK("/app", `
+helmCharts:
+- chartName: server
+  releaseName: demo
+namePrefix: prod-
+commonLabels:
+  env: prod
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: demo-server
+spec:
+  replicas: 3
+`)
+	m := th.Run("/app", optionsWithHelm(th, helm))
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: prod-demo-server
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+    spec:
+      containers:
+      - image: server:1.0
+        name: server
+`)
+}
+
+func TestHelmChartsNeedEnableHelm(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/charts/server/Chart.yaml", `
+name: server
+`)
+	th.WriteK("/app", `
+helmCharts:
+- chartName: server
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if !strings.Contains(err.Error(), "must specify --enable-helm") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHelmChartsMustBeWithinRoot(t *testing.T) {
+	dir := makeTmpDir(t)
+	defer os.RemoveAll(dir)
+	helm := writeFakeHelm(t, dir)
+
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/charts/server/Chart.yaml", `
+name: server
+`)
+	for _, home := range []string{"/charts", "../charts"} {
+		th.WriteK("/app", `
+helmCharts:
+- chartName: server
+  chartHome: `+home+`
+`)
+		err := th.RunWithErr("/app", optionsWithHelm(th, helm))
+		if !strings.Contains(err.Error(), "must be within the kustomization root") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+// The helm command is set by whoever runs kustomize; a
+// remote base can't choose it.
+func TestRemoteBaseCannotSetHelmCommand(t *testing.T) {
+	bundle := makeTar(t, "base/kustomization.yaml", `
+helmCharts:
+- chartName: server
+  helmBin: /tmp/evil
+`)
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(bundle)
+		}))
+	defer srv.Close()
+
+	dir := makeTmpDir(t)
+	defer os.RemoveAll(dir)
+	helm := writeFakeHelm(t, dir)
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	th.WriteK(dir, `
+resources:
+- `+srv.URL+`/bundle.tar//base
+`)
+	err := th.RunWithErr(dir, optionsWithHelm(th, helm))
+	if !strings.Contains(err.Error(), "helmCharts[0].helmBin: unknown field") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeInventoryBase(th kusttest_test.Harness) {
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteF("/app/role.yaml", `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:web
+`)
+}
+
+func TestInventoryConfigMap(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInventoryBase(th)
+	th.WriteK("/app", `
+namespace: prod
+resources:
+- deployment.yaml
+- role.yaml
+inventory:
+  type: ConfigMap
+  configMap:
+    name: inventory
+    namespace: prod
+`)
+	// Without pruning, the inventory is ignored.
+	m := th.Run("/app", th.MakeDefaultOptions())
+	if m.Size() != 2 {
+		t.Fatalf("expected no inventory, got %d resources", m.Size())
+	}
+
+	options := th.MakeDefaultOptions()
+	options.DoPrune = true
+	m = th.Run("/app", options)
+	yml, err := m.AsYaml()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inventory := string(yml[strings.LastIndex(string(yml), "---\n")+4:])
+	inventory = inventory[:strings.Index(inventory, "    kustomize.config.k8s.io/inventory-hash")]
+	if inventory != `apiVersion: v1
+data:
+  _system__web_rbac.authorization.k8s.io_ClusterRole: ""
+  prod_web_apps_Deployment: ""
+kind: ConfigMap
+metadata:
+  annotations:
+` {
+		t.Fatalf("unexpected inventory:\n%s", inventory)
+	}
+	if !strings.HasSuffix(string(yml), `
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: prod.inventory
+  name: inventory
+  namespace: prod
+`) {
+		t.Fatalf("unexpected inventory:\n%s", yml)
+	}
+}
+
+func TestInventoryResourceGroup(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInventoryBase(th)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+- role.yaml
+inventory:
+  type: ResourceGroup
+  resourceGroup:
+    name: web-inventory
+`)
+	options := th.MakeDefaultOptions()
+	options.DoPrune = true
+	m := th.Run("/app", options)
+	if m.Size() != 3 {
+		t.Fatalf("expected an inventory, got %d resources", m.Size())
+	}
+	r := m.Resources()[2]
+	if r.GetKind() != "ResourceGroup" || r.GetName() != "web-inventory" {
+		t.Fatalf("unexpected inventory %s", r.CurId())
+	}
+	yml, err := r.AsYAML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(yml), `spec:
+  resources:
+  - group: rbac.authorization.k8s.io
+    kind: ClusterRole
+    name: system:web
+    namespace: ""
+  - group: apps
+    kind: Deployment
+    name: web
+    namespace: ""
+`) {
+		t.Fatalf("unexpected inventory:\n%s", yml)
+	}
+}
+
+func TestInventoryWithoutName(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInventoryBase(th)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+inventory:
+  type: ConfigMap
+`)
+	options := th.MakeDefaultOptions()
+	options.DoPrune = true
+	err := th.RunWithErr("/app", options)
+	if err == nil || !strings.Contains(err.Error(), "inventory must have a name") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -5,6 +5,8 @@ package krusty
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 
 	"sigs.k8s.io/kustomize/api/builtins"
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -14,6 +16,7 @@ import (
 	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
 	"sigs.k8s.io/kustomize/api/k8sdeps/validator"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/kusterr"
 	fLdr "sigs.k8s.io/kustomize/api/loader"
 	"sigs.k8s.io/kustomize/api/provenance"
 	"sigs.k8s.io/kustomize/api/resmap"
@@ -49,6 +52,111 @@ func MakeKustomizer(fSys filesys.FileSystem, o *Options) *Kustomizer {
 // on any number of internal paths (e.g. the filesystem may contain
 // multiple overlays, and Run can be called on each of them).
 func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
+	repos, done, err := b.repoCache()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	lock, err := b.readLock(path)
+	if err != nil {
+		return nil, err
+	}
+	return b.run(path, repos, nil, lock)
+}
+
+// Lock performs a kustomization like Run, except that any
+// lock file is ignored, and returns a lock of the versions
+// of the remote bases and files that were fetched.
+func (b *Kustomizer) Lock(path string) (*types.KustomizationLock, error) {
+	repos, done, err := b.repoCache()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	lock := fLdr.NewLock(nil, false)
+	if _, err := b.run(path, repos, nil, lock); err != nil {
+		return nil, err
+	}
+	return lock.Fetched(), nil
+}
+
+// repoCache returns the cache of the remote bases of a
+// build, and the function to call once the build is done.
+// Unless RepoCacheDir is set, the cache is a temporary
+// directory of the build's own.
+func (b *Kustomizer) repoCache() (*fLdr.RepoCache, func(), error) {
+	if b.options.RepoCacheDir != "" {
+		return fLdr.NewRepoCache(
+			b.options.RepoCacheDir, b.options.RepoCacheTTL), func() {}, nil
+	}
+	dir, err := ioutil.TempDir("", "kustomize-repos-")
+	if err != nil {
+		return nil, nil, err
+	}
+	return fLdr.NewRepoCache(dir, 0), func() { os.RemoveAll(dir) }, nil
+}
+
+// readLock returns a Lock made from the lock file of the
+// kustomization at path, or nil if there's no lock file.
+func (b *Kustomizer) readLock(path string) (*fLdr.Lock, error) {
+	var pins *types.KustomizationLock
+	if b.fSys.IsDir(path) {
+		var err error
+		if pins, err = fLdr.ReadLockFile(b.fSys, path); err != nil {
+			return nil, err
+		}
+	}
+	if pins == nil {
+		if b.options.Locked {
+			return nil, fmt.Errorf(
+				"locked build, but %s has no %s", path, konfig.LockFileName)
+		}
+		return nil, nil
+	}
+	return fLdr.NewLock(pins, b.options.Locked), nil
+}
+
+// RunResult is the outcome of one kustomization
+// performed by RunMany.
+type RunResult struct {
+	// The path given to RunMany.
+	Path string
+	// The resulting resources, if Err is nil.
+	ResMap resmap.ResMap
+	Err    error
+}
+
+// RunMany performs the kustomizations at the given paths,
+// as Run would, returning one result per path, in order.
+// A failed kustomization doesn't stop the others.
+//
+// Bases shared by the kustomizations are only accumulated
+// (and remote bases only fetched) once.
+func (b *Kustomizer) RunMany(paths []string) []RunResult {
+	repos, done, err := b.repoCache()
+	results := make([]RunResult, len(paths))
+	if err != nil {
+		for i, path := range paths {
+			results[i] = RunResult{Path: path, Err: err}
+		}
+		return results
+	}
+	defer done()
+	bases := target.NewBaseCache()
+	for i, path := range paths {
+		lock, err := b.readLock(path)
+		var m resmap.ResMap
+		if err == nil {
+			m, err = b.run(path, repos, bases, lock)
+		}
+		results[i] = RunResult{Path: path, ResMap: m, Err: err}
+	}
+	return results
+}
+
+func (b *Kustomizer) run(
+	path string, repos *fLdr.RepoCache,
+	bases *target.BaseCache, lock *fLdr.Lock) (resmap.ResMap, error) {
 	pf := transformer.NewFactoryImpl()
 	rf := resmap.NewFactory(
 		resource.NewFactory(
@@ -58,7 +166,7 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 	if b.options.LoadRestrictions == types.LoadRestrictionsRootOnly {
 		lr = fLdr.RestrictionRootOnly
 	}
-	ldr, err := fLdr.NewLoader(lr, path, b.fSys)
+	ldr, err := fLdr.NewLockedLoader(lr, path, b.fSys, repos, lock)
 	if err != nil {
 		return nil, err
 	}
@@ -70,15 +178,38 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 		pf,
 		pLdr.NewLoader(b.options.PluginConfig, rf),
 	)
+	if b.options.Strict {
+		kt.ValidateStrictly()
+	}
+	if b.options.Validate {
+		kt.ValidateSchemas()
+	}
+	kt.UseParams(b.options.Params)
 	err = kt.Load()
 	if err != nil {
 		return nil, err
 	}
+	if b.options.TrackOrigins {
+		kt.TrackOrigins()
+	}
+	if bases != nil {
+		kt.UseBaseCache(bases)
+	}
 	var m resmap.ResMap
 	m, err = kt.MakeCustomizedResMap()
 	if err != nil {
 		return nil, err
 	}
+	err = kt.CheckValidations(m)
+	if v, ok := err.(*kusterr.ValidationError); ok && b.options.ValidationWarnOnly {
+		if b.options.ValidationWarnings != nil {
+			b.options.ValidationWarnings(v)
+		}
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
 	if b.options.DoLegacyResourceSort {
 		builtins.NewLegacyOrderTransformerPlugin().Transform(m)
 	}
@@ -92,5 +223,11 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 		}
 		t.Transform(m)
 	}
+	if b.options.DoPrune {
+		err = kt.AddInventory(m)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return m, nil
 }
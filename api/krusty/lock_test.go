@@ -0,0 +1,99 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/loader"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func makeTar(t *testing.T, name, content string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name: name, Mode: 0644, Size: int64(len(content)),
+		Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(content))
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLockedBuild(t *testing.T) {
+	bundle := makeTar(t, "base/kustomization.yaml", `
+configMapGenerator:
+- name: cm
+  literals:
+  - color=red
+`)
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(bundle)
+		}))
+	defer srv.Close()
+
+	// The bundle is unpacked on disk.
+	dir := makeTmpDir(t)
+	defer os.RemoveAll(dir)
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	th.WriteK(dir, `
+resources:
+- `+srv.URL+`/bundle.tar//base
+`)
+	options := th.MakeDefaultOptions()
+	options.Locked = true
+	_, err := krusty.MakeKustomizer(th.GetFSys(), &options).Run(dir)
+	if err == nil || !strings.Contains(err.Error(), dir+" has no kustomization.lock") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	options.Locked = false
+	lock, err := krusty.MakeKustomizer(th.GetFSys(), &options).Lock(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lock.Remotes) != 1 || lock.Remotes[0].Url != srv.URL+"/bundle.tar" ||
+		!strings.HasPrefix(lock.Remotes[0].Sum, "sha256:") {
+		t.Fatalf("unexpected lock: %v", lock)
+	}
+	if err = loader.WriteLockFile(th.GetFSys(), dir, lock); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	options.Locked = true
+	m := th.Run(dir, options)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  color: red
+kind: ConfigMap
+metadata:
+  name: cm-c5m9hc2mfc
+`)
+
+	bundle = makeTar(t, "base/kustomization.yaml", `
+configMapGenerator:
+- name: cm
+  literals:
+  - color=blue
+`)
+	_, err = krusty.MakeKustomizer(th.GetFSys(), &options).Run(dir)
+	if err == nil || !strings.Contains(err.Error(),
+		srv.URL+"/bundle.tar doesn't match kustomization.lock") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
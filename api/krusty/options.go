@@ -4,7 +4,10 @@
 package krusty
 
 import (
+	"time"
+
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/kusterr"
 	"sigs.k8s.io/kustomize/api/types"
 )
 
@@ -27,10 +30,62 @@ type Options struct {
 	// See type definition.
 	LoadRestrictions types.LoadRestrictions
 
-	// Create an inventory object for pruning.
+	// Append the inventory object that the kustomization's
+	// inventory field asks for, listing the other objects
+	// in the build output, for pruning.
 	DoPrune bool
 
-	// Options related to kustomize plugins.
+	// When true, each resource in the build output is
+	// annotated with its origin (the file it was read
+	// from, or the generator that made it), and with the
+	// list of transformers that changed it.
+	TrackOrigins bool
+
+	// Copies of remote bases are kept in this directory,
+	// one per repository and ref, and each is fetched at
+	// most once per build.  If empty, they're kept in a
+	// temporary directory removed after the build.
+	RepoCacheDir string
+
+	// Copies in RepoCacheDir left by earlier builds are
+	// reused if younger than this; otherwise they're fetched
+	// again.
+	RepoCacheTTL time.Duration
+
+	// When true, the build fails unless every remote base
+	// and file fetched is recorded in the kustomization.lock
+	// file next to the kustomization, and matches it.
+	// Without this, the remotes that a lock file records
+	// must still match it, but others may be fetched.
+	Locked bool
+
+	// When true, kustomization files are checked against
+	// their schema, which rejects fields whose names differ
+	// only in case from known fields (e.g. 'commonlabels'),
+	// though decoding would accept them.
+	Strict bool
+
+	// The build parameters that the when expressions of
+	// kustomization files refer to.  They override the
+	// defaults in the params fields of the files.
+	Params map[string]string
+
+	// When true, every resource of the output is checked
+	// against its OpenAPI schema: that of its Kubernetes
+	// kind, or of a crds entry or CustomResourceDefinition
+	// of the build.  Resources of kinds without a schema
+	// aren't checked.
+	Validate bool
+
+	// When true, resources breaking the validations of the
+	// kustomization, or their schemas, don't fail the build; the failures are
+	// passed to ValidationWarnings instead, if it's set.
+	ValidationWarnOnly bool
+	ValidationWarnings func(*kusterr.ValidationError)
+
+	// Options related to kustomize plugins, including
+	// whether helm charts may be inflated, and the helm
+	// command to run.
 	PluginConfig *types.PluginConfig
 }
 
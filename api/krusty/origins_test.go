@@ -0,0 +1,150 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestTrackOrigins(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - color=blue
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+- service.yaml
+namePrefix: prod-
+commonLabels:
+  env: prod
+`)
+	th.WriteF("/app/overlay/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    env: prod
+`)
+	options := th.MakeDefaultOptions()
+	options.TrackOrigins = true
+	m := th.Run("/app/overlay", options)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    alpha.config.kubernetes.io/transformations: |
+      - configuredBy:
+          apiVersion: builtin
+          kind: PrefixSuffixTransformer
+        configuredIn: kustomization.yaml
+      - configuredBy:
+          apiVersion: builtin
+          kind: LabelTransformer
+        configuredIn: kustomization.yaml
+    config.kubernetes.io/origin: |
+      path: ../base/deployment.yaml
+  labels:
+    env: prod
+  name: prod-web
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  annotations:
+    alpha.config.kubernetes.io/transformations: |
+      - configuredBy:
+          apiVersion: builtin
+          kind: PrefixSuffixTransformer
+        configuredIn: kustomization.yaml
+      - configuredBy:
+          apiVersion: builtin
+          kind: LabelTransformer
+        configuredIn: kustomization.yaml
+      - configuredBy:
+          apiVersion: builtin
+          kind: HashTransformer
+        configuredIn: kustomization.yaml
+    config.kubernetes.io/origin: |
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+      configuredIn: ../base/kustomization.yaml
+  labels:
+    env: prod
+  name: prod-config-gkk6dmgk42
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    alpha.config.kubernetes.io/transformations: |
+      - configuredBy:
+          apiVersion: builtin
+          kind: PrefixSuffixTransformer
+        configuredIn: kustomization.yaml
+      - configuredBy:
+          apiVersion: builtin
+          kind: LabelTransformer
+        configuredIn: kustomization.yaml
+    config.kubernetes.io/origin: |
+      path: service.yaml
+  labels:
+    env: prod
+  name: prod-web
+spec:
+  selector:
+    env: prod
+`)
+}
+
+func TestTrackOriginsDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+namePrefix: prod-
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: prod-web
+`)
+}
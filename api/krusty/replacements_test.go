@@ -0,0 +1,166 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestReplacementsField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: settings
+    fieldref: data.db-host
+  target:
+    objref:
+      kind: Deployment
+      labelSelector: tier=backend
+    fieldrefs:
+    - spec.template.spec.containers[name=app].env[name=DB_HOST].value
+- source:
+    objref:
+      kind: Deployment
+      name: frontend
+    fieldref: spec.template.spec.containers.0.image
+    options:
+      delimiter: ':'
+      index: 1
+  target:
+    objref:
+      kind: Deployment
+      name: backend
+    fieldrefs:
+    - spec.template.spec.containers[name=app].image
+    options:
+      delimiter: ':'
+      index: 1
+- source:
+    value: backend-sa
+  target:
+    objref:
+      kind: Deployment
+      annotationSelector: needs-sa
+    fieldrefs:
+    - spec.template.spec.serviceAccountName
+    options:
+      create: true
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  db-host: postgres.db.svc
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: example/frontend:1.4.2
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  labels:
+    tier: backend
+  annotations:
+    needs-sa: "true"
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: example/backend:1.0.0
+        env:
+        - name: DB_HOST
+          value: localhost
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  db-host: postgres.db.svc
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - image: example/frontend:1.4.2
+        name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    needs-sa: "true"
+  labels:
+    tier: backend
+  name: backend
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: DB_HOST
+          value: postgres.db.svc
+        image: example/backend:1.4.2
+        name: app
+      serviceAccountName: backend-sa
+`)
+}
+
+func TestReplacementsFieldMissingSource(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: nonexistent
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.serviceAccountName
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"failed to find one resource matching source") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
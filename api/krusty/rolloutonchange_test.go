@@ -0,0 +1,210 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filters/checksum"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeRolloutBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- settings.yaml
+- deployment.yaml
+`)
+	th.WriteF("/app/base/settings.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: fast
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      volumes:
+      - name: settings
+        configMap:
+          name: settings
+      containers:
+      - name: app
+        image: app
+`)
+}
+
+func TestRolloutOnChange(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeRolloutBase(th)
+	th.WriteK("/app/prod", `
+rolloutOnChange: true
+namePrefix: prod-
+resources:
+- ../base
+`)
+	m := th.Run("/app/prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  name: prod-settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+spec:
+  template:
+    metadata:
+      annotations:
+        kustomize.config.k8s.io/checksum: 646d4k48h6
+    spec:
+      containers:
+      - image: app
+        name: app
+      volumes:
+      - configMap:
+          name: prod-settings
+        name: settings
+`)
+
+	// Changing the ConfigMap changes the checksum.
+	th.WriteK("/app/slow", `
+rolloutOnChange: true
+namePrefix: prod-
+resources:
+- ../base
+patchesStrategicMerge:
+- settings.yaml
+`)
+	th.WriteF("/app/slow/settings.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: slow
+`)
+	fast := checksumOf(t, m)
+	slow := checksumOf(t, th.Run("/app/slow", th.MakeDefaultOptions()))
+	if fast == "" || fast == slow {
+		t.Fatalf("expected differing checksums, got %q and %q", fast, slow)
+	}
+
+	// Without the field, nothing is annotated.
+	if c := checksumOf(t, th.Run("/app/base", th.MakeDefaultOptions())); c != "" {
+		t.Fatalf("unexpected checksum %q", c)
+	}
+}
+
+func TestRolloutOnChangeInBase(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeRolloutBase(th)
+	th.WriteK("/app/rolled", `
+rolloutOnChange: true
+resources:
+- ../base
+`)
+	th.WriteK("/app/other", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/app/other/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    spec:
+      volumes:
+      - name: settings
+        configMap:
+          name: prod-settings
+      containers:
+      - name: worker
+        image: worker
+`)
+	// Only the workloads of the base asking for it get
+	// checksums, of the final output.
+	th.WriteK("/app/prod", `
+namePrefix: prod-
+resources:
+- ../rolled
+- ../other
+`)
+	m := th.Run("/app/prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  name: prod-settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+spec:
+  template:
+    metadata:
+      annotations:
+        kustomize.config.k8s.io/checksum: 646d4k48h6
+    spec:
+      containers:
+      - image: app
+        name: app
+      volumes:
+      - configMap:
+          name: prod-settings
+        name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-worker
+spec:
+  template:
+    spec:
+      containers:
+      - image: worker
+        name: worker
+      volumes:
+      - configMap:
+          name: prod-settings
+        name: settings
+`)
+}
+
+// checksumOf returns the checksum of the pod template of
+// the Deployment.
+func checksumOf(t *testing.T, m resmap.ResMap) string {
+	t.Helper()
+	for _, r := range m.Resources() {
+		if r.GetKind() != "Deployment" {
+			continue
+		}
+		var obj interface{} = r.Map()
+		for _, f := range []string{"spec", "template", "metadata", "annotations"} {
+			fields, _ := obj.(map[string]interface{})
+			obj = fields[f]
+		}
+		annotations, _ := obj.(map[string]interface{})
+		c, _ := annotations[checksum.Annotation].(string)
+		return c
+	}
+	return ""
+}
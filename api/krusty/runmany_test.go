@@ -0,0 +1,185 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// readCountingFs counts the reads of each file.
+type readCountingFs struct {
+	filesys.FileSystem
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func (fs *readCountingFs) ReadFile(path string) ([]byte, error) {
+	fs.mu.Lock()
+	fs.reads[path]++
+	fs.mu.Unlock()
+	return fs.FileSystem.ReadFile(path)
+}
+
+func TestRunMany(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+commonLabels:
+  app: web
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteK("/app/dev", `
+resources:
+- ../base
+namePrefix: dev-
+`)
+	th.WriteK("/app/prod", `
+resources:
+- ../base
+namePrefix: prod-
+`)
+	th.WriteK("/app/broken", `
+resources:
+- ../base
+- missing.yaml
+`)
+	fSys := &readCountingFs{
+		FileSystem: th.GetFSys(), reads: make(map[string]int)}
+	options := th.MakeDefaultOptions()
+	results := krusty.MakeKustomizer(fSys, &options).RunMany(
+		[]string{"/app/dev", "/app/broken", "/app/prod"})
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[1].Path != "/app/broken" || results[1].Err == nil ||
+		!strings.Contains(results[1].Err.Error(), "missing.yaml") {
+		t.Fatalf("unexpected result for broken target: %v", results[1])
+	}
+	for i, prefix := range map[int]string{0: "dev", 2: "prod"} {
+		if results[i].Err != nil {
+			t.Fatalf("unexpected error: %v", results[i].Err)
+		}
+		th.AssertActualEqualsExpected(results[i].ResMap, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: web
+  name: `+prefix+`-web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+`)
+	}
+	if n := fSys.reads["/app/base/deployment.yaml"]; n != 1 {
+		t.Fatalf("expected the base to be read once, got %d reads", n)
+	}
+}
+
+// Bases built with other parameters aren't shared.
+func TestRunManyParams(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+- path: monitor.yaml
+  when: env == prod
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteF("/app/base/monitor.yaml", `
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+`)
+	for _, env := range []string{"dev", "prod"} {
+		th.WriteK("/app/"+env, `
+params:
+  env: `+env+`
+resources:
+- ../base
+`)
+	}
+	options := th.MakeDefaultOptions()
+	results := krusty.MakeKustomizer(th.GetFSys(), &options).RunMany(
+		[]string{"/app/dev", "/app/prod"})
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatalf("unexpected error: %v", r.Err)
+		}
+	}
+	if n := results[0].ResMap.Size(); n != 1 {
+		t.Fatalf("expected 1 resource for dev, got %d", n)
+	}
+	if n := results[1].ResMap.Size(); n != 2 {
+		t.Fatalf("expected 2 resources for prod, got %d", n)
+	}
+}
+
+// slowFs takes a while to read each file, so that
+// concurrent loaders overlap.
+type slowFs struct {
+	filesys.FileSystem
+}
+
+func (fs slowFs) ReadFile(path string) ([]byte, error) {
+	time.Sleep(20 * time.Millisecond)
+	return fs.FileSystem.ReadFile(path)
+}
+
+func TestRunManySiblingCycle(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/root", `
+resources:
+- ../p
+- ../q
+`)
+	th.WriteK("/app/p", `
+resources:
+- ../q
+`)
+	th.WriteK("/app/q", `
+resources:
+- ../p
+`)
+	options := th.MakeDefaultOptions()
+	done := make(chan []krusty.RunResult)
+	go func() {
+		done <- krusty.MakeKustomizer(slowFs{th.GetFSys()}, &options).RunMany(
+			[]string{"/app/root"})
+	}()
+	select {
+	case results := <-done:
+		if results[0].Err == nil ||
+			!strings.Contains(results[0].Err.Error(), "cycle detected") {
+			t.Fatalf("expected a cycle error, got %v", results[0].Err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the cycle to fail the build, not hang it")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestScaling(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+- hpa.yaml
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: migrate
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 100m
+      - name: proxy
+        image: proxy
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: db
+        image: db
+`)
+	th.WriteF("/app/base/hpa.yaml", `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 1
+  maxReplicas: 3
+`)
+	th.WriteK("/app/prod", `
+namePrefix: prod-
+resources:
+- ../base
+scaling:
+- selector:
+    labelSelector: tier=web
+  replicas: 4
+  resources:
+  - container: app
+    requests:
+      cpu: 500m
+      memory: 256Mi
+    limits:
+      memory: 512Mi
+  minReplicas: 4
+  maxReplicas: 12
+- selector:
+    kind: StatefulSet
+    name: db
+  replicas: 3
+`)
+	m := th.Run("/app/prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: prod-web
+spec:
+  replicas: 4
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+        resources:
+          limits:
+            memory: 512Mi
+          requests:
+            cpu: 500m
+            memory: 256Mi
+      - image: proxy
+        name: proxy
+      initContainers:
+      - image: migrate
+        name: migrate
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: prod-db
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: db
+        name: db
+---
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: prod-web
+spec:
+  maxReplicas: 12
+  minReplicas: 4
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: prod-web
+`)
+}
+
+func TestScalingSelectsNothing(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+scaling:
+- selector:
+    name: api
+  replicas: 2
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || err.Error() != "scaling selects no resources" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/kusterr"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestValidateSchemas(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+- crd.yaml
+- crontab.yaml
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: two
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - image: nginx
+        ports:
+        - containerPort: 80
+          protocol: TCP
+        resources:
+          limits:
+            cpu: 1
+            memory: 1Gi
+        colour: blue
+`)
+	th.WriteF("/app/crd.yaml", `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+    plural: crontabs
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - schedule
+            properties:
+              schedule:
+                type: string
+              replicas:
+                type: integer
+              mode:
+                type: string
+                enum:
+                - Fast
+                - Slow
+`)
+	th.WriteF("/app/crontab.yaml", `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+spec:
+  replicas: 1.5
+  mode: Medium
+`)
+	opts := th.MakeDefaultOptions()
+	// Without the option, nothing is checked.
+	th.Run("/app", opts)
+
+	opts.Validate = true
+	err := th.RunWithErr("/app", opts)
+	v, ok := err.(*kusterr.ValidationError)
+	if !ok {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	expected := `6 validation failures:
+  apps_v1_Deployment|~X|web: typeMismatch: spec.replicas: expected integer, got string
+  apps_v1_Deployment|~X|web: unknownField: spec.template.spec.containers[0].colour: unknown field
+  apps_v1_Deployment|~X|web: requiredField: spec.template.spec.containers[0]: missing required field 'name'
+  stable.example.com_v1_CronTab|~X|backup: enum: spec.mode: value 'Medium' isn't one of ['Fast', 'Slow']
+  stable.example.com_v1_CronTab|~X|backup: typeMismatch: spec.replicas: expected integer, got number
+  stable.example.com_v1_CronTab|~X|backup: requiredField: spec: missing required field 'schedule'`
+	if v.Error() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, v.Error())
+	}
+}
+
+func TestValidateSchemasOfCrds(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeBaseWithCrd(th)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+- kind.yaml
+`)
+	th.WriteF("/app/overlay/kind.yaml", `
+apiVersion: jingfang.example.com/v1beta1
+kind: MyKind
+metadata:
+  name: other
+replicas: 2
+spec:
+  beeRef:
+    name: bee
+    namespace: garden
+  secretRef:
+    name: crdsecret
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Validate = true
+	err := th.RunWithErr("/app/overlay", opts)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, s := range []string{
+		"2 validation failures:",
+		"jingfang.example.com_v1beta1_MyKind|~X|other: unknownField: spec.beeRef.namespace: unknown field",
+		"jingfang.example.com_v1beta1_MyKind|~X|other: unknownField: replicas: unknown field",
+	} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("expected %q in error:\n%v", s, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/kusterr"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestMisspelledKustomizationField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+commonLabel:
+  app: web
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	d := kusterr.Details(err)
+	if d.Kustomization != "/app/base/kustomization.yaml" || d.Line != 5 ||
+		d.Err.Error() != `invalid kustomization file /app/base/kustomization.yaml:
+  line 5: commonLabel: unknown field; did you mean 'commonLabels'?` {
+		t.Fatalf("unexpected error: %#v", d)
+	}
+}
+
+func TestStrictKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- cm.yaml
+nameprefix: a-
+`)
+	th.WriteF("/app/cm.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	// Decoding ignores the case of field names.
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a-cm
+`)
+	options := th.MakeDefaultOptions()
+	options.Strict = true
+	err := th.RunWithErr("/app", options)
+	if err == nil || err.Error() != `invalid kustomization file /app/kustomization.yaml:
+  line 7: nameprefix: unknown field; did you mean 'namePrefix'?` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -205,3 +205,48 @@ spec:
         name: whatever
 `)
 }
+
+// Sibling bases are accumulated concurrently, so they load
+// the same plugin at once.  Run with -race.
+func TestSiblingBasesLoadingPlugins(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		BuildGoPlugin("someteam.example.com", "v1", "StringPrefixer").
+		BuildGoPlugin("someteam.example.com", "v1", "DatePrefixer")
+	defer th.Reset()
+
+	for _, name := range []string{"apple", "peach"} {
+		th.WriteK("/app/"+name, `
+resources:
+- deployment.yaml
+transformers:
+- stringPrefixer.yaml
+- datePrefixer.yaml
+`)
+		th.WriteF("/app/"+name+"/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: `+name+`
+`)
+		writeStringPrefixer(th, "/app/"+name+"/stringPrefixer.yaml", name)
+		writeDatePrefixer(th, "/app/"+name+"/datePrefixer.yaml", "date")
+	}
+	th.WriteK("/app/overlay", `
+resources:
+- ../apple
+- ../peach
+`)
+
+	m := th.Run("/app/overlay", th.MakeOptionsPluginsEnabled())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: 2018-05-11-apple-apple
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: 2018-05-11-peach-peach
+`)
+}
@@ -4,6 +4,7 @@
 package krusty_test
 
 import (
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -397,3 +398,110 @@ spec:
             image: solsa-echo:foo
 `)
 }
+
+func writeImageIndexBase(th kusttest_test.Harness) {
+	th.WriteF("/app/images.lock.yaml", `
+images:
+- name: nginx
+  tags:
+  - tag: 1.18.0
+    digest: sha256:118
+  - tag: 1.19.2
+    digest: sha256:1192
+  - tag: 2.0.0
+    digest: sha256:200
+- name: busybox
+  tags:
+  - tag: "1.32"
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.32
+      containers:
+      - name: nginx
+        image: nginx:1.18.0
+      - name: sidecar
+        image: nginx
+`)
+}
+
+func TestTransfomersImageIndex(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeImageIndexBase(th)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+imageIndex:
+  path: images.lock.yaml
+  strict: true
+images:
+- name: nginx
+  tagPolicy: semver:^1.18
+  digestFrom: file
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx@sha256:1192
+        name: nginx
+      - image: nginx@sha256:1192
+        name: sidecar
+      initContainers:
+      - image: busybox:1.32
+        name: init
+`)
+}
+
+func TestTransfomersImageIndexStrict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeImageIndexBase(th)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+imageIndex:
+  path: images.lock.yaml
+  strict: true
+images:
+- name: nginx
+  newTag: 2.0.0
+- name: busybox
+  newTag: "1.33"
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"image busybox:1.33 of Deployment web isn't in the image index") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTransfomersImageWithoutIndex(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeImageIndexBase(th)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+images:
+- name: nginx
+  digestFrom: file
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"image nginx has a tagPolicy or digestFrom, but there's no image index") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/kusterr"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeValidatedApp(th kusttest_test.Harness, validations string) {
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+`+validations)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    team: shop
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      initContainers:
+      - name: migrate
+        image: gcr.io/shop/migrate:1
+      containers:
+      - name: app
+        image: gcr.io/shop/app:1
+        resources:
+          limits:
+            cpu: "1"
+            memory: 1Gi
+      - name: proxy
+        image: nginx
+        resources:
+          limits:
+            cpu: 100m
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    owner: shop
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 30080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: admin
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 30080
+`)
+}
+
+func TestValidations(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidatedApp(th, `
+validations:
+- requiredLabel: team
+  target:
+    kind: Deployment
+- name: owned
+  requiredAnnotation: owner
+  target:
+    kind: Service
+- forbiddenValue:
+    path: spec/template/spec/hostNetwork
+    values: ["true"]
+- allowedRegistries:
+  - gcr.io/shop
+- requiredLimits: [cpu, memory]
+- uniqueField: spec/ports/nodePort
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	v, ok := err.(*kusterr.ValidationError)
+	if !ok {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if v.Kustomization != "/app/kustomization.yaml" {
+		t.Fatalf("unexpected kustomization %s", v.Kustomization)
+	}
+	expected := `7 validation failures:
+  ~G_v1_Service|~X|admin: owned: missing annotation 'owner'
+  apps_v1_Deployment|~X|web: forbiddenValue: forbidden value 'true' at spec/template/spec/hostNetwork
+  apps_v1_Deployment|~X|web: allowedRegistries: image 'nginx' of container 'proxy' isn't from an allowed registry
+  apps_v1_Deployment|~X|web: requiredLimits: container 'migrate' has no cpu limit
+  apps_v1_Deployment|~X|web: requiredLimits: container 'migrate' has no memory limit
+  apps_v1_Deployment|~X|web: requiredLimits: container 'proxy' has no memory limit
+  ~G_v1_Service|~X|admin: uniqueField: value '30080' at spec/ports/nodePort is also that of ~G_v1_Service|~X|web`
+	if err.Error() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, err)
+	}
+}
+
+func TestValidationsWarnOnly(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidatedApp(th, `
+validations:
+- requiredLabel: team
+`)
+	var warnings []*kusterr.ValidationError
+	opts := th.MakeDefaultOptions()
+	opts.ValidationWarnOnly = true
+	opts.ValidationWarnings = func(e *kusterr.ValidationError) {
+		warnings = append(warnings, e)
+	}
+	m := th.Run("/app", opts)
+	if m.Size() != 3 {
+		t.Fatalf("expected 3 resources, got %d", m.Size())
+	}
+	if len(warnings) != 1 || len(warnings[0].Failures) != 2 {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+}
+
+func TestValidationsInvalidRule(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidatedApp(th, `
+validations:
+- requiredLabel: team
+  requiredAnnotation: owner
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"validation 0: expected exactly one rule, got 2 [requiredLabel requiredAnnotation]") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidationsOfBase(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+validations:
+- requiredLabel: team
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	// The overlay adds the label, but the base's rule is
+	// checked against the resources as the base leaves them.
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+commonLabels:
+  team: shop
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	v, ok := err.(*kusterr.ValidationError)
+	if !ok {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if v.Kustomization != "/app/overlay/kustomization.yaml" ||
+		len(v.Failures) != 1 ||
+		v.Failures[0].Kustomization != "/app/base/kustomization.yaml" {
+		t.Fatalf("unexpected error %#v", v)
+	}
+	expected := `1 validation failures:
+  apps_v1_Deployment|~X|web: requiredLabel of /app/base/kustomization.yaml: missing label 'team'`
+	if err.Error() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, err)
+	}
+}
+
+func TestValidationsOfComponent(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/comp/kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+validations:
+- requiredAnnotation: owner
+`)
+	th.WriteK("/app/prod", `
+resources:
+- deployment.yaml
+components:
+- ../comp
+`)
+	th.WriteF("/app/prod/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	err := th.RunWithErr("/app/prod", th.MakeDefaultOptions())
+	expected := `1 validation failures:
+  apps_v1_Deployment|~X|web: requiredAnnotation of /app/comp/kustomization.yaml: missing annotation 'owner'`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%v", expected, err)
+	}
+	th.WriteF("/app/prod/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    owner: shop
+`)
+	th.Run("/app/prod", th.MakeDefaultOptions())
+}
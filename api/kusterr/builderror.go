@@ -0,0 +1,230 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/yaml"
+)
+
+// BuildError is an error annotated with where in a build
+// it happened.  As an error propagates out of a build, each
+// layer that knows something about its location wraps it
+// in a BuildError holding just that; Details gathers them.
+//
+// A BuildError's message is that of the error it wraps, so
+// annotating an error doesn't change how it reads.
+type BuildError struct {
+	// Path of the kustomization file being built.
+	Kustomization string
+	// Path of the file holding the error, if not the
+	// kustomization itself.  It may be relative to the
+	// directory of the kustomization.
+	File string
+	// Position of the error in File, counting from 1;
+	// zero if unknown.
+	Line   int
+	Column int
+	// The resource at fault.
+	ResId *resid.ResId
+	// The generator or transformer that failed.
+	Transformer string
+	Err         error
+}
+
+func (e *BuildError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the annotated error.
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the annotated error, for errors.Cause.
+func (e *BuildError) Cause() error {
+	return e.Err
+}
+
+// MarshalJSON emits the annotations, and the message
+// of the error.
+func (e *BuildError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kustomization string       `json:"kustomization,omitempty"`
+		File          string       `json:"file,omitempty"`
+		Line          int          `json:"line,omitempty"`
+		Column        int          `json:"column,omitempty"`
+		ResId         *resid.ResId `json:"resId,omitempty"`
+		Transformer   string       `json:"transformer,omitempty"`
+		Message       string       `json:"message"`
+	}{
+		Kustomization: e.Kustomization,
+		File:          e.File,
+		Line:          e.Line,
+		Column:        e.Column,
+		ResId:         e.ResId,
+		Transformer:   e.Transformer,
+		Message:       e.Error(),
+	})
+}
+
+// WithResId annotates the error with the resource at fault.
+func WithResId(err error, id resid.ResId) error {
+	return &BuildError{ResId: &id, Err: err}
+}
+
+// DecodeError annotates an error decoding the content of
+// the file at path, making YAML syntax errors YamlFormatErrors
+// (see Handler) and locating them in the file.
+func DecodeError(err error, path string, content []byte) error {
+	e := &BuildError{File: path, Err: Handler(err, path)}
+	if _, ok := e.Err.(YamlFormatError); ok {
+		e.Line, e.Column = locateSyntaxError(content)
+	}
+	return e
+}
+
+var documentSeparator = regexp.MustCompile(`(?m)^---.*$`)
+
+// locateSyntaxError returns the position of the first
+// syntax error in content, which may hold several YAML
+// documents.  YAML parsers count the lines of a document
+// from its start, so the line is found by parsing the
+// documents one at a time.
+func locateSyntaxError(content []byte) (line, column int) {
+	offset := 0
+	start := 0
+	bounds := documentSeparator.FindAllIndex(content, -1)
+	bounds = append(bounds, []int{len(content), len(content)})
+	for _, b := range bounds {
+		doc := content[start:b[0]]
+		if _, err := yaml.YAMLToJSON(doc); err != nil {
+			line, column = positionIn(err.Error())
+			if line > 0 {
+				line += offset
+			}
+			return line, column
+		}
+		offset += bytes.Count(content[start:b[1]], []byte("\n"))
+		start = b[1]
+	}
+	return 0, 0
+}
+
+var position = regexp.MustCompile(`yaml: line (\d+)(?:, column (\d+))?`)
+
+// positionIn returns the position named in an error message
+// from a YAML parser, e.g. "yaml: line 3: mapping values are
+// not allowed in this context".
+func positionIn(msg string) (line, column int) {
+	m := position.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, 0
+	}
+	line, _ = strconv.Atoi(m[1])
+	column, _ = strconv.Atoi(m[2])
+	return line, column
+}
+
+// multiError is implemented by errors that have
+// several causes, any of which may explain them.
+type multiError interface {
+	Unwrap() []error
+}
+
+// Details returns what's known of where in a build the
+// error happened, gathered from the BuildErrors it wraps.
+// The innermost annotations win, as they're the most
+// precise.  An error with several causes is explained by
+// the one that's best annotated.
+//
+// The returned BuildError wraps the innermost annotated
+// error, or err itself if it has no annotations.  A relative
+// File is resolved against the directory of Kustomization.
+func Details(err error) *BuildError {
+	d := &BuildError{Err: err}
+	gather(d, err)
+	if d.File != "" && !filepath.IsAbs(d.File) && d.Kustomization != "" {
+		d.File = filepath.Join(filepath.Dir(d.Kustomization), d.File)
+	}
+	return d
+}
+
+// gather merges into d the annotations found in err
+// and its causes, returning a score of how much it found.
+func gather(d *BuildError, err error) int {
+	score := 0
+	for err != nil {
+		switch e := err.(type) {
+		case *BuildError:
+			merge(d, e)
+			score++
+		case YamlFormatError:
+			if d.File == "" {
+				d.File = e.Path
+			}
+			if d.Line == 0 {
+				d.Line, d.Column = positionIn(e.ErrorMsg)
+			}
+			score += 10
+		case multiError:
+			return score + gatherBest(d, e.Unwrap())
+		}
+		next := errors.Unwrap(err)
+		if next == nil {
+			if c, ok := err.(interface{ Cause() error }); ok {
+				next = c.Cause()
+			}
+		}
+		if next == nil && d.Line == 0 {
+			d.Line, d.Column = positionIn(err.Error())
+		}
+		err = next
+	}
+	return score
+}
+
+// gatherBest merges into d the annotations of the best
+// annotated of the errors.
+func gatherBest(d *BuildError, errs []error) int {
+	var best *BuildError
+	bestScore := -1
+	for _, err := range errs {
+		c := *d
+		if s := gather(&c, err); s > bestScore {
+			best, bestScore = &c, s
+		}
+	}
+	if best != nil {
+		*d = *best
+	}
+	return bestScore
+}
+
+func merge(d, e *BuildError) {
+	if e.Kustomization != "" && e.Kustomization != d.Kustomization {
+		// The error happened in another kustomization,
+		// so what was known of its place in the outer
+		// one doesn't apply.
+		d.Kustomization = e.Kustomization
+		d.File, d.Line, d.Column = "", 0, 0
+	}
+	if e.File != "" || e.Line != 0 {
+		d.File, d.Line, d.Column = e.File, e.Line, e.Column
+	}
+	if e.ResId != nil {
+		d.ResId = e.ResId
+	}
+	if e.Transformer != "" {
+		d.Transformer = e.Transformer
+	}
+	d.Err = e.Err
+}
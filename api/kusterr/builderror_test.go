@@ -0,0 +1,133 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+type causes []error
+
+func (c causes) Error() string   { return "causes" }
+func (c causes) Unwrap() []error { return c }
+
+func TestDecodeError(t *testing.T) {
+	content := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  labels: {
+`)
+	err := DecodeError(
+		fmt.Errorf("error converting YAML to JSON: yaml: line 6: did not find expected node content"),
+		"cm.yaml", content)
+	d := Details(err)
+	if d.File != "cm.yaml" || d.Line != 10 {
+		t.Fatalf("unexpected position %s:%d", d.File, d.Line)
+	}
+	if _, ok := d.Err.(YamlFormatError); !ok {
+		t.Fatalf("expected a YamlFormatError, got %T", d.Err)
+	}
+
+	err = DecodeError(fmt.Errorf("missing metadata.name"), "cm.yaml", content)
+	d = Details(err)
+	if d.File != "cm.yaml" || d.Line != 0 || d.Err.Error() != "missing metadata.name" {
+		t.Fatalf("unexpected details %#v", d)
+	}
+}
+
+func TestDetails(t *testing.T) {
+	id := resid.NewResId(resid.Gvk{Version: "v1", Kind: "ConfigMap"}, "cm")
+	cause := fmt.Errorf("no matches")
+	testCases := map[string]struct {
+		err      error
+		expected BuildError
+	}{
+		"unannotated": {
+			err:      cause,
+			expected: BuildError{Err: cause},
+		},
+		"innermost wins": {
+			err: errors.Wrap(&BuildError{
+				Kustomization: "/app/kustomization.yaml",
+				Transformer:   "PatchTransformer",
+				Err: errors.Wrap(&BuildError{
+					Kustomization: "/app/base/kustomization.yaml",
+					Err:           WithResId(cause, id),
+				}, "accumulating resources"),
+			}, "accumulating"),
+			expected: BuildError{
+				Kustomization: "/app/base/kustomization.yaml",
+				Transformer:   "PatchTransformer",
+				ResId:         &id,
+				Err:           cause,
+			},
+		},
+		"best cause": {
+			err: &BuildError{
+				Kustomization: "/app/kustomization.yaml",
+				Err: causes{
+					fmt.Errorf("not a directory"),
+					&BuildError{File: "cm.yaml", Line: 3, Err: cause},
+				},
+			},
+			expected: BuildError{
+				Kustomization: "/app/kustomization.yaml",
+				File:          "/app/cm.yaml",
+				Line:          3,
+				Err:           cause,
+			},
+		},
+		"line in message": {
+			err: &BuildError{
+				Kustomization: "/app/kustomization.yaml",
+				Err:           fmt.Errorf("error converting YAML to JSON: yaml: line 4, column 2: oops"),
+			},
+			expected: BuildError{
+				Kustomization: "/app/kustomization.yaml",
+				Line:          4,
+				Column:        2,
+				Err:           fmt.Errorf("error converting YAML to JSON: yaml: line 4, column 2: oops"),
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if d := Details(tc.err); !reflect.DeepEqual(*d, tc.expected) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, *d)
+			}
+		})
+	}
+}
+
+func TestBuildErrorMarshalJSON(t *testing.T) {
+	id := resid.NewResId(resid.Gvk{Version: "v1", Kind: "ConfigMap"}, "cm")
+	err := &BuildError{
+		Kustomization: "/app/kustomization.yaml",
+		File:          "/app/cm.yaml",
+		Line:          2,
+		ResId:         &id,
+		Err:           fmt.Errorf("oops"),
+	}
+	if err.Error() != "oops" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	b, _ := json.Marshal(err)
+	expected := `{"kustomization":"/app/kustomization.yaml","file":"/app/cm.yaml",` +
+		`"line":2,"resId":{"version":"v1","kind":"ConfigMap","name":"cm"},"message":"oops"}`
+	if string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+}
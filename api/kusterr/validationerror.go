@@ -0,0 +1,58 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+// ValidationFailure is a resource of the build output
+// breaking a rule of the validations of a kustomization.
+type ValidationFailure struct {
+	// Name of the rule.
+	Rule string `json:"rule"`
+	// The resource at fault.
+	ResId resid.ResId `json:"resId"`
+	// Path of the field at fault, if any, e.g.
+	// spec.template.spec.containers[0].image.
+	Path string `json:"path,omitempty"`
+	// What's wrong with it.
+	Message string `json:"message"`
+	// Path of the kustomization file holding the rule,
+	// if that's a base or component of the one built.
+	// Their rules are checked against their own output.
+	Kustomization string `json:"kustomization,omitempty"`
+}
+
+func (f ValidationFailure) String() string {
+	rule := f.Rule
+	if f.Kustomization != "" {
+		rule += " of " + f.Kustomization
+	}
+	if f.Path != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", f.ResId, rule, f.Path, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.ResId, rule, f.Message)
+}
+
+// ValidationError is the error of a build whose output
+// breaks rules of the validations of its kustomization,
+// or of those of its bases and components.
+type ValidationError struct {
+	// Path of the kustomization file that was built.
+	Kustomization string
+	Failures      []ValidationFailure
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		lines[i] = f.String()
+	}
+	return fmt.Sprintf("%d validation failures:\n  %s",
+		len(e.Failures), strings.Join(lines, "\n  "))
+}
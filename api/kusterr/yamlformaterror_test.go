@@ -9,14 +9,14 @@ import (
 )
 
 const (
-	filepath = "/path/to/whatever"
+	yamlPath = "/path/to/whatever"
 	expected = "YAML file [/path/to/whatever] encounters a format error.\n" +
 		"error converting YAML to JSON: yaml: line 2: found character that cannot start any token\n"
 )
 
 func TestYamlFormatError_Error(t *testing.T) {
 	testErr := YamlFormatError{
-		Path:     filepath,
+		Path:     yamlPath,
 		ErrorMsg: "error converting YAML to JSON: yaml: line 2: found character that cannot start any token",
 	}
 	if testErr.Error() != expected {
@@ -26,9 +26,9 @@ func TestYamlFormatError_Error(t *testing.T) {
 
 func TestErrorHandler(t *testing.T) {
 	err := fmt.Errorf("error converting YAML to JSON: yaml: line 2: found character that cannot start any token")
-	testErr := Handler(err, filepath)
+	testErr := Handler(err, yamlPath)
 	expectedErr := fmt.Errorf("format error message")
-	fmtErr := Handler(expectedErr, filepath)
+	fmtErr := Handler(expectedErr, yamlPath)
 	if fmtErr.Error() != expectedErr.Error() {
 		t.Errorf("Expected returning fmt.Error, but found %T", fmtErr)
 	}
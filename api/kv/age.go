@@ -0,0 +1,105 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AgeKeyEnv names the environment variable holding
+	// age secret keys, one per line.
+	AgeKeyEnv = "KUSTOMIZE_AGE_KEY"
+
+	// AgeKeyFileEnv names the environment variable holding
+	// the path of a file of age secret keys, as written
+	// by age-keygen.
+	AgeKeyFileEnv = "KUSTOMIZE_AGE_KEY_FILE"
+)
+
+const ageArmorBegin = "-----BEGIN AGE ENCRYPTED FILE-----"
+
+// AgeKeyring is a Decryptor of files encrypted with age
+// (https://age-encryption.org/v1) to X25519 recipients.
+// It holds the secret keys of the recipients, and no
+// others: there's no PGP, and no agent to ask.
+type AgeKeyring struct {
+	identities []age.Identity
+}
+
+// ParseAgeKeyring returns the keyring of the age secret
+// keys ("AGE-SECRET-KEY-1...") in the content, one per
+// line.  Blank lines and comments are skipped.
+func ParseAgeKeyring(content []byte) (*AgeKeyring, error) {
+	k := &AgeKeyring{}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := age.ParseX25519Identity(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d is not an age secret key", i+1)
+		}
+		k.identities = append(k.identities, id)
+	}
+	if len(k.identities) == 0 {
+		return nil, errors.New("no age secret keys found")
+	}
+	return k, nil
+}
+
+// AgeKeyringFromEnv returns the keyring of the keys in
+// the AgeKeyEnv variable and in the file that the
+// AgeKeyFileEnv variable names.
+func AgeKeyringFromEnv() (*AgeKeyring, error) {
+	var content []byte
+	if keys := os.Getenv(AgeKeyEnv); keys != "" {
+		content = append(content, keys...)
+		content = append(content, '\n')
+	}
+	if path := os.Getenv(AgeKeyFileEnv); path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", AgeKeyFileEnv)
+		}
+		content = append(content, b...)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf(
+			"no age key to decrypt with; set %s or %s",
+			AgeKeyEnv, AgeKeyFileEnv)
+	}
+	return ParseAgeKeyring(content)
+}
+
+// Decrypt returns the plaintext of a binary or armored
+// age file encrypted to a key in the keyring.
+func (k *AgeKeyring) Decrypt(content []byte) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(content)
+	if trimmed := bytes.TrimSpace(content); bytes.HasPrefix(trimmed, []byte(ageArmorBegin)) {
+		src = armor.NewReader(bytes.NewReader(trimmed))
+	}
+	r, err := age.Decrypt(src, k.identities...)
+	if err != nil {
+		if _, ok := err.(*age.NoIdentityMatchError); ok {
+			return nil, errors.New("no key in the age keyring can decrypt it")
+		}
+		return nil, errors.Wrap(err, "malformed age file")
+	}
+	plaintext, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "age payload is corrupt")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,305 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// ageChunkSize is the size of the plaintext of each
+// chunk of an age payload.
+const ageChunkSize = 64 * 1024
+
+// makeAgeKey returns a new age secret key, encoded, and
+// its recipient.
+func makeAgeKey(t *testing.T) (string, *age.X25519Recipient) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id.String(), id.Recipient()
+}
+
+// ageEncrypt encrypts the plaintext to the recipient.
+func ageEncrypt(t *testing.T, r *age.X25519Recipient, plaintext []byte, armored bool) []byte {
+	var out bytes.Buffer
+	var dst io.WriteCloser = nopCloser{&out}
+	if armored {
+		dst = armor.NewWriter(&out)
+	}
+	w, err := age.Encrypt(dst, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = dst.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestAgeKeyringDecrypt(t *testing.T) {
+	secret, public := makeAgeKey(t)
+	other, _ := makeAgeKey(t)
+	k, err := ParseAgeKeyring([]byte(
+		"# created: 2020-06-01T00:00:00Z\n" + other + "\n\n" + secret + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, plaintext := range map[string][]byte{
+		"empty":        {},
+		"short":        []byte("password=hunter2\n"),
+		"one chunk":    bytes.Repeat([]byte("a"), ageChunkSize),
+		"three chunks": bytes.Repeat([]byte("b"), 2*ageChunkSize+1),
+	} {
+		for _, armor := range []bool{false, true} {
+			actual, err := k.Decrypt(ageEncrypt(t, public, plaintext, armor))
+			if err != nil {
+				t.Fatalf("%s, armored %v: unexpected error: %v", name, armor, err)
+			}
+			if !bytes.Equal(actual, plaintext) {
+				t.Fatalf("%s, armored %v: decrypted to %d bytes, not %d",
+					name, armor, len(actual), len(plaintext))
+			}
+		}
+	}
+}
+
+// The files in testdata/age were made by the age CLI
+// (v1.2.1), e.g.
+//
+//	age-keygen -o key1.txt
+//	age -a -r <key1 recipient> -o armored.age
+//
+// multichunk.age holds the lines "line 00000" to
+// "line 11999", and boundary.age exactly one chunk of "a".
+func TestAgeKeyringDecryptAgeFiles(t *testing.T) {
+	readFile := func(name string) []byte {
+		b, err := ioutil.ReadFile("testdata/age/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	var lines strings.Builder
+	for i := 0; i < 12000; i++ {
+		fmt.Fprintf(&lines, "line %05d\n", i)
+	}
+	short := []byte("password=hunter2\n")
+	for _, test := range []struct {
+		file, key string
+		expected  []byte
+	}{
+		{"binary.age", "key1.txt", short},
+		{"armored.age", "key1.txt", short},
+		{"recipients.age", "key1.txt", short},
+		{"recipients.age", "key2.txt", short},
+		{"multichunk.age", "key1.txt", []byte(lines.String())},
+		{"boundary.age", "key1.txt", bytes.Repeat([]byte("a"), ageChunkSize)},
+	} {
+		k, err := ParseAgeKeyring(readFile(test.key))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.key, err)
+		}
+		actual, err := k.Decrypt(readFile(test.file))
+		if err != nil {
+			t.Fatalf("%s with %s: unexpected error: %v", test.file, test.key, err)
+		}
+		if !bytes.Equal(actual, test.expected) {
+			t.Fatalf("%s with %s: decrypted to %d bytes, not %d",
+				test.file, test.key, len(actual), len(test.expected))
+		}
+	}
+	// Only the recipients hold keys to the file.
+	k, err := ParseAgeKeyring(readFile("key2.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = k.Decrypt(readFile("binary.age"))
+	if err == nil || err.Error() != "no key in the age keyring can decrypt it" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAgeKeyringDecryptErrors(t *testing.T) {
+	secret, public := makeAgeKey(t)
+	_, stranger := makeAgeKey(t)
+	k, err := ParseAgeKeyring([]byte(secret))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Three chunks: two full ones, and one of a byte.
+	encrypted := ageEncrypt(t, public, bytes.Repeat([]byte("a"), 2*ageChunkSize+1), false)
+	macLine := bytes.Index(encrypted, []byte("\n---")) + 1
+	payload := macLine + bytes.IndexByte(encrypted[macLine:], '\n') + 1
+	chunk := func(i int) int {
+		// after the nonce, each chunk is sealed with a tag
+		return payload + 16 + i*(ageChunkSize+16)
+	}
+	changed := func(f func(b []byte) []byte) string {
+		return string(f(append([]byte{}, encrypted...)))
+	}
+	flip := func(i int) string {
+		return changed(func(b []byte) []byte { b[i] ^= 1; return b })
+	}
+	header := string(encrypted[:payload])
+	for name, test := range map[string]struct {
+		content, expected string
+	}{
+		"not age": {"secret", "malformed age file"},
+		"truncated header": {
+			string(encrypted[:macLine/2]), "malformed age file"},
+		"truncated nonce": {
+			string(encrypted[:payload+8]), "malformed age file"},
+		"truncated chunk": {
+			string(encrypted[:chunk(1)+100]), "age payload is corrupt"},
+		"missing last chunk": {
+			string(encrypted[:chunk(2)]), "age payload is corrupt"},
+		"missing chunks": {
+			string(encrypted[:chunk(0)]), "age payload is corrupt"},
+		"tampered first chunk": {
+			flip(chunk(0) + 10), "age payload is corrupt"},
+		"tampered last chunk": {
+			flip(len(encrypted) - 1), "age payload is corrupt"},
+		"tampered nonce": {
+			flip(payload), "age payload is corrupt"},
+		"tampered header": {
+			flip(macLine - 2), "malformed age file"},
+		"tampered mac": {
+			flip(macLine + 5), "malformed age file"},
+		"wrong identity": {
+			string(ageEncrypt(t, stranger, []byte("secret"), false)),
+			"no key in the age keyring can decrypt it"},
+		"wrong version": {
+			strings.Replace(header, "age-encryption.org/v1", "age-encryption.org/v2", 1),
+			"malformed age file"},
+		"stanza without arguments": {
+			"age-encryption.org/v1\n-> \n\n--- AAAA\n", "malformed age file"},
+		"stanza body not base64": {
+			"age-encryption.org/v1\n-> X25519 a\n!!!\n--- AAAA\n", "malformed age file"},
+		"no mac line": {
+			"age-encryption.org/v1\n-> X25519 a\n", "malformed age file"},
+		"truncated armor": {
+			"-----BEGIN AGE ENCRYPTED FILE-----\nYWdl\n", "malformed age file"},
+	} {
+		_, err := k.Decrypt([]byte(test.content))
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Errorf("%s: expected error %q, got %v", name, test.expected, err)
+		}
+	}
+}
+
+func TestParseAgeKeyringErrors(t *testing.T) {
+	secret, _ := makeAgeKey(t)
+	// A typo breaks the checksum.
+	typo := secret[:len(secret)-1] + "Q"
+	if strings.HasSuffix(secret, "Q") {
+		typo = secret[:len(secret)-1] + "P"
+	}
+	for content, expected := range map[string]string{
+		"":                     "no age secret keys found",
+		"# nothing\n":          "no age secret keys found",
+		secret + "\nage1abc\n": "line 2 is not an age secret key",
+		typo:                   "line 1 is not an age secret key",
+	} {
+		_, err := ParseAgeKeyring([]byte(content))
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestAgeKeyringFromEnv(t *testing.T) {
+	defer os.Unsetenv(AgeKeyEnv)
+	defer os.Unsetenv(AgeKeyFileEnv)
+	os.Unsetenv(AgeKeyEnv)
+	os.Unsetenv(AgeKeyFileEnv)
+	_, err := AgeKeyringFromEnv()
+	if err == nil || err.Error() !=
+		"no age key to decrypt with; set KUSTOMIZE_AGE_KEY or KUSTOMIZE_AGE_KEY_FILE" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	secret, public := makeAgeKey(t)
+	f, err := ioutil.TempFile("", "keys.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(secret + "\n")
+	f.Close()
+	os.Setenv(AgeKeyFileEnv, f.Name())
+	k, err := AgeKeyringFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(k.identities) != 1 ||
+		k.identities[0].(*age.X25519Identity).Recipient().String() != public.String() {
+		t.Fatalf("unexpected keyring")
+	}
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	secret, public := makeAgeKey(t)
+	k, err := ParseAgeKeyring([]byte(secret))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/tls.key.age", ageEncrypt(t, public, []byte("KEY"), true))
+	fSys.WriteFile("/app/db.env", ageEncrypt(t, public, []byte("USER=admin\nPASSWORD=x=y\n"), false))
+	kvl := makeKvLoader(fSys)
+	kvl.decryptor = k
+	pairs, err := kvl.LoadEncrypted(types.EncryptedKvPairSources{
+		EncryptedFileSources: []string{"/app/tls.key.age", "key=/app/tls.key.age"},
+		EncryptedEnvSources:  []string{"/app/db.env"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []types.Pair{
+		{Key: "USER", Value: "admin"},
+		{Key: "PASSWORD", Value: "x=y"},
+		{Key: "tls.key", Value: "KEY"},
+		{Key: "key", Value: "KEY"},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("expected %v, got %v", expected, pairs)
+	}
+
+	kvl.decryptor = ageKeyringFromEnv{}
+	os.Unsetenv(AgeKeyEnv)
+	os.Unsetenv(AgeKeyFileEnv)
+	_, err = kvl.LoadEncrypted(types.EncryptedKvPairSources{
+		EncryptedEnvSources: []string{"/app/db.env"},
+	})
+	if err == nil || err.Error() != "encrypted env source files: [/app/db.env]: "+
+		"decrypting /app/db.env: no age key to decrypt with; "+
+		"set KUSTOMIZE_AGE_KEY or KUSTOMIZE_AGE_KEY_FILE" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -27,10 +27,40 @@ type loader struct {
 
 	// Used to validate various k8s data fields.
 	validator ifc.Validator
+
+	// Used to decrypt encrypted sources.
+	decryptor Decryptor
+}
+
+// Decryptor decrypts the content of encrypted sources.
+// The plaintext must stay in memory; it's never written
+// to disk.
+type Decryptor interface {
+	Decrypt(content []byte) ([]byte, error)
 }
 
+// NewLoader returns a loader decrypting encrypted sources
+// with the age keyring that AgeKeyringFromEnv returns.
 func NewLoader(ldr ifc.Loader, v ifc.Validator) ifc.KvLoader {
-	return &loader{ldr: ldr, validator: v}
+	return NewLoaderWithDecryptor(ldr, v, ageKeyringFromEnv{})
+}
+
+func NewLoaderWithDecryptor(
+	ldr ifc.Loader, v ifc.Validator, d Decryptor) ifc.KvLoader {
+	return &loader{ldr: ldr, validator: v, decryptor: d}
+}
+
+// ageKeyringFromEnv decrypts with the keyring that
+// AgeKeyringFromEnv returns, read only when there's
+// something to decrypt.
+type ageKeyringFromEnv struct{}
+
+func (ageKeyringFromEnv) Decrypt(content []byte) ([]byte, error) {
+	k, err := AgeKeyringFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return k.Decrypt(content)
 }
 
 func (kvl *loader) Validator() ifc.Validator {
@@ -59,6 +89,45 @@ func (kvl *loader) Load(
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"file sources: %v", args.FileSources))
 	}
+	all = append(all, pairs...)
+
+	sep := args.KeySeparator
+	if sep == "" {
+		sep = defaultKeySeparator
+	}
+	pairs, err = kvl.keyValuesFromStructuredSources(args.StructuredSources, sep)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf(
+			"structured sources: %v", args.StructuredSources))
+	}
+	all = append(all, pairs...)
+
+	for _, d := range args.DirSources {
+		pairs, err = kvl.keyValuesFromDirSource(d, sep)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf(
+				"dir source %s", d.Path))
+		}
+		all = append(all, pairs...)
+	}
+	return all, nil
+}
+
+func (kvl *loader) LoadEncrypted(
+	args types.EncryptedKvPairSources) (all []types.Pair, err error) {
+	pairs, err := kvl.keyValuesFromEncryptedEnvFiles(args.EncryptedEnvSources)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf(
+			"encrypted env source files: %v",
+			args.EncryptedEnvSources))
+	}
+	all = append(all, pairs...)
+
+	pairs, err = kvl.keyValuesFromEncryptedFileSources(args.EncryptedFileSources)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf(
+			"encrypted file sources: %v", args.EncryptedFileSources))
+	}
 	return append(all, pairs...), nil
 }
 
@@ -106,6 +175,60 @@ func (kvl *loader) keyValuesFromEnvFiles(paths []string) ([]types.Pair, error) {
 	return kvs, nil
 }
 
+// keyValuesFromEncryptedFileSources is keyValuesFromFileSources
+// for encrypted files.  A key defaulting to the basename
+// of a file drops its ".age" extension.
+func (kvl *loader) keyValuesFromEncryptedFileSources(
+	sources []string) ([]types.Pair, error) {
+	var kvs []types.Pair
+	for _, s := range sources {
+		k, fPath, err := parseFileSource(s)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(s, "=") {
+			k = strings.TrimSuffix(k, ".age")
+		}
+		content, err := kvl.loadDecrypted(fPath)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, types.Pair{Key: k, Value: string(content)})
+	}
+	return kvs, nil
+}
+
+func (kvl *loader) keyValuesFromEncryptedEnvFiles(
+	paths []string) ([]types.Pair, error) {
+	var kvs []types.Pair
+	for _, p := range paths {
+		content, err := kvl.loadDecrypted(p)
+		if err != nil {
+			return nil, err
+		}
+		more, err := kvl.keyValuesFromLines(content)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, more...)
+	}
+	return kvs, nil
+}
+
+// loadDecrypted returns the plaintext of the encrypted
+// file at the path.
+func (kvl *loader) loadDecrypted(path string) ([]byte, error) {
+	content, err := kvl.ldr.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := kvl.decryptor.Decrypt(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting %s", path)
+	}
+	return plaintext, nil
+}
+
 // keyValuesFromLines parses given content in to a list of key-value pairs.
 func (kvl *loader) keyValuesFromLines(content []byte) ([]types.Pair, error) {
 	var kvs []types.Pair
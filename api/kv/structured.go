@@ -0,0 +1,162 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultKeySeparator = "."
+
+// keyValuesFromStructuredSources returns a pair for each
+// leaf of the JSON or YAML files.
+func (kvl *loader) keyValuesFromStructuredSources(
+	sources []string, sep string) ([]types.Pair, error) {
+	var kvs []types.Pair
+	for _, s := range sources {
+		prefix, fPath := "", s
+		if strings.Contains(s, "=") {
+			var err error
+			prefix, fPath, err = parseFileSource(s)
+			if err != nil {
+				return nil, err
+			}
+		}
+		content, err := kvl.ldr.Load(fPath)
+		if err != nil {
+			return nil, err
+		}
+		j, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		d := json.NewDecoder(bytes.NewReader(j))
+		// Numbers are kept as written.
+		d.UseNumber()
+		if err = d.Decode(&v); err != nil {
+			return nil, err
+		}
+		more, err := flatten(prefix, sep, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "structured source %s", fPath)
+		}
+		kvs = append(kvs, more...)
+	}
+	return kvs, nil
+}
+
+// flatten returns a pair for each leaf of v, keyed by its
+// path from v, after the key of v.
+func flatten(key, sep string, v interface{}) ([]types.Pair, error) {
+	join := func(k string) string {
+		if key == "" {
+			return k
+		}
+		return key + sep + k
+	}
+	var kvs []types.Pair
+	switch v := v.(type) {
+	case map[string]interface{}:
+		var fields []string
+		for k := range v {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+		for _, k := range fields {
+			more, err := flatten(join(k), sep, v[k])
+			if err != nil {
+				return nil, err
+			}
+			kvs = append(kvs, more...)
+		}
+		return kvs, nil
+	case []interface{}:
+		for i, item := range v {
+			more, err := flatten(join(strconv.Itoa(i)), sep, item)
+			if err != nil {
+				return nil, err
+			}
+			kvs = append(kvs, more...)
+		}
+		return kvs, nil
+	}
+	if key == "" {
+		return nil, fmt.Errorf("content must be an object or a list")
+	}
+	switch v := v.(type) {
+	case nil:
+		return []types.Pair{{Key: key}}, nil
+	case string:
+		return []types.Pair{{Key: key, Value: v}}, nil
+	default:
+		return []types.Pair{{Key: key, Value: fmt.Sprint(v)}}, nil
+	}
+}
+
+// keyValuesFromDirSource returns a pair for each file of
+// the directory tree that the source includes.
+func (kvl *loader) keyValuesFromDirSource(
+	d types.DirSource, sep string) ([]types.Pair, error) {
+	for _, p := range append(append([]string{}, d.Include...), d.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q", p)
+		}
+	}
+	lister, ok := kvl.ldr.(ifc.FileLister)
+	if !ok {
+		return nil, fmt.Errorf(
+			"the loader %T can't list the files of %s", kvl.ldr, d.Path)
+	}
+	files, err := lister.ListFiles(d.Path)
+	if err != nil {
+		return nil, err
+	}
+	var kvs []types.Pair
+	for _, f := range files {
+		if (len(d.Include) > 0 && !matchesAny(d.Include, f)) ||
+			matchesAny(d.Exclude, f) {
+			continue
+		}
+		content, err := kvl.ldr.Load(path.Join(d.Path, f))
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, types.Pair{
+			Key:   strings.ReplaceAll(f, "/", sep),
+			Value: string(content),
+		})
+	}
+	return kvs, nil
+}
+
+// matchesAny returns true if the file path matches any of
+// the patterns of a DirSource.
+func matchesAny(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "/**") &&
+			strings.HasPrefix(file, strings.TrimSuffix(p, "**")) {
+			return true
+		}
+		target := file
+		if !strings.Contains(p, "/") {
+			target = path.Base(file)
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestKeyValuesFromStructuredSources(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/settings.yaml", []byte(`
+db:
+  host: db.example.com
+  port: 5432
+  replicas: [a, b]
+debug: true
+ratio: 0.5
+empty: null
+`))
+	fSys.WriteFile("/settings.json", []byte(`{"db": {"host": "db.local"}}`))
+	fSys.WriteFile("/scalar.yaml", []byte("hello\n"))
+	kvl := makeKvLoader(fSys)
+
+	pairs, err := kvl.keyValuesFromStructuredSources(
+		[]string{"/settings.yaml", "app=/settings.json"}, ".")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []types.Pair{
+		{Key: "db.host", Value: "db.example.com"},
+		{Key: "db.port", Value: "5432"},
+		{Key: "db.replicas.0", Value: "a"},
+		{Key: "db.replicas.1", Value: "b"},
+		{Key: "debug", Value: "true"},
+		{Key: "empty", Value: ""},
+		{Key: "ratio", Value: "0.5"},
+		{Key: "app.db.host", Value: "db.local"},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("expected %v, got %v", expected, pairs)
+	}
+
+	pairs, err = kvl.keyValuesFromStructuredSources(
+		[]string{"app=/settings.json"}, "_")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(pairs, []types.Pair{{Key: "app_db_host", Value: "db.local"}}) {
+		t.Fatalf("unexpected pairs %v", pairs)
+	}
+
+	_, err = kvl.keyValuesFromStructuredSources([]string{"/scalar.yaml"}, ".")
+	if err == nil || err.Error() !=
+		"structured source /scalar.yaml: content must be an object or a list" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestKeyValuesFromDirSource(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/conf/nginx.conf", []byte("worker_processes 1;"))
+	fSys.WriteFile("/conf/sites/a.conf", []byte("server a;"))
+	fSys.WriteFile("/conf/sites/b.conf.bak", []byte("server b;"))
+	fSys.WriteFile("/conf/certs/ca.der", []byte{0xff, 0xfe})
+	kvl := makeKvLoader(fSys)
+
+	testCases := map[string]struct {
+		source   types.DirSource
+		expected []string
+	}{
+		"all": {
+			source: types.DirSource{Path: "/conf"},
+			expected: []string{
+				"certs.ca.der", "nginx.conf", "sites.a.conf", "sites.b.conf.bak"},
+		},
+		"included basenames": {
+			source:   types.DirSource{Path: "/conf", Include: []string{"*.conf"}},
+			expected: []string{"nginx.conf", "sites.a.conf"},
+		},
+		"excluded subdirectory": {
+			source: types.DirSource{
+				Path: "/conf", Exclude: []string{"certs/**", "*.bak"}},
+			expected: []string{"nginx.conf", "sites.a.conf"},
+		},
+		"included paths": {
+			source:   types.DirSource{Path: "/conf", Include: []string{"sites/*"}},
+			expected: []string{"sites.a.conf", "sites.b.conf.bak"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			pairs, err := kvl.keyValuesFromDirSource(tc.source, ".")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var keys []string
+			for _, p := range pairs {
+				keys = append(keys, p.Key)
+			}
+			if !reflect.DeepEqual(keys, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, keys)
+			}
+		})
+	}
+
+	_, err := kvl.keyValuesFromDirSource(
+		types.DirSource{Path: "/conf", Include: []string{"[a"}}, ".")
+	if err == nil || err.Error() != `bad pattern "[a"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -102,6 +104,10 @@ type fileLoader struct {
 	// Used to get resources
 	getter remoteTargetGetter
 
+	// If non-nil, pins and records the remote
+	// files loaded.
+	lock *Lock
+
 	// Used to clean up, as needed.
 	cleaner func() error
 }
@@ -134,7 +140,7 @@ func newLoaderOrDie(
 		log.Fatalf("unable to make loader at '%s'; %v", path, err)
 	}
 	return newLoaderAtConfirmedDir(
-		lr, root, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
+		lr, root, fSys, nil, defaultCloner, getRemoteTarget)
 }
 
 // newLoaderAtConfirmedDir returns a new fileLoader with given root.
@@ -175,16 +181,39 @@ func demandDirectoryRoot(
 // New returns a new Loader, rooted relative to current loader,
 // or rooted in a temp directory holding a git repo clone.
 func (fl *fileLoader) New(path string) (ifc.Loader, error) {
+	ldr, err := fl.makeLoader(path)
+	if err != nil {
+		return nil, err
+	}
+	if fl.lock != nil {
+		// An attempt to Load the path as a file may
+		// have come first.
+		fl.lock.forget(path)
+	}
+	ldr.lock = fl.lock
+	return ldr, nil
+}
+
+func (fl *fileLoader) makeLoader(path string) (*fileLoader, error) {
 	if path == "" {
 		return nil, fmt.Errorf("new root cannot be empty")
 	}
 
+	repoSpec, errGit := git.NewRepoSpecFromUrl(path)
+	if errGit == nil && repoSpec.Archive != "" {
+		// Archives are fetched like git clones, not with the getter.
+		if errGit := fl.errIfRepoCycle(repoSpec); errGit != nil {
+			return nil, errGit
+		}
+		return newLoaderAtGitClone(
+			repoSpec, fl.fSys, fl, fl.cloner, fl.getter)
+	}
+
 	ldr, errGet := newLoaderAtGetter(path, fl.fSys, nil, fl.cloner, fl.getter)
 	if errGet == nil {
 		return ldr, nil
 	}
 
-	repoSpec, errGit := git.NewRepoSpecFromUrl(path)
 	if errGit == nil {
 		// Treat this as git repo clone request.
 		if errGit := fl.errIfRepoCycle(repoSpec); errGit != nil {
@@ -212,10 +241,10 @@ func (fl *fileLoader) New(path string) (ifc.Loader, error) {
 }
 
 // newLoaderAtGitClone returns a new Loader pinned to a temporary
-// directory holding a cloned git repo.
+// directory holding a cloned git repo, or an unpacked archive.
 func newLoaderAtGitClone(
 	repoSpec *git.RepoSpec, fSys filesys.FileSystem,
-	referrer *fileLoader, cloner git.Cloner, getter remoteTargetGetter) (ifc.Loader, error) {
+	referrer *fileLoader, cloner git.Cloner, getter remoteTargetGetter) (*fileLoader, error) {
 	cleaner := repoSpec.Cleaner(fSys)
 	err := cloner(repoSpec)
 	if err != nil {
@@ -250,6 +279,30 @@ func newLoaderAtGitClone(
 	}, nil
 }
 
+// Repo returns the URL of the remote repository that the
+// loader's files were fetched from, and the local root of
+// that copy, or empty strings if the files are local.
+func (fl *fileLoader) Repo() (string, string) {
+	if fl.rscSpec != nil {
+		return fl.rscSpec.Raw, fl.rscSpec.Dir.String()
+	}
+	repoSpec := fl.containingRepo()
+	if repoSpec == nil {
+		if fl.referrer != nil {
+			return fl.referrer.Repo()
+		}
+		return "", ""
+	}
+	if repoSpec.Archive != "" {
+		return repoSpec.ArchiveUrl(), repoSpec.CloneDir().String()
+	}
+	url := repoSpec.CloneSpec()
+	if repoSpec.Ref != "" {
+		url += "?ref=" + repoSpec.Ref
+	}
+	return url, repoSpec.CloneDir().String()
+}
+
 func (fl *fileLoader) errIfGitContainmentViolation(
 	base filesys.ConfirmedDir) error {
 	containingRepo := fl.containingRepo()
@@ -331,6 +384,11 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
+		if fl.lock != nil {
+			if err = fl.lock.checkFile(path, body); err != nil {
+				return nil, err
+			}
+		}
 		return body, nil
 	}
 
@@ -344,6 +402,41 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 	return fl.fSys.ReadFile(path)
 }
 
+// ListFiles returns the paths of the files in the tree of
+// the directory at the given path, relative to it and
+// sorted.  Relative paths are taken relative to the root.
+// The files are subject to the load restrictions only
+// when loaded.
+func (fl *fileLoader) ListFiles(path string) ([]string, error) {
+	if !filepath.IsAbs(path) {
+		path = fl.root.Join(path)
+	}
+	d, f, err := fl.fSys.CleanedAbs(path)
+	if err != nil {
+		return nil, err
+	}
+	if f != "" {
+		return nil, fmt.Errorf("'%s' must be a directory", path)
+	}
+	var files []string
+	err = fl.fSys.Walk(d.String(), func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(d.String(), p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 // Cleanup runs the cleaner.
 func (fl *fileLoader) Cleanup() error {
 	return fl.cleaner()
@@ -4,15 +4,19 @@
 package loader
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/ifc"
@@ -118,6 +122,30 @@ func TestLoaderNewSubDir(t *testing.T) {
 	}
 }
 
+func TestLoaderListFiles(t *testing.T) {
+	ldr, err := makeLoader().New("foo")
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	l, ok := ldr.(ifc.FileLister)
+	if !ok {
+		t.Fatalf("expected a FileLister, got %T", ldr)
+	}
+	files, err := l.ListFiles("project")
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	expected := []string{
+		"fileA.yaml", "fileD.yaml", "subdir1/fileB.yaml", "subdir2/fileC.yaml"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected %v, but got %v", expected, files)
+	}
+	_, err = l.ListFiles("project/fileA.yaml")
+	if err == nil || err.Error() != "'/foo/project/fileA.yaml' must be a directory" {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
 func TestLoaderBadRelative(t *testing.T) {
 	l1, err := makeLoader().New("foo/project/subdir1")
 	if err != nil {
@@ -672,3 +700,133 @@ func TestLoaderHTTP(t *testing.T) {
 		}
 	}
 }
+
+func TestCachingLoaderClonesOncePerRepo(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "kustomize-loader-cache-test-")
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	defer os.RemoveAll(cacheDir)
+	fSys := filesys.MakeFsOnDisk()
+
+	clones := 0
+	cloner := func(rs *git.RepoSpec) error {
+		clones++
+		return fSys.MkdirAll(rs.Dir.Join("foo/base"))
+	}
+	gets := 0
+	getter := func(rs *remoteTargetSpec) error {
+		gets++
+		return fSys.MkdirAll(rs.Dir.String())
+	}
+	cache := git.NewRepoCache(cacheDir, time.Hour)
+	l1 := newLoaderAtConfirmedDir(
+		RestrictionRootOnly, filesys.ConfirmedDir(cacheDir), fSys, nil,
+		cache.Cloner(cloner), cachingGetter(cache, getter))
+
+	// Two loaders at the same repository and ref share a clone,
+	// which outlives them.
+	repoSpec, err := git.NewRepoSpecFromUrl(
+		"github.com/someOrg/someRepo/foo/base?ref=v1")
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	var roots []string
+	for i := 0; i < 2; i++ {
+		spec := *repoSpec
+		l2, err := newLoaderAtGitClone(
+			&spec, fSys, l1, l1.cloner, l1.getter)
+		if err != nil {
+			t.Fatalf("unexpected err: %v\n", err)
+		}
+		roots = append(roots, l2.Root())
+		if err = l2.Cleanup(); err != nil {
+			t.Fatalf("unexpected err: %v\n", err)
+		}
+	}
+	if clones != 1 {
+		t.Fatalf("expected 1 clone, got %d", clones)
+	}
+	if roots[0] != roots[1] || !fSys.Exists(roots[0]) {
+		t.Fatalf("expected one lasting clone, got %v", roots)
+	}
+
+	// Likewise for remote targets got with the getter.
+	for i := 0; i < 2; i++ {
+		l2, err := l1.New("github.com/someOrg/otherRepo?ref=v1")
+		if err != nil {
+			t.Fatalf("unexpected err: %v\n", err)
+		}
+		if err = l2.Cleanup(); err != nil {
+			t.Fatalf("unexpected err: %v\n", err)
+		}
+		if !fSys.Exists(l2.Root()) {
+			t.Fatalf("expected %s to outlive its loader", l2.Root())
+		}
+	}
+	if gets != 1 {
+		t.Fatalf("expected 1 get, got %d", gets)
+	}
+}
+
+func TestLoaderAtTarball(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"base/kustomization.yaml":    "resources:\n- cm.yaml\n",
+		"base/cm.yaml":               "kind: ConfigMap\n",
+		"overlay/kustomization.yaml": "resources:\n- ../base\n",
+	} {
+		tw.WriteHeader(&tar.Header{
+			Name: name, Mode: 0644, Size: int64(len(content)),
+			Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	srv := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(buf.Bytes())
+		}))
+	defer srv.Close()
+	url := srv.URL + "/bundle.tar.gz"
+
+	fSys := filesys.MakeFsOnDisk()
+	l1 := newLoaderAtConfirmedDir(
+		RestrictionRootOnly, filesys.ConfirmedDir(os.TempDir()), fSys, nil,
+		git.ArchiveCloner(srv.Client(), nil), getNothing)
+	l2, err := l1.New(url + "//overlay")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !strings.HasSuffix(l2.Root(), "/overlay") {
+		t.Fatalf("unexpected root %s", l2.Root())
+	}
+	repo, _ := l2.(*fileLoader).Repo()
+	if repo != url {
+		t.Fatalf("expected repo %s, got %s", url, repo)
+	}
+	l3, err := l2.New("../base")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	b, err := l3.Load("cm.yaml")
+	if err != nil || string(b) != "kind: ConfigMap\n" {
+		t.Fatalf("unexpected load: %s, %v", b, err)
+	}
+	if _, err = l3.New(url + "//overlay"); err == nil ||
+		!strings.Contains(err.Error(), "cycle detected") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	if _, err = l1.New(url + "@sha256:0000//overlay"); err == nil ||
+		!strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch, got %v", err)
+	}
+	if err = l2.Cleanup(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if fSys.Exists(l2.Root()) {
+		t.Fatalf("expected %s to be removed", l2.Root())
+	}
+}
@@ -7,10 +7,10 @@ import (
 	"context"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/yujunz/go-getter"
 	"sigs.k8s.io/kustomize/api/filesys"
-	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
 )
 
@@ -20,17 +20,24 @@ type remoteTargetSpec struct {
 
 	// Dir is where the resource is saved
 	Dir filesys.ConfirmedDir
+
+	// cached is true if Dir belongs to a git.RepoCache,
+	// and so must outlive the loaders that read from it.
+	cached bool
 }
 
 // Getter is a function that can gets resource
 type remoteTargetGetter func(rs *remoteTargetSpec) error
 
-func newLoaderAtGetter(raw string, fSys filesys.FileSystem, referrer *fileLoader, cloner git.Cloner, getter remoteTargetGetter) (ifc.Loader, error) {
+func newLoaderAtGetter(raw string, fSys filesys.FileSystem, referrer *fileLoader, cloner git.Cloner, getter remoteTargetGetter) (*fileLoader, error) {
 	rs := &remoteTargetSpec{
 		Raw: raw,
 	}
 
 	cleaner := func() error {
+		if rs.cached {
+			return nil
+		}
 		return fSys.RemoveAll(rs.Dir.String())
 	}
 
@@ -52,15 +59,38 @@ func newLoaderAtGetter(raw string, fSys filesys.FileSystem, referrer *fileLoader
 	}, nil
 }
 
-func getRemoteTarget(rs *remoteTargetSpec) error {
-	var err error
+var remoteTargetDetectors = []getter.Detector{
+	new(getter.GitHubDetector),
+	new(getter.GitDetector),
+	new(getter.BitBucketDetector),
+}
 
-	rs.Dir, err = filesys.NewTmpConfirmedDir()
-	if err != nil {
-		return err
+// newRemoteTargetGetters returns getters like the go-getter
+// defaults, but not shared with other clients: a client
+// configures its getters, so clients sharing getters can't
+// run concurrently.
+func newRemoteTargetGetters() map[string]getter.Getter {
+	httpGetter := &getter.HttpGetter{Netrc: true}
+	return map[string]getter.Getter{
+		"file":  new(getter.FileGetter),
+		"git":   new(getter.GitGetter),
+		"hg":    new(getter.HgGetter),
+		"http":  httpGetter,
+		"https": httpGetter,
 	}
+}
 
-	rs.Dir = filesys.ConfirmedDir(rs.Dir.Join("repo"))
+// getRemoteTarget gets the resource into rs.Dir, which
+// mustn't exist yet, or if rs.Dir is empty, into a new
+// temporary directory.
+func getRemoteTarget(rs *remoteTargetSpec) error {
+	if rs.Dir == "" {
+		dir, err := filesys.NewTmpConfirmedDir()
+		if err != nil {
+			return err
+		}
+		rs.Dir = filesys.ConfirmedDir(dir.Join("repo"))
+	}
 
 	// Get the pwd
 	pwd, err := os.Getwd()
@@ -70,17 +100,14 @@ func getRemoteTarget(rs *remoteTargetSpec) error {
 
 	opts := []getter.ClientOption{}
 	client := &getter.Client{
-		Ctx:  context.TODO(),
-		Src:  rs.Raw,
-		Dst:  rs.Dir.String(),
-		Pwd:  pwd,
-		Mode: getter.ClientModeAny,
-		Detectors: []getter.Detector{
-			new(getter.GitHubDetector),
-			new(getter.GitDetector),
-			new(getter.BitBucketDetector),
-		},
-		Options: opts,
+		Ctx:       context.TODO(),
+		Src:       rs.Raw,
+		Dst:       rs.Dir.String(),
+		Pwd:       pwd,
+		Mode:      getter.ClientModeAny,
+		Detectors: remoteTargetDetectors,
+		Getters:   newRemoteTargetGetters(),
+		Options:   opts,
 	}
 	return client.Get()
 }
@@ -101,3 +128,32 @@ func getNothing(rs *remoteTargetSpec) error {
 	_, err = getter.Detect(rs.Raw, pwd, []getter.Detector{})
 	return err
 }
+
+// cachingGetter returns a getter that keeps one copy
+// of each remote resource in the cache, got with get.
+func cachingGetter(
+	cache *git.RepoCache, get remoteTargetGetter) remoteTargetGetter {
+	return func(rs *remoteTargetSpec) error {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if _, err = getter.Detect(
+			rs.Raw, pwd, remoteTargetDetectors); err != nil {
+			// Not remote; nothing to cache.
+			return get(rs)
+		}
+		dir, err := cache.Get(rs.Raw, func(dir string) error {
+			return get(&remoteTargetSpec{
+				Raw: rs.Raw,
+				Dir: filesys.ConfirmedDir(filepath.Join(dir, "repo")),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		rs.Dir = filesys.ConfirmedDir(filepath.Join(dir, "repo"))
+		rs.cached = true
+		return nil
+	}
+}
@@ -6,6 +6,8 @@ package loader
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/ifc"
@@ -21,22 +23,88 @@ import (
 func NewLoader(
 	lr LoadRestrictorFunc,
 	target string, fSys filesys.FileSystem) (ifc.Loader, error) {
+	return NewLockedLoader(lr, target, fSys, nil, nil)
+}
+
+// defaultCloner fetches archives over http, and clones
+// git repositories with the git program.
+var defaultCloner = git.ArchiveCloner(
+	http.DefaultClient, git.ClonerUsingGitExec)
+
+// RepoCache holds copies of remote repositories fetched
+// by caching loaders; see NewCachingLoader.
+type RepoCache struct {
+	cache *git.RepoCache
+}
+
+// NewRepoCache returns a RepoCache keeping its copies
+// in dir.  Copies left in dir by other RepoCaches are
+// reused if younger than ttl.
+func NewRepoCache(dir string, ttl time.Duration) *RepoCache {
+	return &RepoCache{cache: git.NewRepoCache(dir, ttl)}
+}
+
+// NewCachingLoader is like NewLoader, except that the remote
+// repositories that it (and the loaders it makes) fetch are
+// kept in the cache, e.g. one git clone per repository and
+// ref.  Loaders sharing a cache fetch each copy at most once.
+func NewCachingLoader(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem,
+	cache *RepoCache) (ifc.Loader, error) {
+	return NewLockedLoader(lr, target, fSys, cache, nil)
+}
+
+// NewLockedLoader is like NewCachingLoader, except that the
+// remotes that it (and the loaders it makes) fetch are pinned
+// to the versions in the lock, and recorded in it.  Either of
+// cache and lock may be nil.
+func NewLockedLoader(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem,
+	cache *RepoCache, lock *Lock) (ifc.Loader, error) {
+	cloner, getter := defaultCloner, remoteTargetGetter(getRemoteTarget)
+	if cache != nil {
+		cloner = cache.cache.Cloner(cloner)
+		getter = cachingGetter(cache.cache, getter)
+	}
+	if lock != nil {
+		cloner = lock.cloner(cloner)
+		getter = lock.getter(getter)
+	}
+	ldr, err := newLoader(lr, target, fSys, cloner, getter)
+	if err != nil {
+		return nil, err
+	}
+	ldr.lock = lock
+	return ldr, nil
+}
+
+func newLoader(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem,
+	cloner git.Cloner, getter remoteTargetGetter) (*fileLoader, error) {
 
-	ldr, errGet := newLoaderAtGetter(target, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
+	repoSpec, errGit := git.NewRepoSpecFromUrl(target)
+	if errGit == nil && repoSpec.Archive != "" {
+		return newLoaderAtGitClone(
+			repoSpec, fSys, nil, cloner, getter)
+	}
+
+	ldr, errGet := newLoaderAtGetter(target, fSys, nil, cloner, getter)
 	if errGet == nil {
 		return ldr, nil
 	}
 
-	repoSpec, errGit := git.NewRepoSpecFromUrl(target)
 	if errGit == nil {
 		// The target qualifies as a remote git target.
 		return newLoaderAtGitClone(
-			repoSpec, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
+			repoSpec, fSys, nil, cloner, getter)
 	}
 
 	root, errDir := demandDirectoryRoot(fSys, target)
 	if errDir == nil {
-		return newLoaderAtConfirmedDir(lr, root, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget), nil
+		return newLoaderAtConfirmedDir(lr, root, fSys, nil, cloner, getter), nil
 	}
 
 	return nil, fmt.Errorf("Error creating new loader with git: %v, dir: %v, get: %v", errGit, errDir, errGet)
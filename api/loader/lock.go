@@ -0,0 +1,277 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yujunz/go-getter"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Lock pins the remotes fetched by loaders to the versions
+// recorded in a KustomizationLock, and records the versions
+// actually fetched.
+//
+// Git repositories are pinned by fetching the recorded
+// commit instead of the ref.  Other remotes (archives,
+// files fetched over http) can't be pinned that way, so
+// they're checked against the recorded sum.  A strict Lock
+// also refuses remotes that aren't recorded.
+type Lock struct {
+	pins map[string]types.LockedRemote
+	// If true, every remote fetched must be in pins,
+	// and match.
+	strict bool
+	// Returns the commit a git ref names.
+	resolve func(repoSpec *git.RepoSpec) (string, error)
+
+	mu      sync.Mutex
+	fetched map[string]types.LockedRemote
+}
+
+// NewLock returns a Lock pinning remotes to the versions in
+// pins, which may be nil.  If strict, fetching a remote that
+// isn't in pins, or that doesn't match, is an error.
+func NewLock(pins *types.KustomizationLock, strict bool) *Lock {
+	l := &Lock{
+		pins:    make(map[string]types.LockedRemote),
+		strict:  strict,
+		resolve: git.CommitOf,
+		fetched: make(map[string]types.LockedRemote),
+	}
+	if pins != nil {
+		for _, r := range pins.Remotes {
+			l.pins[r.Url] = r
+		}
+	}
+	return l
+}
+
+// Fetched returns a lock of the remotes fetched so far.
+func (l *Lock) Fetched() *types.KustomizationLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock := &types.KustomizationLock{}
+	lock.FixKustomizationLockPostUnmarshalling()
+	for _, r := range l.fetched {
+		lock.Remotes = append(lock.Remotes, r)
+	}
+	sort.Slice(lock.Remotes, func(i, j int) bool {
+		return lock.Remotes[i].Url < lock.Remotes[j].Url
+	})
+	return lock
+}
+
+// pinCommit returns the commit to fetch for the url.
+func (l *Lock) pinCommit(url string, repoSpec *git.RepoSpec) (string, error) {
+	if pin, ok := l.pins[url]; ok && pin.Commit != "" {
+		return pin.Commit, nil
+	}
+	if l.strict {
+		return "", l.errNotLocked(url)
+	}
+	return l.resolve(repoSpec)
+}
+
+// record notes the version fetched of a remote, checking
+// it against the pinned version, if any.
+func (l *Lock) record(fetched types.LockedRemote) error {
+	pin, ok := l.pins[fetched.Url]
+	if !ok && l.strict {
+		return l.errNotLocked(fetched.Url)
+	}
+	if ok && (pin.Commit != fetched.Commit || pin.Sum != fetched.Sum) {
+		return fmt.Errorf(
+			"%s doesn't match %s: expected %s, got %s",
+			fetched.Url, konfig.LockFileName,
+			pin.Commit+pin.Sum, fetched.Commit+fetched.Sum)
+	}
+	l.mu.Lock()
+	l.fetched[fetched.Url] = fetched
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *Lock) errNotLocked(url string) error {
+	return fmt.Errorf(
+		"%s isn't in %s; run 'kustomize edit lock'",
+		url, konfig.LockFileName)
+}
+
+// gitLockUrl names a git repository and ref in a lock.
+func gitLockUrl(repoSpec *git.RepoSpec) string {
+	if repoSpec.Ref == "" {
+		return repoSpec.CloneSpec()
+	}
+	return repoSpec.CloneSpec() + "?ref=" + repoSpec.Ref
+}
+
+// cloner returns a Cloner that clones git repositories
+// at their pinned commit, and records the sum of archives.
+func (l *Lock) cloner(cloner git.Cloner) git.Cloner {
+	return func(repoSpec *git.RepoSpec) error {
+		if repoSpec.Archive != "" {
+			if err := cloner(repoSpec); err != nil {
+				return err
+			}
+			sum, err := sumDir(repoSpec.Dir.String())
+			if err != nil {
+				return err
+			}
+			return l.record(types.LockedRemote{
+				Url: repoSpec.ArchiveUrl(), Sum: sum})
+		}
+		url := gitLockUrl(repoSpec)
+		commit, err := l.pinCommit(url, repoSpec)
+		if err != nil {
+			return err
+		}
+		repoSpec.Ref = commit
+		if err = cloner(repoSpec); err != nil {
+			return err
+		}
+		return l.record(types.LockedRemote{Url: url, Commit: commit})
+	}
+}
+
+var refParam = regexp.MustCompile(`([?&])(ref|version)=[^&]*`)
+
+// getter returns a getter that gets git repositories at
+// their pinned commit, and records the sum of other remotes.
+func (l *Lock) getter(get remoteTargetGetter) remoteTargetGetter {
+	return func(rs *remoteTargetSpec) error {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		src, err := getter.Detect(rs.Raw, pwd, remoteTargetDetectors)
+		if err != nil {
+			// Not remote; nothing to lock.
+			return get(rs)
+		}
+		if !strings.HasPrefix(src, "git::") {
+			if err = get(rs); err != nil {
+				return err
+			}
+			sum, err := sumDir(rs.Dir.String())
+			if err != nil {
+				return err
+			}
+			return l.record(types.LockedRemote{Url: rs.Raw, Sum: sum})
+		}
+		repoSpec, err := git.NewRepoSpecFromUrl(rs.Raw)
+		if err != nil {
+			return err
+		}
+		url := gitLockUrl(repoSpec)
+		commit, err := l.pinCommit(url, repoSpec)
+		if err != nil {
+			return err
+		}
+		pinned := *rs
+		if refParam.MatchString(rs.Raw) {
+			pinned.Raw = refParam.ReplaceAllString(rs.Raw, "${1}ref="+commit)
+		} else if strings.Contains(rs.Raw, "?") {
+			pinned.Raw = rs.Raw + "&ref=" + commit
+		} else {
+			pinned.Raw = rs.Raw + "?ref=" + commit
+		}
+		if err = get(&pinned); err != nil {
+			return err
+		}
+		rs.Dir, rs.cached = pinned.Dir, pinned.cached
+		return l.record(types.LockedRemote{Url: url, Commit: commit})
+	}
+}
+
+// checkFile records the sum of a file fetched over http.
+func (l *Lock) checkFile(url string, content []byte) error {
+	return l.record(types.LockedRemote{
+		Url: url, Sum: fmt.Sprintf("sha256:%x", sha256.Sum256(content))})
+}
+
+// forget drops the record of a file fetched from url,
+// which turned out to be a base rather than a file.
+func (l *Lock) forget(url string) {
+	l.mu.Lock()
+	delete(l.fetched, url)
+	l.mu.Unlock()
+}
+
+// sumDir hashes the names and content of the files in dir,
+// less any .git directory.
+func sumDir(dir string) (string, error) {
+	var lines []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf(
+			"%x  %s\n", sha256.Sum256(b), filepath.ToSlash(rel)))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf(
+		"sha256:%x", sha256.Sum256([]byte(strings.Join(lines, "")))), nil
+}
+
+// ReadLockFile returns the lock in the given directory,
+// or nil if there's no lock file.
+func ReadLockFile(
+	fSys filesys.FileSystem, dir string) (*types.KustomizationLock, error) {
+	path := filepath.Join(dir, konfig.LockFileName)
+	if !fSys.Exists(path) {
+		return nil, nil
+	}
+	b, err := fSys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock types.KustomizationLock
+	if err = yaml.UnmarshalStrict(b, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	lock.FixKustomizationLockPostUnmarshalling()
+	return &lock, nil
+}
+
+// WriteLockFile writes the lock into the given directory.
+func WriteLockFile(
+	fSys filesys.FileSystem, dir string, lock *types.KustomizationLock) error {
+	b, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(filepath.Join(dir, konfig.LockFileName), b)
+}
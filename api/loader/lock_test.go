@@ -0,0 +1,184 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	commitA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	commitB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestLockPinsGitCommits(t *testing.T) {
+	resolved := 0
+	resolve := func(rs *git.RepoSpec) (string, error) {
+		resolved++
+		return commitA, nil
+	}
+	var refs, raws []string
+	cloner := func(rs *git.RepoSpec) error {
+		refs = append(refs, rs.Ref)
+		return nil
+	}
+	get := func(rs *remoteTargetSpec) error {
+		raws = append(raws, rs.Raw)
+		return nil
+	}
+
+	// Unpinned repos are fetched at the commit their ref
+	// names at the time.
+	lock := NewLock(nil, false)
+	lock.resolve = resolve
+	rs, _ := git.NewRepoSpecFromUrl("github.com/someOrg/someRepo/foo?ref=main")
+	if err := lock.cloner(cloner)(rs); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	err := lock.getter(get)(&remoteTargetSpec{
+		Raw: "github.com/someOrg/otherRepo/foo?ref=v1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !reflect.DeepEqual(refs, []string{commitA}) ||
+		!reflect.DeepEqual(raws, []string{
+			"github.com/someOrg/otherRepo/foo?ref=" + commitA}) {
+		t.Fatalf("unexpected fetches: %v, %v", refs, raws)
+	}
+	fetched := lock.Fetched()
+	expected := &types.KustomizationLock{
+		TypeMeta: types.TypeMeta{
+			Kind:       types.KustomizationLockKind,
+			APIVersion: types.KustomizationLockVersion,
+		},
+		Remotes: []types.LockedRemote{
+			{Url: "https://github.com/someOrg/otherRepo.git?ref=v1", Commit: commitA},
+			{Url: "https://github.com/someOrg/someRepo.git?ref=main", Commit: commitA},
+		},
+	}
+	if !reflect.DeepEqual(fetched, expected) {
+		t.Fatalf("expected %v, got %v", expected, fetched)
+	}
+
+	// Pinned repos are fetched at the pinned commit.
+	expected.Remotes[1].Commit = commitB
+	lock = NewLock(expected, true)
+	lock.resolve = resolve
+	refs = nil
+	rs, _ = git.NewRepoSpecFromUrl("https://github.com/someOrg/someRepo//bar?ref=main")
+	if err = lock.cloner(cloner)(rs); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !reflect.DeepEqual(refs, []string{commitB}) || resolved != 2 {
+		t.Fatalf("unexpected fetches: %v, %d", refs, resolved)
+	}
+
+	// Strict locks refuse unpinned repos.
+	rs, _ = git.NewRepoSpecFromUrl("github.com/someOrg/someRepo?ref=dev")
+	err = lock.cloner(cloner)(rs)
+	if err == nil || !strings.Contains(err.Error(),
+		"https://github.com/someOrg/someRepo.git?ref=dev isn't in kustomization.lock") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestLockChecksSums(t *testing.T) {
+	content := "kind: ConfigMap\n"
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(content))
+		}))
+	defer srv.Close()
+	url := srv.URL + "/cm.yaml"
+	dir, err := ioutil.TempDir("", "kustomize-lock-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	get := func(rs *remoteTargetSpec) error {
+		rs.Dir = filesys.ConfirmedDir(dir)
+		return ioutil.WriteFile(
+			filepath.Join(dir, "cm.yaml"), []byte(content), 0600)
+	}
+	load := func(lock *Lock) error {
+		l := NewFileLoaderAtRoot(filesys.MakeFsInMemory())
+		l.lock = lock
+		if _, err := l.Load(url); err != nil {
+			return err
+		}
+		return lock.getter(get)(&remoteTargetSpec{Raw: srv.URL + "/bundle.zip"})
+	}
+
+	lock := NewLock(nil, false)
+	if err = load(lock); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	pins := lock.Fetched()
+	if len(pins.Remotes) != 2 {
+		t.Fatalf("unexpected lock: %v", pins)
+	}
+	if err = load(NewLock(pins, true)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	content = "kind: Secret\n"
+	err = load(NewLock(pins, true))
+	if err == nil || !strings.Contains(err.Error(), url+" doesn't match kustomization.lock") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	// A lax lock checks the remotes it records too.
+	err = load(NewLock(pins, false))
+	if err == nil || !strings.Contains(err.Error(), url+" doesn't match kustomization.lock") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	// But it doesn't mind those it doesn't record.
+	if err = load(NewLock(&types.KustomizationLock{}, false)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	lock, err := ReadLockFile(fSys, "/app")
+	if lock != nil || err != nil {
+		t.Fatalf("expected no lock, got %v, %v", lock, err)
+	}
+	lock = &types.KustomizationLock{Remotes: []types.LockedRemote{
+		{Url: "https://github.com/someOrg/someRepo.git", Commit: commitA},
+		{Url: "https://example.com/base.tar.gz", Sum: "sha256:abc"},
+	}}
+	lock.FixKustomizationLockPostUnmarshalling()
+	if err = WriteLockFile(fSys, "/app", lock); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	b, _ := fSys.ReadFile("/app/kustomization.lock")
+	if string(b) != `apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: KustomizationLock
+remotes:
+- commit: aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+  url: https://github.com/someOrg/someRepo.git
+- sum: sha256:abc
+  url: https://example.com/base.tar.gz
+` {
+		t.Fatalf("unexpected lock file:\n%s", b)
+	}
+	actual, err := ReadLockFile(fSys, "/app")
+	if err != nil || !reflect.DeepEqual(actual, lock) {
+		t.Fatalf("expected %v, got %v, %v", lock, actual, err)
+	}
+	fSys.WriteFile("/app/kustomization.lock", []byte("remotes: 3\n"))
+	if _, err = ReadLockFile(fSys, "/app"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -6,7 +6,7 @@ package resmap
 import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
-	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/kusterr"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 )
@@ -49,7 +49,7 @@ func (rmF *Factory) FromFile(
 	}
 	m, err := rmF.NewResMapFromBytes(content)
 	if err != nil {
-		return nil, kusterr.Handler(err, path)
+		return nil, kusterr.DecodeError(err, path, content)
 	}
 	return m, nil
 }
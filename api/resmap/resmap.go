@@ -12,6 +12,7 @@ import (
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/kusterr"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -42,6 +43,15 @@ func NewPluginHelpers(ldr ifc.Loader, v ifc.Validator, rf *Factory) *PluginHelpe
 	return &PluginHelpers{ldr: ldr, v: v, rf: rf}
 }
 
+// NewPluginHelpersWithConfig makes an instance of
+// PluginHelpers that also holds the configuration of
+// plugins given to kustomize.
+func NewPluginHelpersWithConfig(
+	ldr ifc.Loader, v ifc.Validator, rf *Factory,
+	pc *types.PluginConfig) *PluginHelpers {
+	return &PluginHelpers{ldr: ldr, v: v, rf: rf, pc: pc}
+}
+
 // PluginHelpers holds things that any or all plugins might need.
 // This should be available to each plugin, in addition to
 // any plugin-specific configuration.
@@ -49,6 +59,14 @@ type PluginHelpers struct {
 	ldr ifc.Loader
 	v   ifc.Validator
 	rf  *Factory
+	pc  *types.PluginConfig
+}
+
+// GeneralConfig returns the configuration of plugins
+// given to kustomize, e.g. by its command line flags,
+// or nil if the PluginHelpers weren't made with it.
+func (c *PluginHelpers) GeneralConfig() *types.PluginConfig {
+	return c.pc
 }
 
 func (c *PluginHelpers) Loader() ifc.Loader {
@@ -290,8 +308,8 @@ func (m *resWrangler) Resources() []*resource.Resource {
 func (m *resWrangler) Append(res *resource.Resource) error {
 	id := res.CurId()
 	if r := m.GetMatchingResourcesByCurrentId(id.Equals); len(r) > 0 {
-		return fmt.Errorf(
-			"may not add resource with an already registered id: %s", id)
+		return kusterr.WithResId(fmt.Errorf(
+			"may not add resource with an already registered id: %s", id), id)
 	}
 	m.rList = append(m.rList, res)
 	return nil
@@ -431,9 +449,9 @@ func (m *resWrangler) GetById(
 	if err2 == nil {
 		return match, nil
 	}
-	return nil, fmt.Errorf(
+	return nil, kusterr.WithResId(fmt.Errorf(
 		"%s; %s; failed to find unique target for patch %s",
-		err1.Error(), err2.Error(), id.GvknString())
+		err1.Error(), err2.Error(), id.GvknString()), id)
 }
 
 type resFinder func(IdMatcher) []*resource.Resource
@@ -666,8 +684,8 @@ func (m *resWrangler) appendReplaceOrMerge(
 	case 0:
 		switch res.Behavior() {
 		case types.BehaviorMerge, types.BehaviorReplace:
-			return fmt.Errorf(
-				"id %#v does not exist; cannot merge or replace", id)
+			return kusterr.WithResId(fmt.Errorf(
+				"id %#v does not exist; cannot merge or replace", id), id)
 		default:
 			// presumably types.BehaviorCreate
 			err := m.Append(res)
@@ -690,8 +708,8 @@ func (m *resWrangler) appendReplaceOrMerge(
 		case types.BehaviorMerge:
 			res.Merge(old)
 		default:
-			return fmt.Errorf(
-				"id %#v exists; behavior must be merge or replace", id)
+			return kusterr.WithResId(fmt.Errorf(
+				"id %#v exists; behavior must be merge or replace", id), id)
 		}
 		i, err := m.Replace(res)
 		if err != nil {
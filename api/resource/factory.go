@@ -10,7 +10,7 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/ifc"
-	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/kusterr"
 	"sigs.k8s.io/kustomize/api/types"
 )
 
@@ -87,7 +87,7 @@ func (rf *Factory) SliceFromPatches(
 		}
 		res, err := rf.SliceFromBytes(content)
 		if err != nil {
-			return nil, kusterr.Handler(err, string(path))
+			return nil, kusterr.DecodeError(err, string(path), content)
 		}
 		result = append(result, res...)
 	}
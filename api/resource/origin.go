@@ -0,0 +1,103 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/yaml"
+)
+
+// Origin records where a resource came from, or which
+// transformer changed it.
+//
+// A resource read from a file has a Path (and a Repo,
+// if the file came from a remote repository).  A resource
+// made by a generator, and every transformation, has
+// the generator or transformer in ConfiguredBy, and the
+// kustomization file that configured it in ConfiguredIn.
+type Origin struct {
+	// Path of the file, relative to the build root,
+	// or to the root of the repository if Repo is set.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Repo is the remote repository holding the file.
+	Repo string `json:"repo,omitempty" yaml:"repo,omitempty"`
+
+	// ConfiguredIn is the kustomization file that configured
+	// the generator or transformer, relative to the build root.
+	ConfiguredIn string `json:"configuredIn,omitempty" yaml:"configuredIn,omitempty"`
+
+	// ConfiguredBy identifies the generator or transformer.
+	ConfiguredBy *PluginRef `json:"configuredBy,omitempty" yaml:"configuredBy,omitempty"`
+}
+
+// PluginRef identifies a builtin or external plugin
+// by the apiVersion, kind and name of its configuration.
+type PluginRef struct {
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// SetOrigin records the origin of the resource in an annotation.
+func (r *Resource) SetOrigin(o *Origin) error {
+	b, err := yaml.Marshal(o)
+	if err != nil {
+		return err
+	}
+	r.setAnnotation(konfig.OriginAnnotationKey, string(b))
+	return nil
+}
+
+// GetOrigin returns the recorded origin of the resource,
+// or nil if none was recorded.
+func (r *Resource) GetOrigin() (*Origin, error) {
+	v, ok := r.GetAnnotations()[konfig.OriginAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var o Origin
+	if err := yaml.Unmarshal([]byte(v), &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// AppendTransformation adds a transformer to the
+// recorded list of transformers that changed the resource.
+func (r *Resource) AppendTransformation(o *Origin) error {
+	trail, err := r.GetTransformations()
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(append(trail, o))
+	if err != nil {
+		return err
+	}
+	r.setAnnotation(konfig.TransformationsAnnotationKey, string(b))
+	return nil
+}
+
+// GetTransformations returns the recorded list of
+// transformers that changed the resource.
+func (r *Resource) GetTransformations() ([]*Origin, error) {
+	v, ok := r.GetAnnotations()[konfig.TransformationsAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var trail []*Origin
+	if err := yaml.Unmarshal([]byte(v), &trail); err != nil {
+		return nil, err
+	}
+	return trail, nil
+}
+
+func (r *Resource) setAnnotation(k, v string) {
+	annotations := r.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[k] = v
+	r.SetAnnotations(annotations)
+}
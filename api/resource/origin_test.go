@@ -0,0 +1,60 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "sigs.k8s.io/kustomize/api/resource"
+)
+
+func TestOriginRoundTrip(t *testing.T) {
+	r := factory.FromMap(
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "winnie",
+			},
+		})
+	o, err := r.GetOrigin()
+	if err != nil || o != nil {
+		t.Fatalf("expected no origin, got %v, %v", o, err)
+	}
+	want := &Origin{Path: "base/cm.yaml", Repo: "github.com/foo/bar?ref=v1"}
+	if err = r.SetOrigin(want); err != nil {
+		t.Fatal(err)
+	}
+	if o, err = r.GetOrigin(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o, want) {
+		t.Fatalf("expected %v, got %v", want, o)
+	}
+
+	trail := []*Origin{
+		{
+			ConfiguredIn: "kustomization.yaml",
+			ConfiguredBy: &PluginRef{APIVersion: "builtin", Kind: "LabelTransformer"},
+		},
+		{
+			ConfiguredIn: "overlay/kustomization.yaml",
+			ConfiguredBy: &PluginRef{
+				APIVersion: "someteam.example.com/v1", Kind: "Prefixer", Name: "p"},
+		},
+	}
+	for _, t2 := range trail {
+		if err = r.AppendTransformation(t2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := r.GetTransformations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, trail) {
+		t.Fatalf("expected %v, got %v", trail, got)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Violation is a part of a document that doesn't match
+// its schema.
+type Violation struct {
+	// Path of the offending field, e.g. "images[0].newTag".
+	Path string
+	// Position of the field in the document, counting
+	// from 1.
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return fmt.Sprintf("line %d: %s", v.Line, v.Msg)
+	}
+	return fmt.Sprintf("line %d: %s: %s", v.Line, v.Path, v.Msg)
+}
+
+// Violations is the error of a document that doesn't
+// match its schema.
+type Violations []Violation
+
+func (vs Violations) Error() string {
+	lines := make([]string, len(vs))
+	for i, v := range vs {
+		lines[i] = v.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Check checks the YAML document in content against the
+// schema, returning its Violations, if any.
+func (s *Schema) Check(content []byte) error {
+	node, err := yaml.Parse(string(content))
+	if err == io.EOF {
+		// Nothing to check.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	vs := s.check(s, node.YNode(), "")
+	if len(vs) == 0 {
+		return nil
+	}
+	return vs
+}
+
+// check checks the node against the schema s, whose
+// references are to definitions of root.
+func (s *Schema) check(root *Schema, n *yaml.Node, path string) Violations {
+	if s.Ref != "" {
+		return root.definition(s.Ref).check(root, n, path)
+	}
+	if len(s.OneOf) > 0 {
+		// A document saying what kind it is is checked
+		// against that kind, else against the closest match.
+		for _, o := range s.OneOf {
+			if d := root.definition(o.Ref); d != nil && d.isKindOf(n) {
+				return d.check(root, n, path)
+			}
+		}
+		var best Violations
+		for i, o := range s.OneOf {
+			vs := o.check(root, n, path)
+			if len(vs) == 0 {
+				return nil
+			}
+			if i == 0 || len(vs) < len(best) {
+				best = vs
+			}
+		}
+		return best
+	}
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Tag == "!!null" {
+		// As for encoding/json, null is the zero value.
+		return nil
+	}
+	violation := func(format string, args ...interface{}) Violations {
+		return Violations{{
+			Path: path, Line: n.Line, Column: n.Column,
+			Msg: fmt.Sprintf(format, args...)}}
+	}
+	if got := typeOf(n); s.Type != "" && !conforms(got, s.Type) {
+		return violation("expected %s, got %s", s.Type, got)
+	}
+	if len(s.Enum) > 0 && !contains(s.Enum, n.Value) {
+		return violation("expected %s", strings.Join(quoted(s.Enum), " or "))
+	}
+	var vs Violations
+	switch s.Type {
+	case "array":
+		for i, item := range n.Content {
+			vs = append(vs, s.Items.check(
+				root, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "object":
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			seen[k.Value] = true
+			fieldPath := k.Value
+			if path != "" {
+				fieldPath = path + "." + k.Value
+			}
+			if p, ok := s.Properties[k.Value]; ok {
+				vs = append(vs, p.check(root, v, fieldPath)...)
+				continue
+			}
+			if values, ok := s.AdditionalProperties.(*Schema); ok {
+				vs = append(vs, values.check(root, v, fieldPath)...)
+				continue
+			}
+			msg := "unknown field"
+			if near := s.nearest(k.Value); near != "" {
+				msg += fmt.Sprintf("; did you mean '%s'?", near)
+			}
+			vs = append(vs, Violation{
+				Path: fieldPath, Line: k.Line, Column: k.Column, Msg: msg})
+		}
+		for _, r := range s.Required {
+			if !seen[r] {
+				vs = append(vs, violation("missing field '%s'", r)...)
+			}
+		}
+	}
+	return vs
+}
+
+// isKindOf returns true if the node is a mapping whose
+// kind is the one kind that s allows.
+func (s *Schema) isKindOf(n *yaml.Node) bool {
+	k, ok := s.Properties["kind"]
+	if !ok || len(k.Enum) != 1 || n.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == "kind" {
+			return n.Content[i+1].Value == k.Enum[0]
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON type of the value of the node.
+func typeOf(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch n.Tag {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	}
+	if yaml11Bools[n.Value] && n.Style == 0 {
+		// sigs.k8s.io/yaml, which decodes kustomizations,
+		// speaks YAML 1.1.
+		return "boolean"
+	}
+	return "string"
+}
+
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+}
+
+func conforms(got, expected string) bool {
+	return got == expected || (got == "integer" && expected == "number")
+}
+
+// nearest returns the property of s that the name is
+// probably a misspelling of, if any.
+func (s *Schema) nearest(name string) string {
+	best, bestD := "", 3
+	for _, p := range s.propertyNames() {
+		if strings.EqualFold(p, name) {
+			return p
+		}
+		if d := distance(p, name); d < bestD {
+			best, bestD = p, d
+		}
+	}
+	return best
+}
+
+// distance returns the Levenshtein distance of a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func contains(l []string, s string) bool {
+	for _, x := range l {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func quoted(l []string) []string {
+	r := make([]string, len(l))
+	for i, v := range l {
+		r[i] = "'" + v + "'"
+	}
+	return r
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package schema makes JSON Schemas of the types in
+// api/types, and checks YAML documents against them.
+package schema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// Draft is the JSON Schema version of the schemas made.
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema, less the keywords that
+// schemas made from Go types don't need.
+type Schema struct {
+	Schema string   `json:"$schema,omitempty"`
+	Ref    string   `json:"$ref,omitempty"`
+	Title  string   `json:"title,omitempty"`
+	Type   string   `json:"type,omitempty"`
+	Enum   []string `json:"enum,omitempty"`
+	// Properties of an object, and which of them are required.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// Either false, if an object may have no properties
+	// other than Properties, or the *Schema of the values
+	// of a map.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	// Schema of the items of an array.
+	Items *Schema   `json:"items,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	// Schemas referred to by Ref, by name.
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+const definitionsPrefix = "#/definitions/"
+
+// Kustomization returns the schema of kustomization
+// files: of either a Kustomization or a Component.
+func Kustomization() *Schema {
+	defs := make(map[string]*Schema)
+	schemaOf(reflect.TypeOf(types.Kustomization{}), defs)
+	fields := defs["Kustomization"]
+
+	// Entries of resources and components are paths, or
+	// paths with when expressions.
+	entries := &Schema{Type: "array", Items: &Schema{OneOf: []*Schema{
+		schemaOf(reflect.TypeOf(types.PathEntry{}), defs), {Type: "string"}}}}
+	fields.Properties["resources"] = entries
+	fields.Properties["components"] = entries
+
+	// Kustomizations and Components have the same fields,
+	// but a Component must say that it is one.
+	defs[types.KustomizationKind] = withTypeMeta(
+		fields, types.KustomizationKind, types.KustomizationVersion, false)
+	defs[types.ComponentKind] = withTypeMeta(
+		fields, types.ComponentKind, types.ComponentVersion, true)
+	return &Schema{
+		Schema: Draft,
+		Title:  "Kustomization",
+		OneOf: []*Schema{
+			{Ref: definitionsPrefix + types.KustomizationKind},
+			{Ref: definitionsPrefix + types.ComponentKind},
+		},
+		Definitions: defs,
+	}
+}
+
+// withTypeMeta returns a copy of the schema of an object
+// with kind and apiVersion fields, restricted to the
+// given kind and version.
+func withTypeMeta(s *Schema, kind, version string, required bool) *Schema {
+	c := *s
+	c.Title = kind
+	c.Properties = make(map[string]*Schema, len(s.Properties))
+	for k, v := range s.Properties {
+		c.Properties[k] = v
+	}
+	c.Properties["kind"] = &Schema{Type: "string", Enum: []string{kind}}
+	c.Properties["apiVersion"] = &Schema{Type: "string", Enum: []string{version}}
+	if required {
+		c.Required = []string{"kind"}
+	}
+	return &c
+}
+
+// schemaOf returns the schema of values of type t, as
+// encoding/json would encode them.  Structs are added to
+// defs by name, and referred to.
+func schemaOf(t reflect.Type, defs map[string]*Schema) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), defs)}
+	case reflect.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: schemaOf(t.Elem(), defs),
+		}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			s := &Schema{
+				Type:                 "object",
+				Properties:           make(map[string]*Schema),
+				AdditionalProperties: false,
+			}
+			// Added before its fields, in case they refer to it.
+			defs[t.Name()] = s
+			addFields(s, t, defs)
+		}
+		return &Schema{Ref: definitionsPrefix + t.Name()}
+	default:
+		// Any value, e.g. of an interface{}.
+		return &Schema{}
+	}
+}
+
+// addFields adds the fields of the struct type t to the
+// properties of s, inlining embedded structs as
+// encoding/json does.
+func addFields(s *Schema, t reflect.Type, defs map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, defs)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = schemaOf(f.Type, defs)
+	}
+}
+
+// definition returns the schema a reference refers to.
+func (s *Schema) definition(ref string) *Schema {
+	return s.Definitions[strings.TrimPrefix(ref, definitionsPrefix)]
+}
+
+// propertyNames returns the names of the properties of s,
+// sorted.
+func (s *Schema) propertyNames() []string {
+	var names []string
+	for n := range s.Properties {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
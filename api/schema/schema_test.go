@@ -0,0 +1,172 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKustomizationSchema(t *testing.T) {
+	s := Kustomization()
+	if s.Schema != Draft || len(s.OneOf) != 2 {
+		t.Fatalf("unexpected schema: %v", s)
+	}
+	k := s.Definitions["Kustomization"]
+	for name, expected := range map[string]*Schema{
+		"namePrefix": {Type: "string"},
+		"kind":       {Type: "string", Enum: []string{"Kustomization"}},
+		"commonLabels": {
+			Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+		"patchesStrategicMerge": {
+			Type: "array", Items: &Schema{Type: "string"}},
+		"images": {
+			Type: "array", Items: &Schema{Ref: "#/definitions/Image"}},
+		"resources": {
+			Type: "array", Items: &Schema{OneOf: []*Schema{
+				{Ref: "#/definitions/PathEntry"}, {Type: "string"}}}},
+	} {
+		if !reflect.DeepEqual(k.Properties[name], expected) {
+			t.Errorf("expected %s to be %v, got %v", name, expected, k.Properties[name])
+		}
+	}
+	if k.Required != nil {
+		t.Errorf("unexpected required fields %v", k.Required)
+	}
+	c := s.Definitions["Component"]
+	if c.Properties["kind"].Enum[0] != "Component" ||
+		!reflect.DeepEqual(c.Required, []string{"kind"}) {
+		t.Errorf("unexpected Component schema %v", c)
+	}
+	// Embedded structs are inlined.
+	sel := s.Definitions["Selector"]
+	if sel.Properties["kind"] == nil || sel.Properties["Gvk"] != nil {
+		t.Errorf("unexpected Selector schema %v", sel)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var generic map[string]interface{}
+	if err = json.Unmarshal(b, &generic); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if generic["definitions"].(map[string]interface{})["Image"].(map[string]interface{})["additionalProperties"] != false {
+		t.Errorf("expected structs to be closed")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	testCases := map[string]struct {
+		content  string
+		expected string
+	}{
+		"valid": {
+			content: `
+namePrefix: p-
+commonLabels:
+  app: web
+resources:
+- a.yaml
+images:
+- name: nginx
+  newTag: "1.19"
+replicas:
+- name: web
+  count: 3
+`,
+		},
+		"entries with when expressions": {
+			content: `
+resources:
+- a.yaml
+- path: monitoring
+  when: env == prod
+components:
+- path: debug
+  when: env == dev
+`,
+		},
+		"entry with unknown field": {
+			content: `
+resources:
+- path: monitoring
+  if: env == prod
+`,
+			expected: "line 4: resources[0].if: unknown field",
+		},
+		"empty": {},
+		"null field": {
+			content: "resources:\n",
+		},
+		"valid component": {
+			content: `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+namePrefix: p-
+`,
+		},
+		"misspelled": {
+			content: `
+commonLabel:
+  app: web
+`,
+			expected: "line 2: commonLabel: unknown field; did you mean 'commonLabels'?",
+		},
+		"wrong case": {
+			content: `
+nameprefix: p-
+`,
+			expected: "line 2: nameprefix: unknown field; did you mean 'namePrefix'?",
+		},
+		"wrong shape": {
+			content: `
+patchesStrategicMerge:
+- path: patch.yaml
+images:
+- name: nginx
+  newTag: 1.19
+`,
+			expected: "line 3: patchesStrategicMerge[0]: expected string, got object\n" +
+				"line 6: images[0].newTag: expected string, got number",
+		},
+		"unknown nested": {
+			content: `
+replicas:
+- name: web
+  replicas: 3
+`,
+			expected: "line 4: replicas[0].replicas: unknown field",
+		},
+		"wrong kind": {
+			content: `
+kind: Kustomisation
+`,
+			expected: "line 2: kind: expected 'Kustomization'",
+		},
+		"component in wrong version": {
+			content: `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Component
+`,
+			expected: "line 2: apiVersion: expected 'kustomize.config.k8s.io/v1alpha1'",
+		},
+	}
+	s := Kustomization()
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := s.Check([]byte(tc.content))
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("expected error:\n%s\ngot:\n%v", tc.expected, err)
+			}
+		})
+	}
+}
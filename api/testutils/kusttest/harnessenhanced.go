@@ -72,6 +72,14 @@ func (th *HarnessEnhanced) Reset() {
 	th.pte.reset()
 }
 
+// EnableHelm allows plugins to inflate helm charts with the
+// given helm command.
+func (th *HarnessEnhanced) EnableHelm(command string) *HarnessEnhanced {
+	th.pl.Config().HelmConfig = types.HelmConfig{
+		Enabled: true, Command: command}
+	return th
+}
+
 func (th *HarnessEnhanced) PrepBuiltin(k string) *HarnessEnhanced {
 	return th.BuildGoPlugin(konfig.BuiltinPluginPackage, "", k)
 }
@@ -119,6 +127,21 @@ func (th *HarnessEnhanced) LoadAndRunGenerator(
 	return rm
 }
 
+func (th *HarnessEnhanced) ErrorFromLoadAndRunGenerator(
+	config string) error {
+	res, err := th.rf.RF().FromBytes([]byte(config))
+	if err != nil {
+		th.t.Fatalf("Err: %v", err)
+	}
+	g, err := th.pl.LoadGenerator(
+		th.ldr, valtest_test.MakeFakeValidator(), res)
+	if err != nil {
+		return err
+	}
+	_, err = g.Generate()
+	return err
+}
+
 func (th *HarnessEnhanced) LoadAndRunTransformer(
 	config, input string) resmap.ResMap {
 	resMap, err := th.RunTransformer(config, input)
@@ -0,0 +1,54 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// HelmChartArgs contains the metadata of how to inflate a
+// helm chart with a local helm binary.
+type HelmChartArgs struct {
+	// ChartName is the name of the chart, e.g. 'minecraft'.
+	ChartName string `json:"chartName,omitempty" yaml:"chartName,omitempty"`
+
+	// ChartVersion, if set, selects the archive
+	// '<chartName>-<chartVersion>.tgz' in ChartHome, as found in
+	// a local copy of a chart repository, rather than the chart
+	// directory '<chartName>'.
+	ChartVersion string `json:"chartVersion,omitempty" yaml:"chartVersion,omitempty"`
+
+	// ChartHome is the directory, relative to the kustomization
+	// root, holding the chart.  Defaults to 'charts'.  It must
+	// be within the root, and the chart is read through the
+	// loader, subject to its load restrictions.
+	ChartHome string `json:"chartHome,omitempty" yaml:"chartHome,omitempty"`
+
+	// ReleaseName is passed to 'helm template'.
+	// Defaults to 'release-name'.
+	ReleaseName string `json:"releaseName,omitempty" yaml:"releaseName,omitempty"`
+
+	// ReleaseNamespace, if set, is passed to 'helm template'.
+	ReleaseNamespace string `json:"releaseNamespace,omitempty" yaml:"releaseNamespace,omitempty"`
+
+	// ValuesFiles are values files, relative to the
+	// kustomization root, passed to helm in order.
+	ValuesFiles []string `json:"valuesFiles,omitempty" yaml:"valuesFiles,omitempty"`
+
+	// ValuesInline are values that override those in ValuesFiles.
+	ValuesInline map[string]interface{} `json:"valuesInline,omitempty" yaml:"valuesInline,omitempty"`
+
+	// IncludeCRDs asks helm to include the chart's CRDs
+	// in the output.
+	IncludeCRDs bool `json:"includeCRDs,omitempty" yaml:"includeCRDs,omitempty"`
+}
+
+// HelmConfig holds the options for running helm.  They're
+// set by whoever runs kustomize, never by a kustomization,
+// since inflating a chart runs a local program.
+type HelmConfig struct {
+	// Enabled allows the helmCharts field, and the
+	// HelmChartInflationGenerator plugin, to be used.
+	Enabled bool
+
+	// Command is the helm command to run.  Defaults to
+	// 'helm', found on the PATH.
+	Command string
+}
@@ -18,4 +18,25 @@ type Image struct {
 	// Digest is the value used to replace the original image tag.
 	// If digest is present NewTag value is ignored.
 	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// TagPolicy picks the new tag from the tags of the image
+	// in the kustomization's image index: "semver:<range>",
+	// e.g. "semver:^1.2", picks the highest tag in the range.
+	TagPolicy string `json:"tagPolicy,omitempty" yaml:"tagPolicy,omitempty"`
+
+	// DigestFrom, if "file", replaces the tag with its digest
+	// in the kustomization's image index.
+	DigestFrom string `json:"digestFrom,omitempty" yaml:"digestFrom,omitempty"`
+}
+
+// ImageIndex is a file of the approved tags of images and
+// their digests, e.g. an images.lock.yaml file, as read by
+// imagetag.ParseIndex.
+type ImageIndex struct {
+	// Path of the file.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Strict, if true, fails the build if the image of a
+	// container in its output isn't in the index.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
 }
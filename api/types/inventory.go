@@ -3,10 +3,24 @@
 
 package types
 
+const (
+	// InventoryTypeConfigMap is the default type of
+	// inventory object: a ConfigMap.
+	InventoryTypeConfigMap = "ConfigMap"
+	// InventoryTypeResourceGroup is an inventory object
+	// that's a ResourceGroup custom resource.
+	InventoryTypeResourceGroup = "ResourceGroup"
+)
+
 // Inventory records all objects touched in a build operation.
 type Inventory struct {
+	// Type of the inventory object: ConfigMap (the default)
+	// or ResourceGroup.
 	Type      string   `json:"type,omitempty" yaml:"type,omitempty"`
 	ConfigMap NameArgs `json:"configMap,omitempty" yaml:"configMap,omitempty"`
+	// Name and namespace of the inventory object, if
+	// it's a ResourceGroup.
+	ResourceGroup NameArgs `json:"resourceGroup,omitempty" yaml:"resourceGroup,omitempty"`
 }
 
 // NameArgs holds both namespace and name.
@@ -57,10 +57,25 @@ type Kustomization struct {
 	// patch, but this operator is simpler to specify.
 	Images []Image `json:"images,omitempty" yaml:"images,omitempty"`
 
+	// ImageIndex is the image index file that resolves the
+	// tagPolicy and digestFrom of Images.
+	ImageIndex *ImageIndex `json:"imageIndex,omitempty" yaml:"imageIndex,omitempty"`
+
 	// Replicas is a list of {resourcename, count} that allows for simpler replica
 	// specification. This can also be done with a patch.
 	Replicas []Replica `json:"replicas,omitempty" yaml:"replicas,omitempty"`
 
+	// Scaling is a list of replica counts, container compute
+	// resources and autoscaler bounds, each for the resources
+	// that a selector selects.
+	Scaling []Scaling `json:"scaling,omitempty" yaml:"scaling,omitempty"`
+
+	// RolloutOnChange, if true, annotates the pod templates of
+	// workloads with a checksum of the ConfigMaps and Secrets
+	// that they mount, or take the environment of containers
+	// from, so that changing those rolls the workloads.
+	RolloutOnChange bool `json:"rolloutOnChange,omitempty" yaml:"rolloutOnChange,omitempty"`
+
 	// Vars allow things modified by kustomize to be injected into a
 	// kubernetes object specification. A var is a name (e.g. FOO) associated
 	// with a field in a specific resource instance.  The field must
@@ -70,6 +85,16 @@ type Kustomization struct {
 	// value of the specified field has been determined.
 	Vars []Var `json:"vars,omitempty" yaml:"vars,omitempty"`
 
+	// Replacements is a list of replacements, each copying a value
+	// from a field of one resource (or from a literal) into fields
+	// of the selected resources.  Unlike Vars, replacements need
+	// no "$(FOO)" placeholders in the target resources.
+	Replacements []Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+
+	// Validations are rules that the resources of the
+	// build output must follow.
+	Validations []Validation `json:"validations,omitempty" yaml:"validations,omitempty"`
+
 	//
 	// Operands - what kustomize operates on.
 	//
@@ -79,10 +104,31 @@ type Kustomization struct {
 	// via relative paths, absolute paths, or URLs.
 	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
 
+	// ResourceConditions holds the when expressions of
+	// entries of Resources, by path.  An entry whose
+	// expression doesn't hold for the build parameters is
+	// left out of the build.  It's read from, and written
+	// to, entries written as
+	//   - path: monitoring
+	//     when: env in (prod, staging)
+	ResourceConditions map[string]string `json:"-" yaml:"-"`
+
 	// Components specifies relative paths to specifications of other Components
 	// via relative paths, absolute paths, or URLs.
 	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
 
+	// ComponentConditions holds the when expressions of
+	// entries of Components, by path, as ResourceConditions
+	// does for Resources.
+	ComponentConditions map[string]string `json:"-" yaml:"-"`
+
+	// Params are the defaults of the build parameters that
+	// the when expressions of this kustomization, and of its
+	// bases and components, refer to.  Parameters given to
+	// the build, and the params of kustomizations using this
+	// one, override them.
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+
 	// Crds specifies relative paths to Custom Resource Definition files.
 	// This allows custom resources to be recognized as operands, making
 	// it possible to add them to the Resources list.
@@ -112,6 +158,12 @@ type Kustomization struct {
 	// the map will have a suffix hash generated from its contents.
 	SecretGenerator []SecretArgs `json:"secretGenerator,omitempty" yaml:"secretGenerator,omitempty"`
 
+	// HelmCharts is a list of helm charts to inflate with a
+	// local helm binary (one 'helm template' run per item).
+	// The resulting resources are normal operands, subject to
+	// name prefixing, patching, etc.
+	HelmCharts []HelmChartArgs `json:"helmCharts,omitempty" yaml:"helmCharts,omitempty"`
+
 	// GeneratorOptions modify behavior of all ConfigMap and Secret generators.
 	GeneratorOptions *GeneratorOptions `json:"generatorOptions,omitempty" yaml:"generatorOptions,omitempty"`
 
@@ -124,6 +176,10 @@ type Kustomization struct {
 	// Transformers is a list of files containing transformers
 	Transformers []string `json:"transformers,omitempty" yaml:"transformers,omitempty"`
 
+	// Validators is a list of files containing validators,
+	// which may fail the build but may not change resources
+	Validators []string `json:"validators,omitempty" yaml:"validators,omitempty"`
+
 	// Inventory appends an object that contains the record
 	// of all other objects, which can be used in apply, prune and delete
 	Inventory *Inventory `json:"inventory,omitempty" yaml:"inventory,omitempty"`
@@ -0,0 +1,44 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+const (
+	KustomizationLockVersion = "kustomize.config.k8s.io/v1alpha1"
+	KustomizationLockKind    = "KustomizationLock"
+)
+
+// KustomizationLock records the versions of the remote bases
+// and files reached from a kustomization, so that later builds
+// fetch (or insist on) the same versions.
+type KustomizationLock struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Remotes are sorted by Url.
+	Remotes []LockedRemote `json:"remotes,omitempty" yaml:"remotes,omitempty"`
+}
+
+// LockedRemote is the version of one remote.
+type LockedRemote struct {
+	// Url identifies the remote as written in a kustomization,
+	// less any path within it, e.g. github.com/org/repo?ref=main
+	Url string `json:"url" yaml:"url"`
+
+	// Commit is the commit fetched from a git repository.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
+	// Sum is a hash of the content fetched from other
+	// remotes, e.g. sha256:4c5f...
+	Sum string `json:"sum,omitempty" yaml:"sum,omitempty"`
+}
+
+// FixKustomizationLockPostUnmarshalling fills in the
+// type meta of a lock.
+func (l *KustomizationLock) FixKustomizationLockPostUnmarshalling() {
+	if l.Kind == "" {
+		l.Kind = KustomizationLockKind
+	}
+	if l.APIVersion == "" {
+		l.APIVersion = KustomizationLockVersion
+	}
+}
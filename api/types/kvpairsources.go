@@ -28,4 +28,55 @@ type KvPairSources struct {
 	// or npm ".env" file or a ".ini" file
 	// (wikipedia.org/wiki/INI_file)
 	EnvSources []string `json:"envs,omitempty" yaml:"envs,omitempty"`
+
+	// StructuredSources is a list of JSON or YAML file
+	// sources, of the form [{prefix}=]{path}.  Each leaf
+	// of the file's content becomes a pair, keyed by the
+	// path to the leaf, its parts joined by KeySeparator,
+	// after the prefix if any.  E.g. the field "host" of
+	// the object "db" has the key "db.host".  List items
+	// are keyed by their index.
+	StructuredSources []string `json:"structured,omitempty" yaml:"structured,omitempty"`
+
+	// DirSources is a list of directory trees, each file
+	// of which becomes a pair, keyed by its path below the
+	// directory, its parts joined by KeySeparator.
+	DirSources []DirSource `json:"dirs,omitempty" yaml:"dirs,omitempty"`
+
+	// KeySeparator joins the parts of the keys of pairs
+	// from StructuredSources and DirSources.  Defaults to ".".
+	KeySeparator string `json:"keySeparator,omitempty" yaml:"keySeparator,omitempty"`
+}
+
+// DirSource is a directory tree to obtain key value
+// pairs from.
+type DirSource struct {
+	// Path of the directory.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Include, if not empty, limits the files to those
+	// matching one of these glob patterns.  A pattern
+	// without a "/" matches basenames; others match paths
+	// below the directory, and may end in "/**" to match
+	// everything below a subdirectory.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+
+	// Exclude drops the files matching any of these glob
+	// patterns, which are as those of Include.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// EncryptedKvPairSources defines places to obtain key
+// value pairs from files encrypted with age, which are
+// decrypted at build time.
+type EncryptedKvPairSources struct {
+	// EncryptedFileSources is a list of encrypted file
+	// sources, of the form of FileSources.  A key
+	// defaulting to the basename of the path drops any
+	// ".age" extension.
+	EncryptedFileSources []string `json:"encryptedFiles,omitempty" yaml:"encryptedFiles,omitempty"`
+
+	// EncryptedEnvSources is a list of paths of encrypted
+	// files whose plaintext is as that of EnvSources.
+	EncryptedEnvSources []string `json:"encryptedEnvs,omitempty" yaml:"encryptedEnvs,omitempty"`
 }
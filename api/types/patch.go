@@ -16,4 +16,8 @@ type Patch struct {
 
 	// Target points to the resources that the patch is applied to
 	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// When is an expression over the build parameters.
+	// The patch is only applied if it holds.
+	When string `json:"when,omitempty" yaml:"when,omitempty"`
 }
@@ -0,0 +1,155 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PathEntry is an entry of the resources or components
+// of a kustomization: a path, and maybe a when expression
+// over the build parameters.  An entry whose expression
+// doesn't hold is left out of the build.
+//
+// An entry without an expression is written as its path,
+//
+//   - deployment.yaml
+//
+// else as an object:
+//
+//   - path: monitoring
+//     when: env in (prod, staging)
+type PathEntry struct {
+	Path string `json:"path" yaml:"path"`
+	When string `json:"when,omitempty" yaml:"when,omitempty"`
+}
+
+// pathEntryFields is PathEntry less its methods.
+type pathEntryFields PathEntry
+
+func (e PathEntry) MarshalJSON() ([]byte, error) {
+	if e.When == "" {
+		return json.Marshal(e.Path)
+	}
+	return json.Marshal(pathEntryFields(e))
+}
+
+func (e *PathEntry) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*e = PathEntry{}
+		return json.Unmarshal(data, &e.Path)
+	}
+	var f pathEntryFields
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		if strings.HasPrefix(err.Error(), "json: unknown field") {
+			return fmt.Errorf("%s in entry; entries have a path and a when expression",
+				strings.TrimPrefix(err.Error(), "json: "))
+		}
+		return err
+	}
+	if f.Path == "" {
+		return fmt.Errorf("entry has no path")
+	}
+	*e = PathEntry(f)
+	return nil
+}
+
+// kustomizationFields is Kustomization less its methods.
+type kustomizationFields Kustomization
+
+// kustomizationEntries is how a Kustomization is
+// written: its resources and components as entries,
+// with their when expressions.
+type kustomizationEntries struct {
+	kustomizationFields
+	Resources  []PathEntry `json:"resources,omitempty"`
+	Components []PathEntry `json:"components,omitempty"`
+}
+
+func (k Kustomization) MarshalJSON() ([]byte, error) {
+	if len(k.ResourceConditions) == 0 && len(k.ComponentConditions) == 0 {
+		return json.Marshal(kustomizationFields(k))
+	}
+	return json.Marshal(kustomizationEntries{
+		kustomizationFields: kustomizationFields(k),
+		Resources:           entriesOf(k.Resources, k.ResourceConditions),
+		Components:          entriesOf(k.Components, k.ComponentConditions),
+	})
+}
+
+func (k *Kustomization) UnmarshalJSON(data []byte) error {
+	return k.unmarshalJSON(data, false)
+}
+
+// UnmarshalStrictJSON is UnmarshalJSON, failing on
+// fields that the Kustomization doesn't have.
+func (k *Kustomization) UnmarshalStrictJSON(data []byte) error {
+	return k.unmarshalJSON(data, true)
+}
+
+func (k *Kustomization) unmarshalJSON(data []byte, strict bool) error {
+	e := kustomizationEntries{kustomizationFields: kustomizationFields(*k)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(&e); err != nil {
+		return err
+	}
+	resources, resourceConditions, err := pathsOf(e.Resources)
+	if err != nil {
+		return err
+	}
+	components, componentConditions, err := pathsOf(e.Components)
+	if err != nil {
+		return err
+	}
+	*k = Kustomization(e.kustomizationFields)
+	if e.Resources != nil {
+		k.Resources, k.ResourceConditions = resources, resourceConditions
+	}
+	if e.Components != nil {
+		k.Components, k.ComponentConditions = components, componentConditions
+	}
+	return nil
+}
+
+// entriesOf returns entries of the paths, with their
+// when expressions.
+func entriesOf(paths []string, conditions map[string]string) []PathEntry {
+	var result []PathEntry
+	for _, p := range paths {
+		result = append(result, PathEntry{Path: p, When: conditions[p]})
+	}
+	return result
+}
+
+// pathsOf returns the paths of the entries, and their
+// when expressions by path, if any.
+func pathsOf(entries []PathEntry) ([]string, map[string]string, error) {
+	var paths []string
+	var conditions map[string]string
+	whens := make(map[string]string)
+	for _, e := range entries {
+		if w, ok := whens[e.Path]; ok && w != e.When {
+			return nil, nil, fmt.Errorf(
+				"conflicting when expressions for path '%s'", e.Path)
+		}
+		whens[e.Path] = e.When
+		paths = append(paths, e.Path)
+		if e.When == "" {
+			continue
+		}
+		if conditions == nil {
+			conditions = make(map[string]string)
+		}
+		conditions[e.Path] = e.When
+	}
+	return paths, conditions, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	. "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPathEntryRoundTrip(t *testing.T) {
+	content := `components:
+- path: debug
+  when: env == dev
+resources:
+- deployment.yaml
+- path: monitoring
+  when: env in (prod, staging)
+`
+	var k Kustomization
+	if err := yaml.Unmarshal([]byte(content), &k); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Kustomization{
+		Resources: []string{"deployment.yaml", "monitoring"},
+		ResourceConditions: map[string]string{
+			"monitoring": "env in (prod, staging)",
+		},
+		Components:          []string{"debug"},
+		ComponentConditions: map[string]string{"debug": "env == dev"},
+	}
+	if !reflect.DeepEqual(k, expected) {
+		t.Fatalf("expected %v, got %v", expected, k)
+	}
+	b, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != content {
+		t.Fatalf("expected:\n%s\ngot:\n%s", content, b)
+	}
+}
+
+func TestPathEntryStrings(t *testing.T) {
+	k := Kustomization{Resources: []string{"a.yaml", "b"}}
+	b, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != "resources:\n- a.yaml\n- b\n" {
+		t.Fatalf("unexpected content:\n%s", b)
+	}
+	var result Kustomization
+	if err = yaml.Unmarshal(b, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, k) {
+		t.Fatalf("expected %v, got %v", k, result)
+	}
+}
+
+func TestPathEntryStrict(t *testing.T) {
+	var k Kustomization
+	if err := k.UnmarshalJSON([]byte(`{"resourcez": ["a.yaml"]}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := k.UnmarshalStrictJSON([]byte(`{"resourcez": ["a.yaml"]}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown field "resourcez"`) {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestPathEntryErrors(t *testing.T) {
+	for content, expected := range map[string]string{
+		`
+resources:
+- when: env == prod
+`: "entry has no path",
+		`
+components:
+- path: debug
+  if: env == dev
+`: `unknown field "if" in entry; entries have a path and a when expression`,
+		`
+resources:
+- monitoring
+- path: monitoring
+  when: env == prod
+`: "conflicting when expressions for path 'monitoring'",
+	} {
+		var k Kustomization
+		err := yaml.Unmarshal([]byte(content), &k)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error %q, got %v", expected, err)
+		}
+	}
+}
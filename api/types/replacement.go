@@ -3,6 +3,8 @@
 
 package types
 
+const defaultReplacementFieldRef = "metadata.name"
+
 // Replacement defines how to perform a substitution
 // where it is from and where it is to.
 type Replacement struct {
@@ -16,12 +18,40 @@ type Replacement struct {
 //  - from a string
 type ReplSource struct {
 	ObjRef   *Target `json:"objref,omitempty" yaml:"objref,omitempty"`
-	FieldRef string  `json:"fieldref,omitempty" yaml:"fiedldref,omitempty"`
+	FieldRef string  `json:"fieldref,omitempty" yaml:"fieldref,omitempty"`
 	Value    string  `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Options, if present, select a part of the source value.
+	Options *FieldOptions `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 // ReplTarget defines where a substitution is to.
 type ReplTarget struct {
 	ObjRef    *Selector `json:"objref,omitempty" yaml:"objref,omitempty"`
 	FieldRefs []string  `json:"fieldrefs,omitempty" yaml:"fieldrefs,omitempty"`
+
+	// Options, if present, select the part of each target
+	// field value to replace, and whether to create it.
+	Options *FieldOptions `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// FieldOptions refine the interpretation of a field value.
+type FieldOptions struct {
+	// Delimiter splits the field value into parts.
+	Delimiter string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+
+	// Index selects which part of the split value to use.
+	Index int `json:"index,omitempty" yaml:"index,omitempty"`
+
+	// Create the field if it is missing.
+	Create bool `json:"create,omitempty" yaml:"create,omitempty"`
+}
+
+// FieldRefOrDefault returns the field reference of the
+// source, defaulting to the name of the referenced object.
+func (s *ReplSource) FieldRefOrDefault() string {
+	if s.FieldRef == "" {
+		return defaultReplacementFieldRef
+	}
+	return s.FieldRef
 }
@@ -0,0 +1,45 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Scaling sets the replica count, the compute resources of
+// containers, and the autoscaler bounds of the resources
+// that its selector selects.  It's meant to replace the
+// patches that overlays for environments mostly consist of.
+type Scaling struct {
+	// Selector selects the resources to scale.
+	Selector Selector `json:"selector,omitempty" yaml:"selector,omitempty"`
+
+	// Replicas is the replica count of the selected
+	// workloads.
+	Replicas *int64 `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+
+	// Resources are the compute resources of containers of
+	// the selected workloads, by container name.
+	Resources []ContainerResources `json:"resources,omitempty" yaml:"resources,omitempty"`
+
+	// MinReplicas and MaxReplicas are the bounds of the
+	// selected HorizontalPodAutoscalers, and of those
+	// scaling a selected workload.
+	MinReplicas *int64 `json:"minReplicas,omitempty" yaml:"minReplicas,omitempty"`
+	MaxReplicas *int64 `json:"maxReplicas,omitempty" yaml:"maxReplicas,omitempty"`
+}
+
+// ContainerResources are the compute resources of the
+// containers, and init containers, with a name.
+type ContainerResources struct {
+	// Container is the name of the containers.
+	Container string `json:"container,omitempty" yaml:"container,omitempty"`
+
+	Requests *ResourceQuantities `json:"requests,omitempty" yaml:"requests,omitempty"`
+	Limits   *ResourceQuantities `json:"limits,omitempty" yaml:"limits,omitempty"`
+}
+
+// ResourceQuantities are amounts of CPU and memory, as
+// Kubernetes quantities, e.g. "250m" and "64Mi".  An empty
+// amount is left as it is.
+type ResourceQuantities struct {
+	CPU    string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
@@ -8,6 +8,10 @@ type SecretArgs struct {
 	// GeneratorArgs for the secret.
 	GeneratorArgs `json:",inline,omitempty" yaml:",inline,omitempty"`
 
+	// EncryptedKvPairSources for the secret, besides
+	// the plaintext ones of its GeneratorArgs.
+	EncryptedKvPairSources `json:",inline,omitempty" yaml:",inline,omitempty"`
+
 	// Type of the secret.
 	//
 	// This is the same field as the secret type field in v1/Secret:
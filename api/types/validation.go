@@ -0,0 +1,57 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Validation is a rule that the resources of the build
+// output must follow.  Each has exactly one of the rule
+// fields.
+type Validation struct {
+	// Name names the rule in failures; by default, the
+	// name of its rule field.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Target selects the resources that the rule applies
+	// to; all of them if nil.
+	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// RequiredLabel is a label that resources must have.
+	RequiredLabel string `json:"requiredLabel,omitempty" yaml:"requiredLabel,omitempty"`
+
+	// RequiredAnnotation is an annotation that resources
+	// must have.
+	RequiredAnnotation string `json:"requiredAnnotation,omitempty" yaml:"requiredAnnotation,omitempty"`
+
+	// ForbiddenValue is a field that resources mustn't
+	// have certain values in.
+	ForbiddenValue *ForbiddenValue `json:"forbiddenValue,omitempty" yaml:"forbiddenValue,omitempty"`
+
+	// AllowedRegistries are the registries, or registry
+	// paths, that the images of containers must be from,
+	// e.g. "gcr.io/my-project".  Images without a registry
+	// are from docker.io, and those of one name component
+	// from docker.io/library, e.g. nginx.
+	AllowedRegistries []string `json:"allowedRegistries,omitempty" yaml:"allowedRegistries,omitempty"`
+
+	// RequiredLimits are the resources, e.g. cpu and memory,
+	// that containers must have limits for.
+	RequiredLimits []string `json:"requiredLimits,omitempty" yaml:"requiredLimits,omitempty"`
+
+	// UniqueField is the path of a field, e.g.
+	// spec/ports/nodePort, that no two resources may have
+	// the same value in.
+	UniqueField string `json:"uniqueField,omitempty" yaml:"uniqueField,omitempty"`
+}
+
+// ForbiddenValue is a field, and the values that it
+// mustn't have.
+type ForbiddenValue struct {
+	// Path is the path of the field, e.g.
+	// spec/template/spec/hostNetwork, descending into
+	// the items of lists.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Values are the forbidden values, as written in YAML,
+	// e.g. "true".
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
+}
@@ -4,14 +4,18 @@
 package commands
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
 	"sigs.k8s.io/kustomize/kyaml/runfn"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -54,9 +58,19 @@ func GetRunFnRunner(name string) *RunFnRunner {
 		&r.StarURL, "star-url", "", "run a starlark script as a function. (Alpha)")
 	r.Command.Flags().StringVar(
 		&r.StarName, "star-name", "", "name of starlark program. (Alpha)")
+	r.Command.Flags().BoolVar(
+		&r.EnableWasm, "enable-wasm", false, "enable support for WebAssembly functions. (Alpha)")
+	r.Command.Flags().StringVar(
+		&r.WasmPath, "wasm-path", "", "run a WebAssembly module as a function. (Alpha)")
 
 	r.Command.Flags().StringVar(
 		&r.ResultsDir, "results-dir", "", "write function results to this dir")
+	r.Command.Flags().StringVar(
+		&r.ResultsFormat, "results-format", "",
+		"write a report of all function results to stderr as a table, json or sarif")
+	r.Command.Flags().StringVar(
+		&r.SeverityThreshold, "severity-threshold", "",
+		"fail if any function reports a result of this severity or higher: error, warning or info")
 
 	r.Command.Flags().BoolVar(
 		&r.Network, "network", false, "enable network access for functions that declare it")
@@ -86,8 +100,12 @@ type RunFnRunner struct {
 	StarName           string
 	EnableExec         bool
 	ExecPath           string
+	EnableWasm         bool
+	WasmPath           string
 	RunFns             runfn.RunFns
 	ResultsDir         string
+	ResultsFormat      string
+	SeverityThreshold  string
 	Network            bool
 	NetworkName        string
 	Mounts             []string
@@ -102,7 +120,8 @@ func (r *RunFnRunner) runE(c *cobra.Command, args []string) error {
 func (r *RunFnRunner) getContainerFunctions(c *cobra.Command, args, dataItems []string) (
 	[]*yaml.RNode, error) {
 
-	if r.Image == "" && r.StarPath == "" && r.ExecPath == "" && r.StarURL == "" {
+	if r.Image == "" && r.StarPath == "" && r.ExecPath == "" && r.StarURL == "" &&
+		r.WasmPath == "" {
 		return nil, nil
 	}
 
@@ -173,6 +192,26 @@ func (r *RunFnRunner) getContainerFunctions(c *cobra.Command, args, dataItems []
 		if err != nil {
 			return nil, err
 		}
+	} else if r.EnableWasm && r.WasmPath != "" {
+		// create the function spec to set as an annotation
+		fn, err = yaml.Parse(`wasm: {}`)
+		if err != nil {
+			return nil, err
+		}
+
+		// embed the module, since paths in function specs must be relative
+		// to the function config, which the commandline doesn't have
+		b, err := ioutil.ReadFile(r.WasmPath)
+		if err != nil {
+			return nil, err
+		}
+		err = fn.PipeE(
+			yaml.Lookup("wasm"),
+			yaml.SetField("module", yaml.NewScalarRNode(
+				base64.StdEncoding.EncodeToString(b))))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// create the function config
@@ -253,8 +292,13 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 		return errors.Errorf("must specify --enable-exec with --exec-path")
 	}
 
+	if !r.EnableWasm && r.WasmPath != "" {
+		return errors.Errorf("must specify --enable-wasm with --wasm-path")
+	}
+
 	if c.ArgsLenAtDash() >= 0 && r.Image == "" &&
-		!(r.EnableStar && (r.StarPath != "" || r.StarURL != "")) && !(r.EnableExec && r.ExecPath != "") {
+		!(r.EnableStar && (r.StarPath != "" || r.StarURL != "")) && !(r.EnableExec && r.ExecPath != "") &&
+		!(r.EnableWasm && r.WasmPath != "") {
 		return errors.Errorf("must specify --image")
 	}
 
@@ -293,18 +337,27 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	storageMounts := toStorageMounts(r.Mounts)
 
 	r.RunFns = runfn.RunFns{
-		FunctionPaths:  r.FnPaths,
-		GlobalScope:    r.GlobalScope,
-		Functions:      fns,
-		Output:         output,
-		Input:          input,
-		Path:           path,
-		Network:        r.Network,
-		NetworkName:    r.NetworkName,
-		EnableStarlark: r.EnableStar,
-		EnableExec:     r.EnableExec,
-		StorageMounts:  storageMounts,
-		ResultsDir:     r.ResultsDir,
+		FunctionPaths:     r.FnPaths,
+		GlobalScope:       r.GlobalScope,
+		Functions:         fns,
+		Output:            output,
+		Input:             input,
+		Path:              path,
+		Network:           r.Network,
+		NetworkName:       r.NetworkName,
+		EnableStarlark:    r.EnableStar,
+		EnableExec:        r.EnableExec,
+		EnableWasm:        r.EnableWasm,
+		StorageMounts:     storageMounts,
+		ResultsDir:        r.ResultsDir,
+		ResultsFormat:     r.ResultsFormat,
+		SeverityThreshold: framework.Severity(r.SeverityThreshold),
+	}
+	if r.EnableWasm {
+		r.RunFns.WasmFilterProvider = wasm.NewFilter
+	}
+	if r.ResultsFormat != "" {
+		r.RunFns.ResultsOutput = c.ErrOrStderr()
 	}
 
 	// don't consider args for the function
@@ -5,18 +5,30 @@ package commands
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/runfn"
 )
 
 // TestRunFnCommand_preRunE verifies that preRunE correctly parses the commandline
 // flags and arguments into the RunFns structure to be executed.
 func TestRunFnCommand_preRunE(t *testing.T) {
+	wasmFile, err := ioutil.TempFile("", "kustomize-test-*.wasm")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(wasmFile.Name())
+	_, err = wasmFile.Write([]byte("\x00asm\x01\x00\x00\x00"))
+	if !assert.NoError(t, err) || !assert.NoError(t, wasmFile.Close()) {
+		t.FailNow()
+	}
+
 	tests := []struct {
 		name           string
 		args           []string
@@ -205,6 +217,42 @@ apiVersion: v1
 				EnableStarlark: true,
 			},
 		},
+		{
+			name: "wasm",
+			args: []string{"run", "dir",
+				"--enable-wasm",
+				"--wasm-path", wasmFile.Name(),
+				"--", "Foo", "g=h"},
+			path: "dir",
+			expected: `
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      wasm: {module: AGFzbQEAAAA=}
+data: {g: h}
+kind: Foo
+apiVersion: v1
+`,
+		},
+		{
+			name: "wasm-not-enabled",
+			args: []string{"run", "dir",
+				"--wasm-path", wasmFile.Name(),
+				"--", "Foo", "g=h"},
+			path: "dir",
+			err:  "must specify --enable-wasm with --wasm-path",
+		},
+		{
+			name: "wasm-enabled",
+			args: []string{"run", "dir", "--enable-wasm"},
+			path: "dir",
+			expectedStruct: &runfn.RunFns{
+				Path:        "dir",
+				NetworkName: "bridge",
+				EnableWasm:  true,
+			},
+		},
 		{
 			name:          "function paths",
 			args:          []string{"run", "dir", "--fn-path", "path1", "--fn-path", "path2"},
@@ -268,6 +316,20 @@ kind: ConfigMap
 apiVersion: v1
 `,
 		},
+		{
+			name: "results report",
+			args: []string{"run", "dir",
+				"--results-format", "sarif",
+				"--severity-threshold", "warning"},
+			path: "dir",
+			expectedStruct: &runfn.RunFns{
+				Path:              "dir",
+				NetworkName:       "bridge",
+				ResultsFormat:     runfn.SARIFFormat,
+				ResultsOutput:     os.Stderr,
+				SeverityThreshold: framework.Warning,
+			},
+		},
 		{
 			name: "config map multi args",
 			args: []string{"run", "dir", "dir2", "--image", "foo:bar", "--", "a=b", "c=d", "e=f"},
@@ -333,6 +395,12 @@ apiVersion: v1
 				t.FailNow()
 			}
 
+			// functions can't be compared
+			if !assert.Equal(t, r.EnableWasm, r.RunFns.WasmFilterProvider != nil) {
+				t.FailNow()
+			}
+			r.RunFns.WasmFilterProvider = nil
+
 			// check if Network was set
 			if tt.network {
 				if !assert.Equal(t, tt.network, r.RunFns.Network) {
@@ -273,9 +273,23 @@ order they appear in the file).
   file contents.
 
   See ` + "`" + `kustomize help cfg docs-fn` + "`" + ` for more details on writing functions.
+
+#### Results:
+
+  Functions may emit results, e.g. validation errors, in ResourceList.results.
+  --results-format collects the results of all functions into one report, grouped by
+  function and sorted by severity, file and line, and writes it to stderr as a
+  table, as json or as sarif.
+
+  By default run fails if any function whose failure is deferred fails.
+  --severity-threshold instead fails run if any function reports a result of the
+  given severity -- error, warning or info -- or higher.  A function which fails
+  without reporting results counts as an error.
 `
 var RunFnsExamples = `
-kustomize fn run example/`
+kustomize fn run example/
+
+kustomize fn run example/ --results-format sarif --severity-threshold warning`
 
 var SetShort = `[Alpha] Set values on Resources fields values.`
 var SetLong = `
@@ -25,3 +25,5 @@ exclude (
 )
 
 replace sigs.k8s.io/kustomize/api => ../api
+
+replace sigs.k8s.io/kustomize/kyaml => ../kyaml
@@ -4,6 +4,8 @@
 package build
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"log"
 	"path/filepath"
@@ -25,6 +27,14 @@ type Options struct {
 	kustomizationPath string
 	outputPath        string
 	outOrder          reorderOutput
+
+	// If true, build every path in kustomizationPaths,
+	// sharing the work on their common bases.
+	multi              bool
+	kustomizationPaths []string
+
+	// Where validation warnings are written.
+	errOut io.Writer
 }
 
 // NewOptions creates a Options object
@@ -50,6 +60,34 @@ with a kustomization.yaml file, e.g.
 
 The URL should be formulated as described at
 https://github.com/hashicorp/go-getter#url-format
+
+Remote bases are fetched once per build.  To keep copies
+of them in a cache directory, reusing those fetched by
+earlier builds for up to an hour, run
+
+  kustomize build --cache-dir ~/.cache/kustomize/repos \
+    --cache-ttl 1h someDir
+
+To build with the remote bases recorded by 'kustomize edit lock',
+failing if any of them changed, run
+
+  kustomize build --locked someDir
+
+To report build errors as JSON, e.g. for an editor or CI
+annotations, run
+
+  kustomize build --error-format=json someDir
+
+To report resources breaking the validations of the
+kustomization without failing the build, run
+
+  kustomize build --warn-only someDir
+
+To check the output against the OpenAPI schemas of its
+kinds, reporting unknown fields, type mismatches, missing
+required fields and values outside enums, run
+
+  kustomize build --validate someDir
 `
 
 // NewCmdBuild creates a new build command.
@@ -66,7 +104,16 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return o.RunBuild(out)
+			o.errOut = cmd.ErrOrStderr()
+			err = o.RunBuild(out)
+			if err != nil && isFlagErrorFormatJSON() {
+				// The JSON replaces the usual message.
+				cmd.SilenceErrors = true
+				if errJ := writeJSONErrors(cmd.ErrOrStderr(), err); errJ != nil {
+					return errJ
+				}
+			}
+			return err
 		},
 	}
 
@@ -74,16 +121,43 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 		&o.outputPath,
 		"output", "o", "",
 		"If specified, write the build output to this path.")
+	cmd.Flags().BoolVar(
+		&o.multi,
+		"multi", false,
+		"Build every path given, writing each output to its own file in "+
+			"the --output directory, else to stdout.  A failed build "+
+			"doesn't stop the others.")
 	addFlagLoadRestrictor(cmd.Flags())
 	addFlagEnablePlugins(cmd.Flags())
 	addFlagReorderOutput(cmd.Flags())
 	addFlagEnableManagedbyLabel(cmd.Flags())
+	addFlagShowOrigins(cmd.Flags())
+	addFlagRepoCache(cmd.Flags())
+	addFlagLocked(cmd.Flags())
+	addFlagErrorFormat(cmd.Flags())
+	addFlagStrict(cmd.Flags())
+	addFlagParam(cmd.Flags())
+	addFlagWarnOnly(cmd.Flags())
+	addFlagValidate(cmd.Flags())
+	addFlagHelm(cmd.Flags())
 	return cmd
 }
 
 // Validate validates build command.
 func (o *Options) Validate(args []string) (err error) {
-	if len(args) > 1 {
+	if o.multi {
+		if len(args) == 0 {
+			return errors.New(
+				"specify at least one path to " +
+					konfig.DefaultKustomizationFileName())
+		}
+		if o.outputPath != "" {
+			if err = validateTargetFileNames(args); err != nil {
+				return err
+			}
+		}
+		o.kustomizationPaths = args
+	} else if len(args) > 1 {
 		return errors.New(
 			"specify one path to " +
 				konfig.DefaultKustomizationFileName())
@@ -97,6 +171,18 @@ func (o *Options) Validate(args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	err = validateFlagRepoCache()
+	if err != nil {
+		return err
+	}
+	err = validateFlagErrorFormat()
+	if err != nil {
+		return err
+	}
+	err = validateFlagParam()
+	if err != nil {
+		return err
+	}
 	o.outOrder, err = validateFlagReorderOutput()
 	return
 }
@@ -115,15 +201,33 @@ func (o *Options) makeOptions() *krusty.Options {
 	} else {
 		opts.PluginConfig = konfig.DisabledPluginConfig()
 	}
+	opts.PluginConfig.HelmConfig = types.HelmConfig{
+		Enabled: flagEnableHelmValue,
+		Command: flagHelmCommandValue,
+	}
 	if isManagedbyLabelEnabled() {
 		opts.AddManagedbyLabel = true
 	}
+	opts.TrackOrigins = isFlagShowOriginsSet()
+	opts.RepoCacheDir = flagCacheDirValue
+	opts.RepoCacheTTL = flagCacheTTLValue
+	opts.Locked = isFlagLockedSet()
+	opts.Strict = isFlagStrictSet()
+	opts.Params, _ = getFlagParamValue()
+	opts.Validate = isFlagValidateSet()
+	if isFlagWarnOnlySet() {
+		opts.ValidationWarnOnly = true
+		opts.ValidationWarnings = o.writeValidationWarnings
+	}
 	return opts
 }
 
 func (o *Options) RunBuild(out io.Writer) error {
 	fSys := filesys.MakeFsOnDisk()
 	k := krusty.MakeKustomizer(fSys, o.makeOptions())
+	if o.multi {
+		return o.emitManyResources(out, fSys, k.RunMany(o.kustomizationPaths))
+	}
 	m, err := k.Run(o.kustomizationPath)
 	if err != nil {
 		return err
@@ -147,6 +251,98 @@ func (o *Options) emitResources(
 	return err
 }
 
+// emitManyResources writes the output of each build to
+// its own file in the output directory, if any, else to out,
+// each output preceded by a comment naming its path.
+func (o *Options) emitManyResources(
+	out io.Writer, fSys filesys.FileSystem, results []krusty.RunResult) error {
+	if o.outputPath != "" {
+		if err := fSys.MkdirAll(o.outputPath); err != nil {
+			return err
+		}
+	}
+	failures := &buildFailures{total: len(results)}
+	for i, r := range results {
+		if r.Err != nil {
+			failures.add(r.Path, r.Err)
+			continue
+		}
+		res, err := r.ResMap.AsYaml()
+		if err != nil {
+			failures.add(r.Path, err)
+			continue
+		}
+		if o.outputPath != "" {
+			err = fSys.WriteFile(
+				filepath.Join(o.outputPath, targetFileName(r.Path)), res)
+		} else {
+			var b bytes.Buffer
+			if i > 0 {
+				b.WriteString("---\n")
+			}
+			b.WriteString("# Source: " + r.Path + "\n")
+			b.Write(res)
+			_, err = out.Write(b.Bytes())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(failures.errs) > 0 {
+		return failures
+	}
+	return nil
+}
+
+// buildFailures is the error of the failed builds of
+// emitManyResources.
+type buildFailures struct {
+	total int
+	paths []string
+	errs  []error
+}
+
+func (f *buildFailures) add(path string, err error) {
+	f.paths = append(f.paths, path)
+	f.errs = append(f.errs, err)
+}
+
+func (f *buildFailures) Error() string {
+	lines := make([]string, len(f.errs))
+	for i, err := range f.errs {
+		lines[i] = fmt.Sprintf("%s: %v", f.paths[i], err)
+	}
+	return fmt.Sprintf("%d of %d builds failed:\n  %s",
+		len(f.errs), f.total, strings.Join(lines, "\n  "))
+}
+
+// targetFileName returns the name of the file holding
+// the output of the build of the given path.
+func targetFileName(path string) string {
+	path = strings.Trim(filepath.ToSlash(filepath.Clean(path)), "/")
+	if path == "." || path == "" {
+		return "root.yaml"
+	}
+	return strings.ReplaceAll(path, "/", "_") + ".yaml"
+}
+
+// validateTargetFileNames fails if the outputs of the
+// builds of two of the paths would be written to the
+// same file, e.g. those of 'a/b' and 'a_b'.
+func validateTargetFileNames(paths []string) error {
+	seen := make(map[string]string, len(paths))
+	for _, path := range paths {
+		name := targetFileName(path)
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf(
+				"the outputs of '%s' and '%s' would both be written to %s",
+				other, path, name)
+		}
+		seen[name] = path
+	}
+	return nil
+}
+
 func writeIndividualFiles(
 	fSys filesys.FileSystem, folderPath string, m resmap.ResMap) error {
 	byNamespace := m.GroupedByCurrentNamespace()
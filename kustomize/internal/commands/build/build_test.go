@@ -4,10 +4,15 @@
 package build
 
 import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
 )
 
 func TestNewOptionsToSilenceCodeInspectionError(t *testing.T) {
@@ -52,3 +57,278 @@ func TestBuildValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildValidateMulti(t *testing.T) {
+	opts := Options{multi: true}
+	err := opts.Validate([]string{})
+	if err == nil || err.Error() != "specify at least one path to "+
+		konfig.DefaultKustomizationFileName() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts = Options{multi: true}
+	if err = opts.Validate([]string{"a", "b/c"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(opts.kustomizationPaths, []string{"a", "b/c"}) {
+		t.Fatalf("unexpected paths: %v", opts.kustomizationPaths)
+	}
+	opts = Options{multi: true, outputPath: "out"}
+	err = opts.Validate([]string{"a/b", "c", "a_b/"})
+	if err == nil || err.Error() !=
+		"the outputs of 'a/b' and 'a_b/' would both be written to a_b.yaml" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEmitManyResources(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/base/kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+`))
+	fSys.WriteFile("/app/base/cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	fSys.WriteFile("/app/overlays/dev/kustomization.yaml", []byte(`
+resources:
+- ../../base
+namePrefix: dev-
+`))
+	fSys.WriteFile("/app/overlays/bad/kustomization.yaml", []byte(`
+resources:
+- missing
+`))
+	results := krusty.MakeKustomizer(fSys, krusty.MakeDefaultOptions()).
+		RunMany([]string{"/app/base", "/app/overlays/bad", "/app/overlays/dev"})
+
+	var out bytes.Buffer
+	err := (&Options{}).emitManyResources(&out, fSys, results)
+	if err == nil || !strings.HasPrefix(err.Error(),
+		"1 of 3 builds failed:\n  /app/overlays/bad: ") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `# Source: /app/base
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+# Source: /app/overlays/dev
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dev-cm
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	err = (&Options{outputPath: "/out"}).emitManyResources(&out, fSys, results)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if out.Len() != 0 {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+	for path, expected := range map[string]string{
+		"/out/app_base.yaml":         "name: cm\n",
+		"/out/app_overlays_dev.yaml": "name: dev-cm\n",
+	} {
+		b, err := fSys.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasSuffix(string(b), expected) {
+			t.Fatalf("unexpected content of %s: %s", path, b)
+		}
+	}
+	if fSys.Exists("/out/app_overlays_bad.yaml") {
+		t.Fatalf("unexpected output of failed build")
+	}
+}
+
+func TestWriteJSONErrors(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/good/kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+`))
+	fSys.WriteFile("/app/good/cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	fSys.WriteFile("/app/bad/kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+`))
+	fSys.WriteFile("/app/bad/cm.yaml", []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+	name: cm
+`))
+	results := krusty.MakeKustomizer(fSys, krusty.MakeDefaultOptions()).
+		RunMany([]string{"/app/good", "/app/bad"})
+	var out bytes.Buffer
+	err := (&Options{}).emitManyResources(&out, fSys, results)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	out.Reset()
+	if err = writeJSONErrors(&out, err); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var details []map[string]interface{}
+	if err = json.Unmarshal(out.Bytes(), &details); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+	if len(details) != 1 {
+		t.Fatalf("unexpected details: %v", details)
+	}
+	d := details[0]
+	if d["target"] != "/app/bad" ||
+		d["kustomization"] != "/app/bad/kustomization.yaml" ||
+		d["file"] != "/app/bad/cm.yaml" || d["line"] != 4.0 ||
+		!strings.Contains(d["message"].(string), "found character that cannot start any token") {
+		t.Fatalf("unexpected details: %v", d)
+	}
+}
+
+func TestValidateFlagErrorFormat(t *testing.T) {
+	defer func() { flagErrorFormatValue = errorFormatText }()
+	flagErrorFormatValue = "xml"
+	if err := (&Options{}).Validate(nil); err == nil ||
+		err.Error() != "illegal flag value --error-format xml; legal values: [text json]" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetFlagParamValue(t *testing.T) {
+	defer func() { flagParamValue = nil }()
+	flagParamValue = []string{"env=prod", "region=eu=west"}
+	params, err := getFlagParamValue()
+	if err != nil || len(params) != 2 ||
+		params["env"] != "prod" || params["region"] != "eu=west" {
+		t.Fatalf("unexpected params %v, error %v", params, err)
+	}
+	flagParamValue = []string{"env"}
+	if err := (&Options{}).Validate(nil); err == nil ||
+		err.Error() != "invalid --param 'env', expected name=value" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWriteJSONValidationErrors(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+validations:
+- requiredLabel: team
+`))
+	fSys.WriteFile("/app/cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	k := krusty.MakeKustomizer(fSys, krusty.MakeDefaultOptions())
+	_, err := k.Run("/app")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	var out bytes.Buffer
+	if err = writeJSONErrors(&out, err); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var details []map[string]interface{}
+	if err = json.Unmarshal(out.Bytes(), &details); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+	if len(details) != 1 {
+		t.Fatalf("unexpected details: %v", details)
+	}
+	d := details[0]
+	if d["kustomization"] != "/app/kustomization.yaml" ||
+		d["rule"] != "requiredLabel" ||
+		d["message"] != "missing label 'team'" ||
+		d["resId"].(map[string]interface{})["name"] != "cm" {
+		t.Fatalf("unexpected details: %v", d)
+	}
+}
+
+func TestWriteJSONSchemaErrors(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+`))
+	fSys.WriteFile("/app/cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  port: 80
+`))
+	opts := krusty.MakeDefaultOptions()
+	opts.Validate = true
+	_, err := krusty.MakeKustomizer(fSys, opts).Run("/app")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	var out bytes.Buffer
+	if err = writeJSONErrors(&out, err); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var details []map[string]interface{}
+	if err = json.Unmarshal(out.Bytes(), &details); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+	if len(details) != 1 {
+		t.Fatalf("unexpected details: %v", details)
+	}
+	d := details[0]
+	if d["rule"] != "typeMismatch" ||
+		d["path"] != "data.port" ||
+		d["message"] != "expected string, got integer" {
+		t.Fatalf("unexpected details: %v", d)
+	}
+}
+
+func TestValidationWarnings(t *testing.T) {
+	defer func() { flagWarnOnlyValue = false }()
+	flagWarnOnlyValue = true
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+validations:
+- requiredLabel: team
+`))
+	fSys.WriteFile("/app/cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	var errOut bytes.Buffer
+	o := &Options{errOut: &errOut}
+	m, err := krusty.MakeKustomizer(fSys, o.makeOptions()).Run("/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Size() != 1 {
+		t.Fatalf("expected 1 resource, got %d", m.Size())
+	}
+	expected := "Warning: 1 validation failures:\n" +
+		"  ~G_v1_ConfigMap|~X|cm: requiredLabel: missing label 'team'\n"
+	if errOut.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, errOut.String())
+	}
+}
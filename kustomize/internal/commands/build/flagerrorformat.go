@@ -0,0 +1,139 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/kusterr"
+)
+
+const (
+	flagErrorFormatName = "error-format"
+	errorFormatText     = "text"
+	errorFormatJSON     = "json"
+)
+
+var (
+	flagErrorFormatValue = errorFormatText
+	flagErrorFormatHelp  = "if set to '" + errorFormatJSON + "', build errors " +
+		"are written to stderr as a JSON array of objects holding the " +
+		"kustomization, file, line, column, resource id and transformer " +
+		"at fault, where known, and the message."
+)
+
+func addFlagErrorFormat(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagErrorFormatValue, flagErrorFormatName,
+		errorFormatText, flagErrorFormatHelp)
+}
+
+func validateFlagErrorFormat() error {
+	switch flagErrorFormatValue {
+	case errorFormatText, errorFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagErrorFormatName, flagErrorFormatValue,
+			[]string{errorFormatText, errorFormatJSON})
+	}
+}
+
+func isFlagErrorFormatJSON() bool {
+	return flagErrorFormatValue == errorFormatJSON
+}
+
+// writeJSONErrors writes the details of the error as a
+// JSON array; one element per failed build if --multi.
+func writeJSONErrors(w io.Writer, err error) error {
+	var details []map[string]interface{}
+	add := func(target string, err error) error {
+		if v, ok := err.(*kusterr.ValidationError); ok {
+			for _, d := range validationDetails(v) {
+				if target != "" {
+					d["target"] = target
+				}
+				details = append(details, d)
+			}
+			return nil
+		}
+		b, err := json.Marshal(kusterr.Details(err))
+		if err != nil {
+			return err
+		}
+		var d map[string]interface{}
+		if err = json.Unmarshal(b, &d); err != nil {
+			return err
+		}
+		if target != "" {
+			d["target"] = target
+		}
+		details = append(details, d)
+		return nil
+	}
+	if f, ok := err.(*buildFailures); ok {
+		for i := range f.paths {
+			if err := add(f.paths[i], f.errs[i]); err != nil {
+				return err
+			}
+		}
+	} else if err := add("", err); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// validationDetails returns the details of each failure
+// of the validation error.
+func validationDetails(v *kusterr.ValidationError) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(v.Failures))
+	for i, f := range v.Failures {
+		result[i] = map[string]interface{}{
+			"kustomization": v.Kustomization,
+			"resId":         f.ResId,
+			"rule":          f.Rule,
+			"message":       f.Message,
+		}
+		if f.Path != "" {
+			result[i]["path"] = f.Path
+		}
+		if f.Kustomization != "" {
+			result[i]["kustomization"] = f.Kustomization
+		}
+	}
+	return result
+}
+
+// writeValidationWarnings writes the failures of the
+// validation error to the error output, as warnings.
+func (o *Options) writeValidationWarnings(v *kusterr.ValidationError) {
+	w := o.errOut
+	if w == nil {
+		w = os.Stderr
+	}
+	if !isFlagErrorFormatJSON() {
+		fmt.Fprintf(w, "Warning: %v\n", v)
+		return
+	}
+	details := validationDetails(v)
+	for _, d := range details {
+		d["severity"] = "warning"
+	}
+	b, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		fmt.Fprintf(w, "Warning: %v\n", v)
+		return
+	}
+	w.Write(append(b, '\n'))
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEnableHelmName  = "enable-helm"
+	flagEnableHelmHelp  = `enable the helmCharts field, which inflates charts by running helm`
+	flagHelmCommandName = "helm-command"
+	flagHelmCommandHelp = `helm command to run when inflating charts`
+)
+
+var (
+	flagEnableHelmValue  = false
+	flagHelmCommandValue = "helm"
+)
+
+func addFlagHelm(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagEnableHelmValue, flagEnableHelmName,
+		false, flagEnableHelmHelp)
+	set.StringVar(
+		&flagHelmCommandValue, flagHelmCommandName,
+		"helm", flagHelmCommandHelp)
+}
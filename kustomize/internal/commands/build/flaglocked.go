@@ -0,0 +1,30 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+const (
+	flagLockedName = "locked"
+	flagLockedHelp = `fail unless every remote base and file matches its entry in ` +
+		konfig.LockFileName + ` (see 'kustomize edit lock')`
+)
+
+var (
+	flagLockedValue = false
+)
+
+func addFlagLocked(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagLockedValue, flagLockedName,
+		false, flagLockedHelp)
+}
+
+func isFlagLockedSet() bool {
+	return flagLockedValue
+}
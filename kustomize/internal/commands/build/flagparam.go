@@ -0,0 +1,45 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagParamName = "param"
+	flagParamHelp = `a build parameter, as name=value, that the when expressions ` +
+		`of resources, components and patches refer to; may be repeated`
+)
+
+var (
+	flagParamValue []string
+)
+
+func addFlagParam(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&flagParamValue, flagParamName,
+		[]string{}, flagParamHelp)
+}
+
+func validateFlagParam() error {
+	_, err := getFlagParamValue()
+	return err
+}
+
+func getFlagParamValue() (map[string]string, error) {
+	result := make(map[string]string)
+	for _, p := range flagParamValue {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf(
+				"invalid --%s '%s', expected name=value", flagParamName, p)
+		}
+		result[kv[0]] = kv[1]
+	}
+	return result, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagCacheDirName = "cache-dir"
+	flagCacheDirHelp = `directory in which to keep copies of remote bases, ` +
+		`one per repository and ref, across builds, e.g. ` +
+		`$XDG_CACHE_HOME/kustomize/repos; if empty, copies last one build`
+	flagCacheTTLName = "cache-ttl"
+	flagCacheTTLHelp = `reuse copies of remote bases left in the cache ` +
+		`by earlier builds if younger than this, e.g. '1h'; ` +
+		`within one build, a copy is always reused`
+)
+
+var (
+	flagCacheDirValue string
+	flagCacheTTLValue time.Duration
+)
+
+func addFlagRepoCache(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagCacheDirValue, flagCacheDirName,
+		"", flagCacheDirHelp)
+	set.DurationVar(
+		&flagCacheTTLValue, flagCacheTTLName,
+		0, flagCacheTTLHelp)
+}
+
+func validateFlagRepoCache() error {
+	if flagCacheTTLValue < 0 {
+		return fmt.Errorf(
+			"illegal flag value --%s %s; must not be negative",
+			flagCacheTTLName, flagCacheTTLValue)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+const (
+	flagShowOriginsName = "show-origins"
+	flagShowOriginsHelp = `annotate resources with their origin (` +
+		konfig.OriginAnnotationKey + `) and the transformers that changed them (` +
+		konfig.TransformationsAnnotationKey + `)`
+)
+
+var (
+	flagShowOriginsValue = false
+)
+
+func addFlagShowOrigins(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagShowOriginsValue, flagShowOriginsName,
+		false, flagShowOriginsHelp)
+}
+
+func isFlagShowOriginsSet() bool {
+	return flagShowOriginsValue
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagStrictName = "strict"
+	flagStrictHelp = `check kustomization files against their schema ` +
+		`(see 'kustomize cfg schema kustomization'), rejecting fields ` +
+		`whose names are in the wrong case`
+)
+
+var (
+	flagStrictValue = false
+)
+
+func addFlagStrict(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagStrictValue, flagStrictName,
+		false, flagStrictHelp)
+}
+
+func isFlagStrictSet() bool {
+	return flagStrictValue
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagValidateName = "validate"
+	flagValidateHelp = `check every resource of the output against its ` +
+		`OpenAPI schema, including those of crds and CustomResourceDefinitions ` +
+		`of the build, without contacting a cluster`
+)
+
+var (
+	flagValidateValue = false
+)
+
+func addFlagValidate(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagValidateValue, flagValidateName,
+		false, flagValidateHelp)
+}
+
+func isFlagValidateSet() bool {
+	return flagValidateValue
+}
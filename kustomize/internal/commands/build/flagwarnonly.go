@@ -0,0 +1,28 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagWarnOnlyName = "warn-only"
+	flagWarnOnlyHelp = `write resources breaking the validations of the ` +
+		`kustomization to stderr as warnings, instead of failing the build`
+)
+
+var (
+	flagWarnOnlyValue = false
+)
+
+func addFlagWarnOnly(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagWarnOnlyValue, flagWarnOnlyName,
+		false, flagWarnOnlyHelp)
+}
+
+func isFlagWarnOnlySet() bool {
+	return flagWarnOnlyValue
+}
@@ -17,8 +17,10 @@ import (
 	"sigs.k8s.io/kustomize/api/konfig"
 	shell_complete "sigs.k8s.io/kustomize/cmd/config/complete"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/build"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/crdconfig"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/create"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/schema"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/status"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/version"
 )
@@ -47,6 +49,10 @@ See https://sigs.k8s.io/kustomize
 		status.NewCmdStatus(),
 	)
 	configcobra.AddCommands(c, "kustomize")
+	if cfg, _, err := c.Find([]string{"cfg"}); err == nil {
+		cfg.AddCommand(schema.NewCmdSchema(stdOut))
+		cfg.AddCommand(crdconfig.NewCmdCrdConfig(fSys, stdOut))
+	}
 
 	c.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 
@@ -0,0 +1,44 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package crdconfig
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+// NewCmdCrdConfig makes a command printing the
+// configuration that builds learn from CRDs.
+func NewCmdCrdConfig(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "crdconfig FILE...",
+		Short: "Prints the configuration that builds learn from CRDs",
+		Long: `Prints, as a configurations file, the configuration that
+builds learn from the OpenAPI definitions of CRDs in the files, as
+listed in the crds field of kustomizations.
+
+That includes the name references of custom resources, whether their
+definitions declare them with x-kubernetes-object-ref-* extensions, or
+they are inferred from their fields: fields whose names mention a
+Secret, ConfigMap, Service or ServiceAccount, and either end in Ref and
+have a name field, or are LocalObjectReferences.
+`,
+		Example: `
+  # Review the name references that builds infer
+  kustomize cfg crdconfig crds/mycrd.json
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := krusty.ConfigFromCRDs(fSys, args)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		},
+	}
+}
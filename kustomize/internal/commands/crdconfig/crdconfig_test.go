@@ -0,0 +1,53 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package crdconfig
+
+import (
+	"bytes"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+func TestCrdConfig(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("crds.yaml", []byte(`
+example.com/v1.Proxy:
+  Schema:
+    properties:
+      apiVersion:
+        type: string
+      kind:
+        type: string
+      metadata:
+        $ref: k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta
+      spec:
+        properties:
+          upstreamServiceRef:
+            properties:
+              name:
+                type: string
+`))
+	var out bytes.Buffer
+	cmd := NewCmdCrdConfig(fSys, &out)
+	cmd.SetArgs([]string{"crds.yaml"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `nameReference:
+- fieldSpecs:
+  - kind: Proxy
+    path: spec/upstreamServiceRef/name
+  kind: Service
+  version: v1
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -30,6 +30,12 @@ func newCmdAddConfigMap(
 
 	# Adds a configmap from env-file
 	kustomize edit add configmap my-configmap --from-env-file=env/path.env
+
+	# Adds a configmap with a key per leaf of a YAML or JSON file (e.g. db.host)
+	kustomize edit add configmap my-configmap --from-structured=settings.yaml
+
+	# Adds a configmap with a key per file of a directory tree
+	kustomize edit add configmap my-configmap --from-dir=conf --exclude='*.bak'
 `,
 		RunE: func(_ *cobra.Command, args []string) error {
 			err := flags.ExpandFileSource(fSys)
@@ -37,7 +43,7 @@ func newCmdAddConfigMap(
 				return err
 			}
 
-			err = flags.Validate(args)
+			err = flags.ValidateConfigMap(args)
 			if err != nil {
 				return err
 			}
@@ -81,6 +87,33 @@ func newCmdAddConfigMap(
 		"from-env-file",
 		"",
 		"Specify the path to a file to read lines of key=val pairs to create a configmap (i.e. a Docker .env file).")
+	cmd.Flags().StringSliceVar(
+		&flags.StructuredSources,
+		"from-structured",
+		[]string{},
+		"Specify the path to a JSON or YAML file, optionally with a key prefix (i.e. [prefix=]path), each leaf of "+
+			"which is inserted in configmap, keyed by its path (i.e. db.host).")
+	cmd.Flags().StringVar(
+		&flags.DirSource,
+		"from-dir",
+		"",
+		"Specify the path to a directory, each file of whose tree is inserted in configmap, keyed by its path "+
+			"below the directory.  Files that aren't UTF-8 become binaryData.")
+	cmd.Flags().StringSliceVar(
+		&flags.Include,
+		"include",
+		[]string{},
+		"Glob patterns of the files of from-dir to insert (default all).  Patterns without a '/' match basenames.")
+	cmd.Flags().StringSliceVar(
+		&flags.Exclude,
+		"exclude",
+		[]string{},
+		"Glob patterns of the files of from-dir not to insert.  Patterns without a '/' match basenames.")
+	cmd.Flags().StringVar(
+		&flags.KeySeparator,
+		"key-separator",
+		"",
+		"Specify the separator of the parts of the keys made by from-structured and from-dir (default '.').")
 
 	return cmd
 }
@@ -126,4 +159,18 @@ func mergeFlagsIntoCmArgs(args *types.ConfigMapArgs, flags flagsAndArgs) {
 		args.EnvSources = append(
 			args.EnvSources, flags.EnvFileSource)
 	}
+	if len(flags.StructuredSources) > 0 {
+		args.StructuredSources = append(
+			args.StructuredSources, flags.StructuredSources...)
+	}
+	if flags.DirSource != "" {
+		args.DirSources = append(args.DirSources, types.DirSource{
+			Path:    flags.DirSource,
+			Include: flags.Include,
+			Exclude: flags.Exclude,
+		})
+	}
+	if flags.KeySeparator != "" {
+		args.KeySeparator = flags.KeySeparator
+	}
 }
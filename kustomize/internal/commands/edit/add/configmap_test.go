@@ -4,6 +4,7 @@
 package add
 
 import (
+	"reflect"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -110,3 +111,25 @@ func TestMergeFlagsIntoConfigMapArgs_EnvSource(t *testing.T) {
 		t.Fatalf("expected env2")
 	}
 }
+
+func TestMergeFlagsIntoConfigMapArgs_StructuredAndDirSources(t *testing.T) {
+	k := &types.Kustomization{}
+	args := findOrMakeConfigMapArgs(k, "foo")
+	mergeFlagsIntoCmArgs(args, flagsAndArgs{
+		StructuredSources: []string{"settings.yaml"},
+		DirSource:         "conf",
+		Exclude:           []string{"*.bak"},
+		KeySeparator:      "_",
+	})
+	mergeFlagsIntoCmArgs(args, flagsAndArgs{
+		StructuredSources: []string{"app=app.json"},
+	})
+	expected := types.KvPairSources{
+		StructuredSources: []string{"settings.yaml", "app=app.json"},
+		DirSources:        []types.DirSource{{Path: "conf", Exclude: []string{"*.bak"}}},
+		KeySeparator:      "_",
+	}
+	if !reflect.DeepEqual(k.ConfigMapGenerator[0].KvPairSources, expected) {
+		t.Fatalf("expected %v, got %v", expected, k.ConfigMapGenerator[0].KvPairSources)
+	}
+}
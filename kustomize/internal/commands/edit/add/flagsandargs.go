@@ -22,6 +22,17 @@ type flagsAndArgs struct {
 	// EnvFileSource to derive the configMap/Secret from (optional)
 	// TODO: Rationalize this name with Generic.EnvSource
 	EnvFileSource string
+	// StructuredSources to derive the configMap from (optional)
+	StructuredSources []string
+	// DirSource to derive the configMap from (optional),
+	// and glob patterns of the files in it to include or
+	// exclude.
+	DirSource string
+	Include   []string
+	Exclude   []string
+	// KeySeparator joins the parts of keys made from
+	// StructuredSources and DirSource (optional)
+	KeySeparator string
 	// Type of secret to create
 	Type string
 	// Namespace of secret
@@ -30,16 +41,35 @@ type flagsAndArgs struct {
 
 // Validate validates required fields are set to support structured generation.
 func (a *flagsAndArgs) Validate(args []string) error {
+	return a.validate(args, false)
+}
+
+// ValidateConfigMap is Validate for 'edit add configmap',
+// which also takes --from-structured and --from-dir.
+func (a *flagsAndArgs) ValidateConfigMap(args []string) error {
+	return a.validate(args, true)
+}
+
+func (a *flagsAndArgs) validate(args []string, configMap bool) error {
 	if len(args) != 1 {
 		return fmt.Errorf("name must be specified once")
 	}
 	a.Name = args[0]
-	if len(a.EnvFileSource) == 0 && len(a.FileSources) == 0 && len(a.LiteralSources) == 0 {
+	if configMap {
+		if len(a.EnvFileSource) == 0 && len(a.FileSources) == 0 && len(a.LiteralSources) == 0 &&
+			len(a.StructuredSources) == 0 && a.DirSource == "" {
+			return fmt.Errorf(
+				"at least from-env-file, or from-file, from-literal, from-structured or from-dir must be set")
+		}
+	} else if len(a.EnvFileSource) == 0 && len(a.FileSources) == 0 && len(a.LiteralSources) == 0 {
 		return fmt.Errorf("at least from-env-file, or from-file or from-literal must be set")
 	}
 	if len(a.EnvFileSource) > 0 && (len(a.FileSources) > 0 || len(a.LiteralSources) > 0) {
 		return fmt.Errorf("from-env-file cannot be combined with from-file or from-literal")
 	}
+	if (len(a.Include) > 0 || len(a.Exclude) > 0) && a.DirSource == "" {
+		return fmt.Errorf("include and exclude can only be combined with from-dir")
+	}
 	// TODO: Should we check if the path exists? if it's valid, if it's within the same (sub-)directory?
 	return nil
 }
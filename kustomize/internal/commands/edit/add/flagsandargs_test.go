@@ -5,6 +5,7 @@ package add
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -61,17 +62,52 @@ func TestDataConfigValidation_Flags(t *testing.T) {
 			},
 			shouldFail: false,
 		},
+		{
+			name: "we have only from-structured",
+			fa: flagsAndArgs{
+				StructuredSources: []string{"settings.yaml"},
+			},
+			shouldFail: false,
+		},
+		{
+			name: "we have from-dir with excludes",
+			fa: flagsAndArgs{
+				DirSource: "conf",
+				Exclude:   []string{"*.bak"},
+			},
+			shouldFail: false,
+		},
+		{
+			name: "we have includes without from-dir",
+			fa: flagsAndArgs{
+				FileSources: []string{"one"},
+				Include:     []string{"*.conf"},
+			},
+			shouldFail: true,
+		},
 	}
 
 	for _, test := range tests {
-		if test.fa.Validate([]string{"name"}) == nil && test.shouldFail {
+		if test.fa.ValidateConfigMap([]string{"name"}) == nil && test.shouldFail {
 			t.Fatalf("Validation should fail if %s", test.name)
-		} else if test.fa.Validate([]string{"name"}) != nil && !test.shouldFail {
+		} else if test.fa.ValidateConfigMap([]string{"name"}) != nil && !test.shouldFail {
 			t.Fatalf("Validation should succeed if %s", test.name)
 		}
 	}
 }
 
+func TestDataValidation_NoSourceMessages(t *testing.T) {
+	fa := flagsAndArgs{}
+	err := fa.Validate([]string{"name"})
+	if err == nil || strings.Contains(err.Error(), "from-structured") {
+		t.Fatalf("unexpected secret error: %v", err)
+	}
+	err = fa.ValidateConfigMap([]string{"name"})
+	if err == nil || !strings.Contains(err.Error(), "from-structured or from-dir") {
+		t.Fatalf("unexpected configmap error: %v", err)
+	}
+}
+
 func TestExpandFileSource(t *testing.T) {
 	fSys := filesys.MakeEmptyDirInMemory()
 	fSys.Create("dir/fa1")
@@ -11,6 +11,7 @@ import (
 	"sigs.k8s.io/kustomize/api/loader"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/add"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/fix"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/lock"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/remove"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/set"
 )
@@ -31,6 +32,9 @@ func NewCmdEdit(
 
 	# Sets the namesuffix field
 	kustomize edit set namesuffix <suffix-value>
+
+	# Pins the remote bases in kustomization.lock
+	kustomize edit lock
 `,
 		Args: cobra.MinimumNArgs(1),
 	}
@@ -42,6 +46,7 @@ func NewCmdEdit(
 			kf),
 		set.NewCmdSet(fSys, v),
 		fix.NewCmdFix(fSys),
+		lock.NewCmdLock(fSys),
 		remove.NewCmdRemove(fSys, v),
 	)
 	return c
@@ -0,0 +1,50 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/loader"
+)
+
+// NewCmdLock returns an instance of 'lock' subcommand.
+func NewCmdLock(fSys filesys.FileSystem) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Pin the remote bases of the kustomization in " + konfig.LockFileName,
+		Long: `Builds the kustomization, and records in ` + konfig.LockFileName + `
+the commit of every remote git base reached (however indirectly), and a
+hash of every other remote base or file.  Later builds fetch the recorded
+commits instead of following branches; 'kustomize build --locked' also fails
+if any remote is missing from the lock file or no longer matches it.
+
+Run it again to update the lock file to the latest versions.`,
+		Example: `
+	# Pin the remote bases of the kustomization in the current directory
+	kustomize edit lock
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return errors.New("lock takes no arguments")
+			}
+			return RunLock(fSys)
+		},
+	}
+	return cmd
+}
+
+// RunLock runs `lock` command
+func RunLock(fSys filesys.FileSystem) error {
+	k := krusty.MakeKustomizer(fSys, krusty.MakeDefaultOptions())
+	lock, err := k.Lock(filesys.SelfDir)
+	if err != nil {
+		return err
+	}
+	return loader.WriteLockFile(fSys, filesys.SelfDir, lock)
+}
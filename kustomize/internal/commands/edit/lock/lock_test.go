@@ -0,0 +1,57 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	testutils_test "sigs.k8s.io/kustomize/kustomize/v3/internal/commands/testutils"
+)
+
+func TestLock(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+resources:
+- cm.yaml
+`))
+	fSys.WriteFile("cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	cmd := NewCmdLock(fSys)
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	content, err := fSys.ReadFile(konfig.LockFileName)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if string(content) != `apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: KustomizationLock
+` {
+		t.Fatalf("unexpected lock file:\n%s", content)
+	}
+	if err = cmd.RunE(cmd, []string{"foo"}); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestLockFailsOnBrokenKustomization(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+resources:
+- missing.yaml
+`))
+	cmd := NewCmdLock(fSys)
+	if err := cmd.RunE(cmd, nil); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if fSys.Exists(konfig.LockFileName) {
+		t.Fatalf("unexpected lock file")
+	}
+}
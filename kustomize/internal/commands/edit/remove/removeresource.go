@@ -75,6 +75,7 @@ func (o *removeResourceOptions) RunRemoveResource(fSys filesys.FileSystem) error
 	newResources := make([]string, 0, len(m.Resources))
 	for _, resource := range m.Resources {
 		if kustfile.StringInSlice(resource, resources) {
+			delete(m.ResourceConditions, resource)
 			continue
 		}
 		newResources = append(newResources, resource)
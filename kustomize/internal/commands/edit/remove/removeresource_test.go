@@ -160,3 +160,30 @@ func TestRemoveResources(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveResourceWithCondition(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`resources:
+- path: monitoring
+  when: env == prod
+- path: logging
+  when: env == dev
+`))
+	cmd := newCmdRemoveResource(fSys)
+	if err := cmd.RunE(cmd, []string{"monitoring"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := `resources:
+- path: logging
+  when: env == dev
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`
+	if string(content) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, content)
+	}
+}
@@ -31,6 +31,7 @@ func NewCmdSet(fSys filesys.FileSystem, v ifc.Validator) *cobra.Command {
 		newCmdSetNamespace(fSys, v),
 		newCmdSetImage(fSys),
 		newCmdSetReplicas(fSys),
+		newCmdSetResources(fSys),
 	)
 	return c
 }
@@ -17,7 +17,11 @@ import (
 )
 
 type setImageOptions struct {
-	imageMap map[string]types.Image
+	imageMap    map[string]types.Image
+	tagPolicy   string
+	digestFrom  string
+	imageIndex  string
+	strictIndex bool
 }
 
 var pattern = regexp.MustCompile("^(.*):([a-zA-Z0-9._-]*)$")
@@ -25,13 +29,15 @@ var pattern = regexp.MustCompile("^(.*):([a-zA-Z0-9._-]*)$")
 // errors
 
 var (
-	errImageNoArgs      = errors.New("no image specified")
-	errImageInvalidArgs = errors.New(`invalid format of image, use one of the following options:
+	errImageNoArgs        = errors.New("no image specified")
+	errImageStrictNoIndex = errors.New("--strict-image-index requires --image-index")
+	errImageInvalidArgs   = errors.New(`invalid format of image, use one of the following options:
 - <image>=<newimage>:<newtag>
 - <image>=<newimage>@<digest>
 - <image>=<newimage>
 - <image>:<newtag>
-- <image>@<digest>`)
+- <image>@<digest>
+- <image>, with --tag-policy or --digest-from`)
 )
 
 const separator = "="
@@ -73,6 +79,20 @@ images:
 
 to the kustomization file if it doesn't exist,
 and overwrite the previous ones if the image name exists.
+
+The command
+  set image nginx --tag-policy 'semver:^1.19' --digest-from file --image-index images.lock.yaml
+will add
+
+images:
+- digestFrom: file
+  name: nginx
+  tagPolicy: semver:^1.19
+imageIndex:
+  path: images.lock.yaml
+
+to the kustomization file, to replace the tag of nginx images with
+the digest of the highest 1.x tag, from 1.19.0, in the image index.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
@@ -82,6 +102,14 @@ and overwrite the previous ones if the image name exists.
 			return o.RunSetImage(fSys)
 		},
 	}
+	cmd.Flags().StringVar(&o.tagPolicy, "tag-policy", "",
+		"policy picking the new tag of the images from the image index, e.g. 'semver:^1.2'")
+	cmd.Flags().StringVar(&o.digestFrom, "digest-from", "",
+		"if 'file', replace the tag of the images with its digest in the image index")
+	cmd.Flags().StringVar(&o.imageIndex, "image-index", "",
+		"path of the image index file, e.g. images.lock.yaml")
+	cmd.Flags().BoolVar(&o.strictIndex, "strict-image-index", false,
+		"fail the build if the image of a container isn't in the image index")
 	return cmd
 }
 
@@ -96,15 +124,20 @@ func (o *setImageOptions) Validate(args []string) error {
 	if len(args) == 0 {
 		return errImageNoArgs
 	}
+	if o.strictIndex && o.imageIndex == "" {
+		return errImageStrictNoIndex
+	}
 
 	o.imageMap = make(map[string]types.Image)
 
 	for _, arg := range args {
 
-		img, err := parse(arg)
+		img, err := parse(arg, o.tagPolicy != "" || o.digestFrom != "")
 		if err != nil {
 			return err
 		}
+		img.TagPolicy = o.tagPolicy
+		img.DigestFrom = o.digestFrom
 		o.imageMap[img.Name] = img
 	}
 	return nil
@@ -140,10 +173,18 @@ func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem) error {
 	})
 
 	m.Images = images
+	if o.imageIndex != "" {
+		m.ImageIndex = &types.ImageIndex{
+			Path:   o.imageIndex,
+			Strict: o.strictIndex,
+		}
+	}
 	return mf.Write(m)
 }
 
-func parse(arg string) (types.Image, error) {
+// parse parses an image argument; with nameOnly, a bare
+// image name is allowed.
+func parse(arg string, nameOnly bool) (types.Image, error) {
 
 	// matches if there is an image name to overwrite
 	// <image>=<new-image><:|@><new-tag>
@@ -159,7 +200,7 @@ func parse(arg string) (types.Image, error) {
 
 	// matches only for <tag|digest> overwrites
 	// <image><:|@><new-tag>
-	p, err := parseOverwrite(arg, false)
+	p, err := parseOverwrite(arg, nameOnly)
 	return types.Image{
 		Name:   p.name,
 		NewTag: p.tag,
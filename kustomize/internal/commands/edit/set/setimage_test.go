@@ -247,3 +247,54 @@ func TestSetImage(t *testing.T) {
 		})
 	}
 }
+
+func TestSetImageWithIndex(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+	cmd := newCmdSetImage(fSys)
+	for flag, value := range map[string]string{
+		"tag-policy":         "semver:^1.19",
+		"digest-from":        "file",
+		"image-index":        "images.lock.yaml",
+		"strict-image-index": "true",
+	} {
+		if err := cmd.Flags().Set(flag, value); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	err := cmd.RunE(cmd, []string{"nginx", "redis=my-redis"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := `images:
+- digestFrom: file
+  name: nginx
+  tagPolicy: semver:^1.19
+- digestFrom: file
+  name: redis
+  newName: my-redis
+  tagPolicy: semver:^1.19
+imageIndex:
+  path: images.lock.yaml
+  strict: true
+`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected kustomization file.\nActual:\n%s\nExpected:\n%s", content, expected)
+	}
+}
+
+func TestSetImageStrictWithoutIndex(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+	cmd := newCmdSetImage(fSys)
+	if err := cmd.Flags().Set("strict-image-index", "true"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cmd.RunE(cmd, []string{"nginx:1.19"}); err != errImageStrictNoIndex {
+		t.Fatalf("expected %v, got %v", errImageStrictNoIndex, err)
+	}
+}
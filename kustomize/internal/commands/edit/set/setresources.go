@@ -0,0 +1,158 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package set
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/kustfile"
+)
+
+type setResourcesOptions struct {
+	selector   types.Selector
+	containers []string
+	requests   types.ResourceQuantities
+	limits     types.ResourceQuantities
+}
+
+// errors
+
+var (
+	errResourcesNoContainers = errors.New("no containers specified")
+	errResourcesNoQuantities = errors.New(
+		"no quantities specified, use --cpu-request, --memory-request, --cpu-limit or --memory-limit")
+	errResourcesEmptyContainer = errors.New("container name can't be empty")
+)
+
+// newCmdSetResources sets the compute resources of containers
+// in the scaling field of the kustomization.
+func newCmdSetResources(fSys filesys.FileSystem) *cobra.Command {
+	var o setResourcesOptions
+
+	cmd := &cobra.Command{
+		Use:   "resources <container>...",
+		Short: `Sets the compute resources of containers in the kustomization file`,
+		Example: `
+The command
+  set resources app --kind Deployment --name web --cpu-request 250m --memory-limit 1Gi
+will add
+
+scaling:
+- selector:
+    kind: Deployment
+    name: web
+  resources:
+  - container: app
+    requests:
+      cpu: 250m
+    limits:
+      memory: 1Gi
+
+to the kustomization file if it doesn't exist,
+and merge into the scaling entry with the same selector if it does.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+			return o.RunSetResources(fSys)
+		},
+	}
+	cmd.Flags().StringVar(&o.selector.Kind, "kind", "",
+		"Kind of the resources to set the container resources of")
+	cmd.Flags().StringVar(&o.selector.Name, "name", "",
+		"Name of the resources to set the container resources of")
+	cmd.Flags().StringVar(&o.selector.Namespace, "namespace", "",
+		"Namespace of the resources to set the container resources of")
+	cmd.Flags().StringVar(&o.selector.LabelSelector, "label-selector", "",
+		"Label selector of the resources to set the container resources of")
+	cmd.Flags().StringVar(&o.requests.CPU, "cpu-request", "",
+		"CPU request of the containers, e.g. 250m")
+	cmd.Flags().StringVar(&o.requests.Memory, "memory-request", "",
+		"Memory request of the containers, e.g. 64Mi")
+	cmd.Flags().StringVar(&o.limits.CPU, "cpu-limit", "",
+		"CPU limit of the containers, e.g. 1")
+	cmd.Flags().StringVar(&o.limits.Memory, "memory-limit", "",
+		"Memory limit of the containers, e.g. 1Gi")
+	return cmd
+}
+
+// Validate validates setResources command.
+func (o *setResourcesOptions) Validate(args []string) error {
+	if len(args) == 0 {
+		return errResourcesNoContainers
+	}
+	for _, arg := range args {
+		if arg == "" {
+			return errResourcesEmptyContainer
+		}
+	}
+	if o.requests == (types.ResourceQuantities{}) &&
+		o.limits == (types.ResourceQuantities{}) {
+		return errResourcesNoQuantities
+	}
+	o.containers = args
+	return nil
+}
+
+// RunSetResources runs setResources command.
+func (o *setResourcesOptions) RunSetResources(fSys filesys.FileSystem) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+
+	i := 0
+	for ; i < len(m.Scaling); i++ {
+		if m.Scaling[i].Selector == o.selector {
+			break
+		}
+	}
+	if i == len(m.Scaling) {
+		m.Scaling = append(m.Scaling, types.Scaling{Selector: o.selector})
+	}
+	s := &m.Scaling[i]
+	for _, name := range o.containers {
+		j := 0
+		for ; j < len(s.Resources); j++ {
+			if s.Resources[j].Container == name {
+				break
+			}
+		}
+		if j == len(s.Resources) {
+			s.Resources = append(s.Resources, types.ContainerResources{Container: name})
+		}
+		cr := &s.Resources[j]
+		cr.Requests = mergeQuantities(cr.Requests, o.requests)
+		cr.Limits = mergeQuantities(cr.Limits, o.limits)
+	}
+	return mf.Write(m)
+}
+
+// mergeQuantities overwrites the amounts in q with the
+// non-empty amounts of with.
+func mergeQuantities(
+	q *types.ResourceQuantities, with types.ResourceQuantities) *types.ResourceQuantities {
+	if with == (types.ResourceQuantities{}) {
+		return q
+	}
+	if q == nil {
+		q = &types.ResourceQuantities{}
+	}
+	if with.CPU != "" {
+		q.CPU = with.CPU
+	}
+	if with.Memory != "" {
+		q.Memory = with.Memory
+	}
+	return q
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package set
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	testutils_test "sigs.k8s.io/kustomize/kustomize/v3/internal/commands/testutils"
+)
+
+func TestSetResources(t *testing.T) {
+	type given struct {
+		args          []string
+		flags         []string
+		infileScaling []string
+	}
+	type expected struct {
+		fileOutput []string
+		err        error
+	}
+	testCases := []struct {
+		description string
+		given       given
+		expected    expected
+	}{
+		{
+			given: given{
+				args: []string{"app"},
+				flags: []string{
+					"--kind=Deployment", "--name=web",
+					"--cpu-request=250m", "--memory-limit=1Gi"},
+			},
+			expected: expected{
+				fileOutput: []string{
+					"scaling:",
+					"- resources:",
+					"  - container: app",
+					"    limits:",
+					"      memory: 1Gi",
+					"    requests:",
+					"      cpu: 250m",
+					"  selector:",
+					"    kind: Deployment",
+					"    name: web",
+				}},
+		},
+		{
+			description: "merge into selector",
+			given: given{
+				args:  []string{"app", "proxy"},
+				flags: []string{"--label-selector=tier=web", "--cpu-request=500m"},
+				infileScaling: []string{
+					"scaling:",
+					"- selector:",
+					"    labelSelector: tier=web",
+					"  replicas: 3",
+					"  resources:",
+					"  - container: app",
+					"    requests:",
+					"      cpu: 250m",
+					"      memory: 64Mi",
+				},
+			},
+			expected: expected{
+				fileOutput: []string{
+					"scaling:",
+					"- replicas: 3",
+					"  resources:",
+					"  - container: app",
+					"    requests:",
+					"      cpu: 500m",
+					"      memory: 64Mi",
+					"  - container: proxy",
+					"    requests:",
+					"      cpu: 500m",
+					"  selector:",
+					"    labelSelector: tier=web",
+				}},
+		},
+		{
+			description: "other selector",
+			given: given{
+				args:  []string{"app"},
+				flags: []string{"--name=web", "--memory-request=64Mi"},
+				infileScaling: []string{
+					"scaling:",
+					"- selector:",
+					"    name: api",
+					"  replicas: 2",
+				},
+			},
+			expected: expected{
+				fileOutput: []string{
+					"scaling:",
+					"- replicas: 2",
+					"  selector:",
+					"    name: api",
+					"- resources:",
+					"  - container: app",
+					"    requests:",
+					"      memory: 64Mi",
+					"  selector:",
+					"    name: web",
+				}},
+		},
+		{
+			description: "error: no args",
+			given: given{
+				flags: []string{"--cpu-request=250m"},
+			},
+			expected: expected{
+				err: errResourcesNoContainers,
+			},
+		},
+		{
+			description: "error: no quantities",
+			given: given{
+				args: []string{"app"},
+			},
+			expected: expected{
+				err: errResourcesNoQuantities,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s%v", tc.description, tc.given.args), func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			cmd := newCmdSetResources(fSys)
+
+			if len(tc.given.infileScaling) > 0 {
+				// write file with infileScaling
+				testutils_test.WriteTestKustomizationWith(
+					fSys,
+					[]byte(strings.Join(tc.given.infileScaling, "\n")))
+			} else {
+				testutils_test.WriteTestKustomization(fSys)
+			}
+
+			// act
+			if err := cmd.Flags().Parse(tc.given.flags); err != nil {
+				t.Fatalf("unexpected flag error: %v", err)
+			}
+			err := cmd.RunE(cmd, tc.given.args)
+
+			// assert
+			if err != tc.expected.err {
+				t.Errorf("Unexpected error from set resources command. Actual: %v\nExpected: %v", err, tc.expected.err)
+				t.FailNow()
+			}
+
+			content, err := testutils_test.ReadTestKustomization(fSys)
+			if err != nil {
+				t.Errorf("unexpected read error: %v", err)
+				t.FailNow()
+			}
+			expectedStr := strings.Join(tc.expected.fileOutput, "\n")
+			if !strings.Contains(string(content), expectedStr) {
+				t.Errorf("unexpected scaling in kustomization file. \nActual:\n%s\nExpected:\n%s", content, expectedStr)
+			}
+		})
+	}
+}
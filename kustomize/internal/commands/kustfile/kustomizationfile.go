@@ -40,6 +40,7 @@ func determineFieldOrder() []string {
 	ordered := []string{
 		"Resources",
 		"Bases",
+		"Params",
 		"NamePrefix",
 		"NameSuffix",
 		"Namespace",
@@ -54,11 +55,16 @@ func determineFieldOrder() []string {
 		"GeneratorOptions",
 		"Vars",
 		"Images",
+		"ImageIndex",
 		"Replicas",
+		"Scaling",
+		"RolloutOnChange",
 		"Configurations",
 		"Generators",
 		"Transformers",
+		"Validators",
 		"Inventory",
+		"Validations",
 	}
 
 	// Add deprecated fields here.
@@ -285,6 +291,13 @@ func marshalField(field string, kustomization *types.Kustomization) ([]byte, err
 	kr := reflect.ValueOf(k)
 	kv := kr.Elem().FieldByName(strings.Title(field))
 	kv.Set(v)
+	// The when expressions of entries are written with them.
+	switch strings.Title(field) {
+	case "Resources":
+		k.ResourceConditions = kustomization.ResourceConditions
+	case "Components":
+		k.ComponentConditions = kustomization.ComponentConditions
+	}
 
 	return yaml.Marshal(k)
 }
@@ -294,5 +307,8 @@ func isEmpty(v reflect.Value) bool {
 	if v.Type().Kind() == reflect.Ptr {
 		return v.IsNil()
 	}
+	if v.Type().Kind() == reflect.Bool {
+		return !v.Bool()
+	}
 	return v.Len() == 0
 }
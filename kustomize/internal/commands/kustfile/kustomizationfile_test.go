@@ -20,6 +20,7 @@ func TestFieldOrder(t *testing.T) {
 		"Kind",
 		"Resources",
 		"Bases",
+		"Params",
 		"NamePrefix",
 		"NameSuffix",
 		"Namespace",
@@ -34,11 +35,16 @@ func TestFieldOrder(t *testing.T) {
 		"GeneratorOptions",
 		"Vars",
 		"Images",
+		"ImageIndex",
 		"Replicas",
+		"Scaling",
+		"RolloutOnChange",
 		"Configurations",
 		"Generators",
 		"Transformers",
+		"Validators",
 		"Inventory",
+		"Validations",
 	}
 	actual := determineFieldOrder()
 	if len(expected) != len(actual) {
@@ -53,7 +59,8 @@ func TestFieldOrder(t *testing.T) {
 
 func TestWriteAndRead(t *testing.T) {
 	kustomization := &types.Kustomization{
-		NamePrefix: "prefix",
+		NamePrefix:      "prefix",
+		RolloutOnChange: true,
 	}
 
 	fSys := filesys.MakeFsInMemory()
@@ -162,6 +169,38 @@ patchesStrategicMerge:
 	}
 }
 
+func TestPreserveWhenExpressions(t *testing.T) {
+	content := []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+- path: monitoring
+  when: env in (prod, staging)
+`)
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, content)
+	mf, err := NewKustomizationFile(fSys)
+	if err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	kustomization, err := mf.Read()
+	if err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	if kustomization.Resources[1] != "monitoring" ||
+		kustomization.ResourceConditions["monitoring"] != "env in (prod, staging)" {
+		t.Fatalf("unexpected resources %v, conditions %v",
+			kustomization.Resources, kustomization.ResourceConditions)
+	}
+	if err = mf.Write(kustomization); err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	bytes, _ := fSys.ReadFile(mf.path)
+	if string(bytes) != string(content) {
+		t.Fatalf("expected:\n%s\ngot:\n%s", content, bytes)
+	}
+}
+
 func TestPreserveCommentsWithAdjust(t *testing.T) {
 	kustomizationContentWithComments := []byte(`
 
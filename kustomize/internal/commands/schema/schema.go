@@ -0,0 +1,51 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/schema"
+)
+
+// NewCmdSchema makes a command printing JSON Schemas of
+// the files kustomize reads.
+func NewCmdSchema(w io.Writer) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "schema",
+		Short: "Prints the JSON Schema of kustomize files",
+		Example: `
+  # Print the schema of kustomization files, e.g. for an editor
+  kustomize cfg schema kustomization > kustomization.schema.json
+`,
+	}
+	c.AddCommand(newCmdSchemaKustomization(w))
+	return c
+}
+
+func newCmdSchemaKustomization(w io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use: "kustomization",
+		Short: "Prints the JSON Schema of " +
+			konfig.DefaultKustomizationFileName() + " files",
+		Long: `Prints the JSON Schema of kustomization files, of both
+Kustomizations and Components, as made from the types kustomize
+decodes them into.  'kustomize build --strict' checks kustomization
+files against it.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := json.MarshalIndent(schema.Kustomization(), "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(w, string(b))
+			return err
+		},
+	}
+}
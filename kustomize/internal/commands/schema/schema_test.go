@@ -0,0 +1,35 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaKustomization(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewCmdSchema(&out)
+	cmd.SetArgs([]string{"kustomization"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var s struct {
+		Schema      string                 `json:"$schema"`
+		Definitions map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Schema != "http://json-schema.org/draft-07/schema#" ||
+		s.Definitions["Kustomization"] == nil || s.Definitions["Component"] == nil {
+		t.Fatalf("unexpected schema:\n%s", out.String())
+	}
+
+	cmd.SetArgs([]string{"kustomization", "extra"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	openapierrors "github.com/go-openapi/errors"
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Defaulter is implemented by functionConfig types which set their own default values.
+// ResourceList.Read() calls Default after parsing the functionConfig.
+type Defaulter interface {
+	Default() error
+}
+
+// Validator is implemented by functionConfig types which validate themselves.
+// ResourceList.Read() calls Validate after defaulting the functionConfig and validating
+// it against the ResourceList.FunctionConfigSchema.  Return a Result to report
+// the invalid fields.
+type Validator interface {
+	Validate() error
+}
+
+// defaultAndValidate defaults the functionConfig, then validates it.
+func (r *ResourceList) defaultAndValidate() error {
+	if d, ok := r.FunctionConfig.(Defaulter); ok {
+		if err := d.Default(); err != nil {
+			return err
+		}
+	}
+	if r.FunctionConfigSchema != nil {
+		if err := r.validateSchema(); err != nil {
+			return err
+		}
+	}
+	if v, ok := r.FunctionConfig.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// validateSchema validates the defaulted functionConfig against the
+// FunctionConfigSchema, and returns a Result with an item for each invalid field.
+func (r *ResourceList) validateSchema() error {
+	// validate the JSON value of the functionConfig, as the API server would
+	node, ok := r.FunctionConfig.(*yaml.RNode)
+	if !ok {
+		b, err := yaml.Marshal(r.FunctionConfig)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		node, err = yaml.Parse(string(b))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	j, err := node.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(j, &value); err != nil {
+		return errors.Wrap(err)
+	}
+
+	err = validate.AgainstSchema(r.FunctionConfigSchema, value, strfmt.Default)
+	if err == nil {
+		return nil
+	}
+	result := Result{Name: "functionConfig"}
+	var errs []error
+	if c, ok := err.(*openapierrors.CompositeError); ok {
+		errs = c.Errors
+	} else {
+		errs = []error{err}
+	}
+	for _, e := range errs {
+		item := Item{Severity: Error, Message: e.Error()}
+		if v, ok := e.(*openapierrors.Validation); ok {
+			item.Field.Path = v.Name
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result
+}
+
+// SchemaFor returns an OpenAPI schema for the type of v, e.g. a functionConfig, based
+// on the yaml tags of its fields.  Fields tagged omitempty are optional, others are
+// required.  The schema may be extended, e.g. with enums or patterns, and set as the
+// ResourceList.FunctionConfigSchema.
+func SchemaFor(v interface{}) *spec.Schema {
+	return schemaForType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+// schemaForType returns the schema for t.  visiting contains the struct types whose
+// schemas are being built, whose recursive fields may contain any value.
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) *spec.Schema {
+	if t == nil {
+		return &spec.Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return spec.StringProperty()
+	case reflect.Bool:
+		return spec.BoolProperty()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(spec.Schema).Typed("integer", "")
+	case reflect.Float32, reflect.Float64:
+		return new(spec.Schema).Typed("number", "")
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return new(spec.Schema).Typed("string", "byte")
+		}
+		return spec.ArrayProperty(schemaForType(t.Elem(), visiting))
+	case reflect.Map:
+		return spec.MapProperty(schemaForType(t.Elem(), visiting))
+	case reflect.Struct:
+		if visiting[t] {
+			return &spec.Schema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		s := new(spec.Schema).Typed("object", "")
+		addProperties(s, t, visiting)
+		return s
+	}
+	return &spec.Schema{}
+}
+
+// addProperties adds the fields of the struct type t as properties of s.
+func addProperties(s *spec.Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("yaml"), ",")
+		name := tag[0]
+		var inline, omitempty bool
+		for _, option := range tag[1:] {
+			inline = inline || option == "inline"
+			omitempty = omitempty || option == "omitempty"
+		}
+		if name == "-" || (f.PkgPath != "" && !inline) {
+			continue
+		}
+		if inline {
+			// the fields of inlined structs are fields of t
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addProperties(s, ft, visiting)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		s.SetProperty(name, *schemaForType(f.Type, visiting))
+		if !omitempty {
+			s.AddRequired(name)
+		}
+	}
+}
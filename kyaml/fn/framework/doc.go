@@ -96,7 +96,19 @@
 //
 // Validator Functions
 //
-// A function may emit validation results by setting the ResourceList.Result
+// A function may emit validation results by setting the ResourceList.Result, or by adding
+// result items with ResourceList.AddResults.  ResultItem creates an item which refers
+// to a resource, the file it was read from and optionally one of its fields.
+//
+//    rl.AddResults(framework.ResultItem(framework.Warning, "missing app label",
+//      rl.Items[i], "metadata", "labels"))
+//
+// Selecting Resources
+//
+// A functionConfig may embed a Selector to select the items the function applies to by
+// group, version, kind, name, namespace, labels and annotations.
+//
+//    items, err := functionConfig.Spec.Selector.Filter(rl.Items)
 //
 // Configuring Functions
 //
@@ -111,12 +123,29 @@
 //
 // Functions may also access environment variables set by the caller.
 //
+// Typed functionConfigs
+//
+// A functionConfig struct may implement Defaulter and Validator, which ResourceList.Read()
+// calls after parsing it.  ResourceList.Read() also validates the functionConfig against
+// the ResourceList.FunctionConfigSchema, e.g. one created with SchemaFor, and returns a
+// Result with the invalid fields.
+//
+//    functionConfig := &Example{}
+//    schema := framework.SchemaFor(functionConfig)
+//    rl := framework.ResourceList{FunctionConfig: functionConfig, FunctionConfigSchema: schema}
+//
+// Testing Functions
+//
+// The frameworktestutil package runs a function against the directories of a testdata
+// directory, each containing a functionConfig, the input items and the expected output.
+//
 // Building a container image for the function
 //
 // The go program must be built into a container to be run as a function.  The framework
-// can be used to generate a Dockerfile to build the function container.
+// can be used to generate a Dockerfile to build the function container, along with a
+// schema.json file containing the OpenAPI schema of the functionConfig.
 //
-//   # create the ./Dockerfile for the container
+//   # create the ./Dockerfile and ./schema.json for the container
 //   $ go run ./main.go gen ./
 //
 //   # build the function's container
@@ -4,12 +4,15 @@
 package framework
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 
+	"github.com/go-openapi/spec"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/kyaml/errors"
@@ -38,6 +41,11 @@ type ResourceList struct {
 	// FunctionConfig will contain the Example unmarshalled into its value.
 	FunctionConfig interface{}
 
+	// FunctionConfigSchema is an OpenAPI schema to validate the functionConfig against.
+	// If set, ResourceList.Read() validates the functionConfig after defaulting it,
+	// and returns a Result with the invalid fields.  See SchemaFor.
+	FunctionConfigSchema *spec.Schema
+
 	// Items is the ResourceList.items input and output value.  Items will be set by
 	// ResourceList.Read() and written by ResourceList.Write().
 	//
@@ -105,7 +113,7 @@ func (r *ResourceList) Read() error {
 	}
 
 	// parse the functionConfig
-	return func() error {
+	err = func() error {
 		if r.rw.FunctionConfig == nil {
 			// no function config exists
 			return nil
@@ -138,6 +146,13 @@ func (r *ResourceList) Read() error {
 			return f.Value.Set(node.Value.YNode().Value)
 		})
 	}()
+	if err != nil {
+		return err
+	}
+	if r.rw.FunctionConfig == nil {
+		return nil
+	}
+	return r.defaultAndValidate()
 }
 
 // Write writes the ResourceList
@@ -161,18 +176,27 @@ func (r *ResourceList) Write() error {
 	return r.rw.Write(r.Items)
 }
 
+// AddResults adds items to the ResourceList.Result written by ResourceList.Write().
+func (r *ResourceList) AddResults(items ...Item) {
+	if r.Result == nil {
+		r.Result = &Result{}
+	}
+	r.Result.Items = append(r.Result.Items, items...)
+}
+
 // Command returns a cobra.Command to run a function.
 //
 // The cobra.Command will use the provided ResourceList to Read() the input,
 // run the provided function, and then Write() the output.
 //
 // The returned cobra.Command will have a "gen" subcommand which can be used to generate
-// a Dockerfile to build the function into a container image
+// a Dockerfile to build the function into a container image, and the OpenAPI schema
+// of its functionConfig
 //
 //		go run main.go gen DIR/
 func Command(resourceList *ResourceList, function Function) cobra.Command {
 	cmd := cobra.Command{}
-	AddGenerateDockerfile(&cmd)
+	AddGenerateCommand(&cmd, resourceList)
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		err := execute(resourceList, function, cmd)
 		if err != nil {
@@ -188,11 +212,19 @@ func Command(resourceList *ResourceList, function Function) cobra.Command {
 // AddGenerateDockerfile adds a "gen" subcommand to create a Dockerfile for building
 // the function as a container.
 func AddGenerateDockerfile(cmd *cobra.Command) {
+	AddGenerateCommand(cmd, nil)
+}
+
+// AddGenerateCommand adds a "gen" subcommand to create a Dockerfile for building
+// the function as a container, and a schema.json file containing the OpenAPI schema
+// of its functionConfig: the ResourceList.FunctionConfigSchema, or else the SchemaFor
+// the ResourceList.FunctionConfig struct, if any.
+func AddGenerateCommand(cmd *cobra.Command, rl *ResourceList) {
 	gen := &cobra.Command{
 		Use:  "gen",
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ioutil.WriteFile(filepath.Join(args[0], "Dockerfile"), []byte(`FROM golang:1.13-stretch
+			err := ioutil.WriteFile(filepath.Join(args[0], "Dockerfile"), []byte(`FROM golang:1.13-stretch
 ENV CGO_ENABLED=0
 WORKDIR /go/src/
 COPY . .
@@ -202,17 +234,59 @@ FROM alpine:latest
 COPY --from=0 /usr/local/bin/function /usr/local/bin/function
 CMD ["function"]
 `), 0600)
+			if err != nil {
+				return err
+			}
+			schema := functionConfigSchema(rl)
+			if schema == nil {
+				return nil
+			}
+			b, err := json.MarshalIndent(schema, "", "  ")
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			return ioutil.WriteFile(
+				filepath.Join(args[0], "schema.json"), append(b, '\n'), 0600)
 		},
 	}
 	cmd.AddCommand(gen)
 }
 
+// functionConfigSchema returns the schema of the functionConfig of rl, or nil if
+// it isn't typed.
+func functionConfigSchema(rl *ResourceList) *spec.Schema {
+	if rl == nil {
+		return nil
+	}
+	if rl.FunctionConfigSchema != nil {
+		return rl.FunctionConfigSchema
+	}
+	if rl.FunctionConfig == nil {
+		return nil
+	}
+	t := reflect.TypeOf(rl.FunctionConfig)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(yaml.RNode{}) {
+		return nil
+	}
+	return SchemaFor(rl.FunctionConfig)
+}
+
 func execute(rl *ResourceList, function Function, cmd *cobra.Command) error {
 	rl.Reader = cmd.InOrStdin()
 	rl.Writer = cmd.OutOrStdout()
 	rl.Flags = cmd.Flags()
 
 	if err := rl.Read(); err != nil {
+		if result, ok := err.(Result); ok {
+			// write the results for an invalid functionConfig
+			rl.Result = &result
+			if err := rl.Write(); err != nil {
+				return err
+			}
+		}
 		return err
 	}
 
@@ -4,6 +4,7 @@
 package framework_test
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func TestCommand_dockerfile(t *testing.T) {
@@ -50,3 +53,229 @@ CMD ["function"]
 		t.FailNow()
 	}
 }
+
+func TestCommand_schema(t *testing.T) {
+	d, err := ioutil.TempDir("", "kustomize")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+
+	// create a function with a typed functionConfig
+	type Spec struct {
+		Value    string            `yaml:"value"`
+		Replicas int               `yaml:"replicas,omitempty"`
+		Labels   map[string]string `yaml:"labels,omitempty"`
+	}
+	type Example struct {
+		Spec Spec `yaml:"spec,omitempty"`
+	}
+	resourceList := &framework.ResourceList{FunctionConfig: &Example{}}
+	cmd := framework.Command(resourceList, func() error { return nil })
+
+	// generate the schema
+	cmd.SetArgs([]string{"gen", d})
+	if !assert.NoError(t, cmd.Execute()) {
+		t.FailNow()
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(d, "schema.json"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	expected := `{
+  "type": "object",
+  "properties": {
+    "spec": {
+      "type": "object",
+      "required": [
+        "value"
+      ],
+      "properties": {
+        "labels": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "replicas": {
+          "type": "integer"
+        },
+        "value": {
+          "type": "string"
+        }
+      }
+    }
+  }
+}
+`
+	if !assert.Equal(t, expected, string(b)) {
+		t.FailNow()
+	}
+}
+
+func TestResourceList_Read_schema(t *testing.T) {
+	type Example struct {
+		Spec struct {
+			Value string `yaml:"value,omitempty"`
+		} `yaml:"spec,omitempty"`
+	}
+	functionConfig := &Example{}
+	schema := framework.SchemaFor(functionConfig)
+	value := schema.Properties["spec"].Properties["value"]
+	value.WithPattern("^[a-z]+$")
+	schema.Properties["spec"].Properties["value"] = value
+
+	rl := framework.ResourceList{
+		FunctionConfig:       functionConfig,
+		FunctionConfigSchema: schema,
+		Reader: bytes.NewBufferString(`
+apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items: []
+functionConfig:
+  spec:
+    value: Foo
+`),
+	}
+	err := rl.Read()
+	result, ok := err.(framework.Result)
+	if !assert.True(t, ok, "expected a Result, got %v", err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 1, result.ExitCode())
+	if assert.Len(t, result.Items, 1) {
+		assert.Equal(t, framework.Error, result.Items[0].Severity)
+		assert.Equal(t, "spec.value", result.Items[0].Field.Path)
+	}
+}
+
+func TestSelector_Filter(t *testing.T) {
+	items, err := (&kio.ByteReader{OmitReaderAnnotations: true, Reader: bytes.NewBufferString(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+    tier: frontend
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+  namespace: storage
+  labels:
+    app: db
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    expose: "true"
+`)}).Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	tests := []struct {
+		name     string
+		selector framework.Selector
+		expected []string
+	}{
+		{name: "empty", expected: []string{"Deployment/web", "Deployment/db", "Service/web"}},
+		{name: "group", selector: framework.Selector{Group: "apps"},
+			expected: []string{"Deployment/web", "Deployment/db"}},
+		{name: "version", selector: framework.Selector{Version: "v1"},
+			expected: []string{"Deployment/web", "Deployment/db", "Service/web"}},
+		{name: "kind", selector: framework.Selector{Kind: "Service"},
+			expected: []string{"Service/web"}},
+		{name: "name", selector: framework.Selector{Name: "w.*"},
+			expected: []string{"Deployment/web", "Service/web"}},
+		{name: "name anchored", selector: framework.Selector{Name: "e"}},
+		{name: "namespace", selector: framework.Selector{Namespace: "stor.*"},
+			expected: []string{"Deployment/db"}},
+		{name: "label equals", selector: framework.Selector{LabelSelector: "app=web"},
+			expected: []string{"Deployment/web"}},
+		{name: "label not equals", selector: framework.Selector{LabelSelector: "app!=web"},
+			expected: []string{"Deployment/db", "Service/web"}},
+		{name: "label exists", selector: framework.Selector{LabelSelector: "app,!tier"},
+			expected: []string{"Deployment/db"}},
+		{name: "label in", selector: framework.Selector{LabelSelector: "app in (db, web), tier notin (backend)"},
+			expected: []string{"Deployment/web", "Deployment/db"}},
+		{name: "annotation", selector: framework.Selector{AnnotationSelector: "expose==true"},
+			expected: []string{"Service/web"}},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			selected, err := test.selector.Filter(items)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var actual []string
+			for _, item := range selected {
+				meta, err := item.GetMeta()
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				actual = append(actual, meta.Kind+"/"+meta.Name)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestSelector_Filter_invalid(t *testing.T) {
+	items, err := (&kio.ByteReader{Reader: bytes.NewBufferString(`
+kind: Service
+metadata:
+  name: web
+`)}).Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = framework.Selector{LabelSelector: "app in"}.Filter(items)
+	assert.EqualError(t, err, `invalid label selector requirement "app in"`)
+}
+
+func TestResultItem(t *testing.T) {
+	item, err := yaml.Parse(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    config.kubernetes.io/path: deploy.yaml
+    config.kubernetes.io/index: '1'
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	actual := framework.ResultItem(framework.Warning, "image has no digest", item,
+		"spec", "template", "spec", "containers", "[name=app]", "image")
+	expected := framework.Item{
+		Message:  "image has no digest",
+		Severity: framework.Warning,
+		ResourceRef: yaml.ResourceMeta{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			ObjectMeta: yaml.ObjectMeta{Name: "web", Namespace: "default"},
+		},
+		Field: framework.Field{
+			Path:         "spec.template.spec.containers[name=app].image",
+			CurrentValue: "app:v1",
+		},
+		File: framework.File{Path: "deploy.yaml", Index: 1},
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package frameworktestutil contains utilities for testing functions written
+// using the framework.
+package frameworktestutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// CommandResultsChecker runs a function against golden test cases, the
+// subdirectories of TestDataDirectory, and compares its output with their
+// expected output.
+//
+// Each test case directory may contain:
+//
+//	config.yaml    the functionConfig
+//	input.yaml     the items
+//	expected.yaml  the expected output ResourceList, including its results
+//	error.txt      a substring of the expected error
+//
+// e.g.
+//
+//	func TestFunction(t *testing.T) {
+//	  frameworktestutil.CommandResultsChecker{Command: func() cobra.Command {
+//	    rl := &framework.ResourceList{FunctionConfig: &Config{}}
+//	    return framework.Command(rl, run(rl))
+//	  }}.Assert(t)
+//	}
+type CommandResultsChecker struct {
+	// TestDataDirectory is the directory containing the test cases.
+	// Defaults to "testdata".
+	TestDataDirectory string
+
+	// ConfigInputFilename defaults to "config.yaml".
+	ConfigInputFilename string
+
+	// InputFilename defaults to "input.yaml".
+	InputFilename string
+
+	// ExpectedOutputFilename defaults to "expected.yaml".
+	ExpectedOutputFilename string
+
+	// ExpectedErrorFilename defaults to "error.txt".
+	ExpectedErrorFilename string
+
+	// UpdateExpectedFromActual if set will write the actual output and error of
+	// each test case to its expected files rather than comparing them, to create
+	// or update the golden files.
+	UpdateExpectedFromActual bool
+
+	// Command returns a new command running the function, e.g. framework.Command.
+	Command func() cobra.Command
+}
+
+// Assert runs the function against each test case.
+func (c CommandResultsChecker) Assert(t *testing.T) {
+	c.setDefaults()
+	dirs, err := ioutil.ReadDir(c.TestDataDirectory)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(c.TestDataDirectory, d.Name())
+		t.Run(d.Name(), func(t *testing.T) {
+			c.assert(t, dir)
+		})
+	}
+}
+
+func (c *CommandResultsChecker) setDefaults() {
+	if c.TestDataDirectory == "" {
+		c.TestDataDirectory = "testdata"
+	}
+	if c.ConfigInputFilename == "" {
+		c.ConfigInputFilename = "config.yaml"
+	}
+	if c.InputFilename == "" {
+		c.InputFilename = "input.yaml"
+	}
+	if c.ExpectedOutputFilename == "" {
+		c.ExpectedOutputFilename = "expected.yaml"
+	}
+	if c.ExpectedErrorFilename == "" {
+		c.ExpectedErrorFilename = "error.txt"
+	}
+}
+
+// assert runs the function against the test case in dir.
+func (c CommandResultsChecker) assert(t *testing.T, dir string) {
+	input, err := c.resourceList(dir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	cmd := c.Command()
+	out := &bytes.Buffer{}
+	cmd.SetIn(input)
+	cmd.SetOut(out)
+	cmd.SetErr(ioutil.Discard)
+	cmd.SetArgs([]string{})
+	err = cmd.Execute()
+
+	expectedOutput := filepath.Join(dir, c.ExpectedOutputFilename)
+	expectedError := filepath.Join(dir, c.ExpectedErrorFilename)
+	if c.UpdateExpectedFromActual {
+		if err != nil {
+			assert.NoError(t, ioutil.WriteFile(expectedError, []byte(err.Error()+"\n"), 0600))
+		} else {
+			assert.NoError(t, ioutil.WriteFile(expectedOutput, out.Bytes(), 0600))
+		}
+		return
+	}
+
+	if b, readErr := ioutil.ReadFile(expectedError); readErr == nil {
+		if !assert.Error(t, err) {
+			t.FailNow()
+		}
+		assert.Contains(t, err.Error(), strings.TrimSpace(string(b)))
+	} else if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	b, readErr := ioutil.ReadFile(expectedOutput)
+	if os.IsNotExist(readErr) && err != nil {
+		// only the error is expected
+		return
+	}
+	if !assert.NoError(t, readErr) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(string(b)), strings.TrimSpace(out.String()))
+}
+
+// resourceList returns the ResourceList input of the test case in dir.
+func (c CommandResultsChecker) resourceList(dir string) (*bytes.Buffer, error) {
+	var items []*yaml.RNode
+	b, err := ioutil.ReadFile(filepath.Join(dir, c.InputFilename))
+	if err == nil {
+		items, err = (&kio.ByteReader{
+			Reader: bytes.NewReader(b), OmitReaderAnnotations: true}).Read()
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var config *yaml.RNode
+	b, err = ioutil.ReadFile(filepath.Join(dir, c.ConfigInputFilename))
+	if err == nil {
+		config, err = yaml.Parse(string(b))
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	input := &bytes.Buffer{}
+	err = kio.ByteWriter{
+		Writer:             input,
+		WrappingKind:       kio.ResourceListKind,
+		WrappingAPIVersion: kio.ResourceListAPIVersion,
+		FunctionConfig:     config,
+	}.Write(items)
+	return input, err
+}
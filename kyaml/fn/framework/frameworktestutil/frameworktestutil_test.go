@@ -0,0 +1,64 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package frameworktestutil_test
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework/frameworktestutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type AnnotateConfig struct {
+	Spec AnnotateSpec `yaml:"spec"`
+}
+
+type AnnotateSpec struct {
+	Selector framework.Selector `yaml:"selector,omitempty"`
+	Value    string             `yaml:"value,omitempty"`
+}
+
+func (c *AnnotateConfig) Default() error {
+	if c.Spec.Value == "" {
+		c.Spec.Value = "default"
+	}
+	return nil
+}
+
+// annotate annotates the selected items, and warns about the items without
+// an app label.
+func annotate() cobra.Command {
+	config := &AnnotateConfig{}
+	schema := framework.SchemaFor(config)
+	value := schema.Properties["spec"].Properties["value"]
+	value.Pattern = "^[a-z]+$"
+	schema.Properties["spec"].Properties["value"] = value
+	rl := &framework.ResourceList{FunctionConfig: config, FunctionConfigSchema: schema}
+	return framework.Command(rl, func() error {
+		items, err := config.Spec.Selector.Filter(rl.Items)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if err := items[i].PipeE(yaml.SetAnnotation("value", config.Spec.Value)); err != nil {
+				return err
+			}
+			meta, err := items[i].GetMeta()
+			if err != nil {
+				return err
+			}
+			if meta.Labels["app"] == "" {
+				rl.AddResults(framework.ResultItem(
+					framework.Warning, "missing app label", items[i], "metadata", "labels"))
+			}
+		}
+		return nil
+	})
+}
+
+func TestCommandResultsChecker(t *testing.T) {
+	frameworktestutil.CommandResultsChecker{Command: annotate}.Assert(t)
+}
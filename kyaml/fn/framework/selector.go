@@ -0,0 +1,179 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Selector selects the ResourceList.Items that a function applies to, like the
+// selectors of kustomize patches and replacements.  Empty fields match any item.
+//
+// e.g. a functionConfig may embed a Selector
+//
+//	type Spec struct {
+//	  Selector framework.Selector `yaml:"selector,omitempty"`
+//	}
+//
+// and select the items
+//
+//	items, err := spec.Selector.Filter(rl.Items)
+type Selector struct {
+	Group   string `yaml:"group,omitempty"`
+	Version string `yaml:"version,omitempty"`
+	Kind    string `yaml:"kind,omitempty"`
+
+	// Name is a regular expression matching the whole name.
+	Name string `yaml:"name,omitempty"`
+
+	// Namespace is a regular expression matching the whole namespace.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// AnnotationSelector is a label selector expression, e.g. "a=b,c in (d,e)",
+	// matching the annotations.
+	AnnotationSelector string `yaml:"annotationSelector,omitempty"`
+
+	// LabelSelector is a label selector expression, e.g. "a=b,c in (d,e)",
+	// matching the labels.
+	LabelSelector string `yaml:"labelSelector,omitempty"`
+}
+
+var _ kio.Filter = Selector{}
+
+// Filter returns the items matched by the Selector.
+func (s Selector) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
+	var selected []*yaml.RNode
+	for i := range items {
+		matched, err := s.Match(items[i])
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			selected = append(selected, items[i])
+		}
+	}
+	return selected, nil
+}
+
+// Match returns whether the Selector matches the item.
+func (s Selector) Match(item *yaml.RNode) (bool, error) {
+	meta, err := item.GetMeta()
+	if err != nil {
+		return false, errors.Wrap(err)
+	}
+	group, version := "", meta.APIVersion
+	if i := strings.Index(meta.APIVersion, "/"); i >= 0 {
+		group, version = meta.APIVersion[:i], meta.APIVersion[i+1:]
+	}
+	for _, m := range [][2]string{
+		{s.Group, group}, {s.Version, version}, {s.Kind, meta.Kind}} {
+		if m[0] != "" && m[0] != m[1] {
+			return false, nil
+		}
+	}
+	for _, m := range [][2]string{{s.Name, meta.Name}, {s.Namespace, meta.Namespace}} {
+		if m[0] == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + m[0] + ")$")
+		if err != nil {
+			return false, errors.Wrap(err)
+		}
+		if !re.MatchString(m[1]) {
+			return false, nil
+		}
+	}
+	for _, m := range []struct {
+		selector string
+		values   map[string]string
+	}{{s.LabelSelector, meta.Labels}, {s.AnnotationSelector, meta.Annotations}} {
+		matched, err := matchLabelSelector(m.selector, m.values)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchLabelSelector returns whether the labels match the label selector expression,
+// a comma separated list of requirements: key, !key, key=value, key==value,
+// key!=value, key in (values) and key notin (values).
+func matchLabelSelector(selector string, labels map[string]string) (bool, error) {
+	for _, r := range splitRequirements(selector) {
+		matched, err := matchRequirement(r, labels)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// splitRequirements splits a label selector at the commas outside parentheses.
+func splitRequirements(selector string) []string {
+	var requirements []string
+	var depth, start int
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				requirements = append(requirements, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	requirements = append(requirements, selector[start:])
+	var nonEmpty []string
+	for _, r := range requirements {
+		if r = strings.TrimSpace(r); r != "" {
+			nonEmpty = append(nonEmpty, r)
+		}
+	}
+	return nonEmpty
+}
+
+// setRequirement matches the label selector requirements key in (values) and
+// key notin (values).
+var setRequirement = regexp.MustCompile(`^([^\s!=(),]+)\s+(in|notin)\s*\((.*)\)$`)
+
+func matchRequirement(r string, labels map[string]string) (bool, error) {
+	if m := setRequirement.FindStringSubmatch(r); m != nil {
+		value, found := labels[m[1]]
+		in := false
+		for _, v := range strings.Split(m[3], ",") {
+			in = in || (found && strings.TrimSpace(v) == value)
+		}
+		return in == (m[2] == "in"), nil
+	}
+	for _, op := range []string{"!=", "==", "="} {
+		if i := strings.Index(r, op); i >= 0 {
+			key, value := strings.TrimSpace(r[:i]), strings.TrimSpace(r[i+len(op):])
+			if key == "" {
+				return false, errors.Errorf("invalid label selector requirement %q", r)
+			}
+			v, found := labels[key]
+			if op == "!=" {
+				return !found || v != value, nil
+			}
+			return found && v == value, nil
+		}
+	}
+	if strings.HasPrefix(r, "!") {
+		_, found := labels[strings.TrimSpace(r[1:])]
+		return !found, nil
+	}
+	if strings.ContainsAny(r, " ()") {
+		return false, errors.Errorf("invalid label selector requirement %q", r)
+	}
+	_, found := labels[r]
+	return found, nil
+}
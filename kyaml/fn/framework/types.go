@@ -4,8 +4,10 @@
 package framework
 
 import (
+	"strconv"
 	"strings"
 
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -90,3 +92,37 @@ func (e Result) ExitCode() int {
 	}
 	return 0
 }
+
+// ResultItem returns an Item about a resource, which refers to the resource and the
+// file it was read from, and to the field if a field path is given.
+//
+//	ResultItem(Warning, "image has no digest", item,
+//	  "spec", "template", "spec", "containers", "[name=app]", "image")
+func ResultItem(severity Severity, message string, resource *yaml.RNode, field ...string) Item {
+	item := Item{Severity: severity, Message: message}
+	if meta, err := resource.GetMeta(); err == nil {
+		item.ResourceRef = yaml.ResourceMeta{
+			APIVersion: meta.APIVersion,
+			Kind:       meta.Kind,
+			ObjectMeta: yaml.ObjectMeta{Name: meta.Name, Namespace: meta.Namespace},
+		}
+	}
+	if path, index, err := kioutil.GetFileAnnotations(resource); err == nil && path != "" {
+		item.File.Path = path
+		item.File.Index, _ = strconv.Atoi(index)
+	}
+	if len(field) == 0 {
+		return item
+	}
+	for i, f := range field {
+		if i > 0 && !strings.HasPrefix(f, "[") {
+			item.Field.Path += "."
+		}
+		item.Field.Path += f
+	}
+	if value, err := resource.Pipe(yaml.Lookup(field...)); err == nil && value != nil &&
+		value.YNode().Kind == yaml.ScalarNode {
+		item.Field.CurrentValue = value.YNode().Value
+	}
+	return item
+}
@@ -146,6 +146,10 @@ func (c Filter) GetExit() error {
 	return c.Exec.GetExit()
 }
 
+func (c Filter) GetResults() *yaml.RNode {
+	return c.Exec.GetResults()
+}
+
 func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	c.setupExec()
 	return c.Exec.Filter(nodes)
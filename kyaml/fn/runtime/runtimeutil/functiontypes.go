@@ -34,6 +34,9 @@ type FunctionSpec struct {
 	// ExecSpec is the spec for running a function as an executable
 	Exec ExecSpec `json:"exec,omitempty" yaml:"exec,omitempty"`
 
+	// Wasm is the spec for running a function as a WebAssembly module
+	Wasm WasmSpec `json:"wasm,omitempty" yaml:"wasm,omitempty"`
+
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
 }
@@ -69,6 +72,26 @@ type StarlarkSpec struct {
 
 	// URL specifies a url containing a starlark script
 	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Args are parameters for the script, which it reads from ctx.args
+	Args map[string]interface{} `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// WasmSpec defines how to run a function as a WebAssembly module
+// compiled for WASI
+type WasmSpec struct {
+	// Path specifies a path to a .wasm module
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Module is a base64 encoded .wasm module, embedded instead of read
+	// from Path
+	Module string `json:"module,omitempty" yaml:"module,omitempty"`
+
+	// MemoryLimitMiB caps the memory of the module, in MiB
+	MemoryLimitMiB uint32 `json:"memoryLimitMiB,omitempty" yaml:"memoryLimitMiB,omitempty"`
+
+	// TimeoutSeconds caps how long the module may run
+	TimeoutSeconds uint32 `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
 }
 
 // StorageMount represents a container's mounted storage option(s)
@@ -55,6 +55,11 @@ func (c FunctionFilter) GetExit() error {
 	return c.exit
 }
 
+// GetResults returns the ResourceList.results emitted by Run
+func (c FunctionFilter) GetResults() *yaml.RNode {
+	return c.results
+}
+
 // functionsDirectoryName is keyword directory name for functions scoped 1 directory higher
 const functionsDirectoryName = "functions"
 
@@ -1273,6 +1273,28 @@ container:
 `,
 		},
 
+		{
+			name: "starlark args",
+			resource: `
+apiVersion: v1beta1
+kind: Example
+metadata:
+  annotations:
+    config.kubernetes.io/function: |-
+      starlark:
+        path: fn.star
+        args:
+          tier: frontend
+          replicas: 3
+`,
+			expectedFn: `
+starlark:
+    path: fn.star
+    args:
+        replicas: 3
+        tier: frontend`,
+		},
+
 		// no fn
 		{name: "no fn",
 			resource: `
@@ -3,6 +3,13 @@
 
 package runtimeutil
 
+import "sigs.k8s.io/kustomize/kyaml/yaml"
+
 type DeferFailureFunction interface {
 	GetExit() error
 }
+
+// ResultsFunction is a function which saves the ResourceList.results it emits.
+type ResultsFunction interface {
+	GetResults() *yaml.RNode
+}
@@ -18,6 +18,7 @@ import (
 
 type Context struct {
 	resourceList starlark.Value
+	args         starlark.Value
 }
 
 func (c *Context) predeclared() (starlark.StringDict, error) {
@@ -33,10 +34,12 @@ func (c *Context) predeclared() (starlark.StringDict, error) {
 		"resource_list": c.resourceList,
 		"open_api":      oa,
 		"environment":   e,
+		"args":          c.args,
 	}
 
 	return starlark.StringDict{
 		"ctx": starlarkstruct.FromStringDict(starlarkstruct.Default, dict),
+		"krm": krm,
 	}, nil
 }
 
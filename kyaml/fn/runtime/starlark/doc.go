@@ -21,6 +21,15 @@
 // Changes made by the starlark program to the "functionConfig" will be reflected in the
 // Filter.FunctionConfig value.
 //
+// Programs may also read parameters from "ctx.args", which are set by Filter.Args, and use
+// the predeclared "krm" module of helpers for field paths, label selectors, resource
+// identity and YAML encoding -- see krm.go for its functions.
+//
+// Programs read from Filter.Path may load() modules from the directory of the program,
+// e.g. load("lib/labels.star", "add_labels").  Modules outside of that directory can't be
+// loaded.  Modules are frozen once loaded, so they should define functions rather than
+// hold resources.
+//
 // The Filter will also format the output so that output has the preferred field ordering
 // rather than an alphabetical field ordering.
 //
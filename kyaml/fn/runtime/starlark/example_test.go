@@ -293,3 +293,235 @@ run(ctx.resource_list["items"])
 	//       - name: nginx
 	//         image: nginx:1.7.9 # {"$ref": "#/definitions/io.k8s.cli.substitutions.image-2"}
 }
+
+// ExampleFilter_Filter_krm uses the krm module and args to label the
+// Deployments selected by the args.
+func ExampleFilter_Filter_krm() {
+	input := bytes.NewBufferString(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-1
+  labels:
+    app: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-2
+  labels:
+    app: db
+spec:
+  template:
+    spec:
+      containers:
+      - name: postgres
+        image: postgres:12
+`)
+
+	fltr := &starlark.Filter{
+		Name: "label",
+		Program: `
+def run(items, args):
+  for item in items:
+    if not krm.match_selector(item, args["selector"]):
+      continue
+    krm.set(item, "spec.template.metadata.labels.tier", args["tier"])
+    image = krm.get(item, "spec.template.spec.containers[name=nginx].image")
+    krm.set(item, 'metadata.annotations["example.com/image"]', image)
+    id = krm.id(item)
+    krm.set(item, 'metadata.annotations["example.com/id"]', id.kind + "/" + id.name)
+
+run(ctx.resource_list["items"], ctx.args)
+`,
+		Args: map[string]interface{}{
+			"tier": "frontend",
+			"selector": map[string]interface{}{
+				"matchExpressions": []interface{}{
+					map[string]interface{}{
+						"key": "app", "operator": "In", "values": []interface{}{"web"}},
+				},
+			},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{&kio.ByteReader{Reader: input}},
+		Filters: []kio.Filter{fltr},
+		Outputs: []kio.Writer{&kio.ByteWriter{
+			Writer:           output,
+			ClearAnnotations: []string{"config.kubernetes.io/path"},
+		}}}.Execute()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(output.String())
+
+	// Output:
+	// apiVersion: apps/v1
+	// kind: Deployment
+	// metadata:
+	//   name: deployment-1
+	//   labels:
+	//     app: web
+	//   annotations:
+	//     example.com/id: Deployment/deployment-1
+	//     example.com/image: nginx:1.8.1
+	// spec:
+	//   template:
+	//     metadata:
+	//       labels:
+	//         tier: frontend
+	//     spec:
+	//       containers:
+	//       - name: nginx
+	//         image: nginx:1.8.1
+	//---
+	// apiVersion: apps/v1
+	// kind: Deployment
+	// metadata:
+	//   name: deployment-2
+	//   labels:
+	//     app: db
+	// spec:
+	//   template:
+	//     spec:
+	//       containers:
+	//       - name: postgres
+	//         image: postgres:12
+}
+
+// ExampleFilter_Filter_load loads a module from the directory of the
+// program.
+func ExampleFilter_Filter_load() {
+	d, err := ioutil.TempDir("", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	err = ioutil.WriteFile(filepath.Join(d, "lib.star"), []byte(`
+def annotate(item, key, value):
+  krm.set(item, ["metadata", "annotations", key], value)
+`), 0600)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(d, "annotate.star"), []byte(`
+load("lib.star", "annotate")
+
+def run(items):
+  for item in items:
+    annotate(item, "example.com/owner", "team-a")
+
+run(ctx.resource_list["items"])
+`), 0600)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	input := bytes.NewBufferString(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: service-1 # the frontend
+spec:
+  ports:
+  - port: 80
+`)
+
+	fltr := &starlark.Filter{
+		Name: "annotate",
+		Path: filepath.Join(d, "annotate.star"),
+	}
+
+	output := &bytes.Buffer{}
+	err = kio.Pipeline{
+		Inputs:  []kio.Reader{&kio.ByteReader{Reader: input}},
+		Filters: []kio.Filter{fltr},
+		Outputs: []kio.Writer{&kio.ByteWriter{
+			Writer:           output,
+			ClearAnnotations: []string{"config.kubernetes.io/path"},
+		}}}.Execute()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(output.String())
+
+	// Output:
+	// apiVersion: v1
+	// kind: Service
+	// metadata:
+	//   name: service-1 # the frontend
+	//   annotations:
+	//     example.com/owner: team-a
+	// spec:
+	//   ports:
+	//   - port: 80
+}
+
+// ExampleFilter_Filter_yaml decodes YAML embedded in a ConfigMap, edits
+// it and encodes it again, keeping its comments.
+func ExampleFilter_Filter_yaml() {
+	input := bytes.NewBufferString(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  config.yaml: |
+    # the log level
+    level: info
+    # the listen port
+    port: 8080
+`)
+
+	fltr := &starlark.Filter{
+		Name: "configure",
+		Program: `
+def run(items):
+  for item in items:
+    s = krm.get(item, 'data["config.yaml"]')
+    config = krm.yaml_decode(s)
+    config["level"] = "debug"
+    krm.set(item, 'data["config.yaml"]', krm.yaml_encode(config, s))
+
+run(ctx.resource_list["items"])
+`,
+	}
+
+	output := &bytes.Buffer{}
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{&kio.ByteReader{Reader: input}},
+		Filters: []kio.Filter{fltr},
+		Outputs: []kio.Writer{&kio.ByteWriter{
+			Writer:           output,
+			ClearAnnotations: []string{"config.kubernetes.io/path"},
+		}}}.Execute()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(output.String())
+
+	// Output:
+	// apiVersion: v1
+	// kind: ConfigMap
+	// metadata:
+	//   name: app-config
+	// data:
+	//   config.yaml: |
+	//     # the log level
+	//     level: debug
+	//     # the listen port
+	//     port: 8080
+}
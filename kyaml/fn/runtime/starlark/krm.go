@@ -0,0 +1,491 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package starlark
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"sigs.k8s.io/kustomize/kyaml/comments"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// krm is the module of helpers for working with resources, predeclared
+// as "krm":
+//
+//	krm.get(obj, path, default=None)  the value at the field path, or default
+//	krm.set(obj, path, value)         sets the value at the field path
+//	krm.match_labels(obj, labels)     whether obj has all the labels
+//	krm.match_selector(obj, selector) whether obj matches a LabelSelector
+//	krm.id(obj)                       the group, version, kind, namespace and name
+//	krm.yaml_decode(s)                the value of a YAML document
+//	krm.yaml_encode(value, original="")
+//	                                  the YAML of value, keeping the comments of original
+//
+// Field paths are either strings, e.g. 'spec.containers[name=nginx].ports[0]'
+// or 'metadata.annotations["config.kubernetes.io/path"]', or lists of field
+// names and list indexes.
+var krm = &starlarkstruct.Module{
+	Name: "krm",
+	Members: starlark.StringDict{
+		"get":            starlark.NewBuiltin("get", krmGet),
+		"set":            starlark.NewBuiltin("set", krmSet),
+		"match_labels":   starlark.NewBuiltin("match_labels", krmMatchLabels),
+		"match_selector": starlark.NewBuiltin("match_selector", krmMatchSelector),
+		"id":             starlark.NewBuiltin("id", krmID),
+		"yaml_decode":    starlark.NewBuiltin("yaml_decode", krmYAMLDecode),
+		"yaml_encode":    starlark.NewBuiltin("yaml_encode", krmYAMLEncode),
+	},
+}
+
+// pathStep is a step of a field path: a field of a dict, an index of a
+// list, or the element of a list whose key field has the value.
+type pathStep struct {
+	field string
+	index int
+	key   string
+	value string
+}
+
+func (s pathStep) isField() bool { return s.key == "" && s.index < 0 }
+
+func (s pathStep) isSelector() bool { return s.key != "" }
+
+// parsePath parses a field path given as a string or list.
+func parsePath(v starlark.Value) ([]pathStep, error) {
+	switch p := v.(type) {
+	case starlark.String:
+		return parsePathString(string(p))
+	case *starlark.List:
+		var steps []pathStep
+		for i := 0; i < p.Len(); i++ {
+			switch e := p.Index(i).(type) {
+			case starlark.String:
+				steps = append(steps, pathStep{field: string(e), index: -1})
+			case starlark.Int:
+				n, ok := e.Int64()
+				if !ok || n < 0 {
+					return nil, errors.Errorf("list index %v out of range", e)
+				}
+				steps = append(steps, pathStep{index: int(n)})
+			default:
+				return nil, errors.Errorf(
+					"path elements must be strings or ints, got %s", e.Type())
+			}
+		}
+		if len(steps) == 0 {
+			return nil, errors.Errorf("empty path")
+		}
+		return steps, nil
+	}
+	return nil, errors.Errorf("path must be a string or list, got %s", v.Type())
+}
+
+func parsePathString(p string) ([]pathStep, error) {
+	var steps []pathStep
+	for i := 0; i < len(p); {
+		switch p[i] {
+		case '.':
+			i++
+		case '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, errors.Errorf("unclosed '[' in path %q", p)
+			}
+			step, err := parseBracket(p[i+1 : i+end])
+			if err != nil {
+				return nil, errors.WrapPrefixf(err, "path %q", p)
+			}
+			steps = append(steps, step)
+			i += end + 1
+		default:
+			end := strings.IndexAny(p[i:], ".[")
+			if end < 0 {
+				end = len(p) - i
+			}
+			steps = append(steps, pathStep{field: p[i : i+end], index: -1})
+			i += end
+		}
+	}
+	if len(steps) == 0 {
+		return nil, errors.Errorf("empty path")
+	}
+	return steps, nil
+}
+
+// parseBracket parses the inside of [...]: a quoted field name, a list
+// index or a key=value selector.
+func parseBracket(s string) (pathStep, error) {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return pathStep{field: s[1 : len(s)-1], index: -1}, nil
+	}
+	if kv := strings.SplitN(s, "=", 2); len(kv) == 2 {
+		return pathStep{key: kv[0], value: kv[1], index: -1}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return pathStep{}, errors.Errorf("invalid list element [%s]", s)
+	}
+	return pathStep{index: n}, nil
+}
+
+// lookup returns the value of the step in v, and whether it was found.
+func lookup(v starlark.Value, s pathStep) (starlark.Value, bool, error) {
+	if s.isField() {
+		d, ok := v.(*starlark.Dict)
+		if !ok {
+			return nil, false, nil
+		}
+		return d.Get(starlark.String(s.field))
+	}
+	l, ok := v.(*starlark.List)
+	if !ok {
+		return nil, false, nil
+	}
+	i := find(l, s)
+	if i < 0 {
+		return nil, false, nil
+	}
+	return l.Index(i), true, nil
+}
+
+// find returns the index of the list element of the step, or -1.
+func find(l *starlark.List, s pathStep) int {
+	if !s.isSelector() {
+		if s.index >= l.Len() {
+			return -1
+		}
+		return s.index
+	}
+	for i := 0; i < l.Len(); i++ {
+		d, ok := l.Index(i).(*starlark.Dict)
+		if !ok {
+			continue
+		}
+		v, found, err := d.Get(starlark.String(s.key))
+		if err == nil && found && valueString(v) == s.value {
+			return i
+		}
+	}
+	return -1
+}
+
+func valueString(v starlark.Value) string {
+	if s, ok := v.(starlark.String); ok {
+		return string(s)
+	}
+	return v.String()
+}
+
+func krmGet(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var obj, path starlark.Value
+	var def starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"obj", &obj, "path", &path, "default?", &def); err != nil {
+		return nil, err
+	}
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range steps {
+		v, found, err := lookup(obj, s)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return def, nil
+		}
+		obj = v
+	}
+	return obj, nil
+}
+
+func krmSet(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var obj, path, value starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"obj", &obj, "path", &path, "value", &value); err != nil {
+		return nil, err
+	}
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range steps {
+		last := i == len(steps)-1
+		if s.isField() {
+			d, ok := obj.(*starlark.Dict)
+			if !ok {
+				return nil, errors.Errorf(
+					"%s: can't set field %q of a %s", b.Name(), s.field, obj.Type())
+			}
+			if last {
+				return starlark.None, d.SetKey(starlark.String(s.field), value)
+			}
+			v, found, err := d.Get(starlark.String(s.field))
+			if err != nil {
+				return nil, err
+			}
+			if !found || v == starlark.None {
+				// create the missing fields
+				if steps[i+1].isField() {
+					v = starlark.NewDict(1)
+				} else {
+					v = starlark.NewList(nil)
+				}
+				if err := d.SetKey(starlark.String(s.field), v); err != nil {
+					return nil, err
+				}
+			}
+			obj = v
+			continue
+		}
+
+		l, ok := obj.(*starlark.List)
+		if !ok {
+			return nil, errors.Errorf(
+				"%s: can't set an element of a %s", b.Name(), obj.Type())
+		}
+		j := find(l, s)
+		if j < 0 && s.isSelector() {
+			// create the missing element
+			e := starlark.NewDict(1)
+			if err := e.SetKey(starlark.String(s.key), starlark.String(s.value)); err != nil {
+				return nil, err
+			}
+			if err := l.Append(e); err != nil {
+				return nil, err
+			}
+			j = l.Len() - 1
+		}
+		if j < 0 {
+			return nil, errors.Errorf(
+				"%s: list index %d out of range [0:%d]", b.Name(), s.index, l.Len())
+		}
+		if last {
+			return starlark.None, l.SetIndex(j, value)
+		}
+		obj = l.Index(j)
+	}
+	return starlark.None, nil
+}
+
+// labelsOf returns the labels of the resource.
+func labelsOf(obj starlark.Value) map[string]string {
+	labels := map[string]string{}
+	v, found, err := lookup(obj, pathStep{field: "metadata", index: -1})
+	if err != nil || !found {
+		return labels
+	}
+	v, found, err = lookup(v, pathStep{field: "labels", index: -1})
+	if err != nil || !found {
+		return labels
+	}
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return labels
+	}
+	for _, item := range d.Items() {
+		labels[valueString(item[0])] = valueString(item[1])
+	}
+	return labels
+}
+
+// stringDict converts a starlark dict of strings.
+func stringDict(v starlark.Value) (map[string]string, error) {
+	m := map[string]string{}
+	if v == starlark.None {
+		return m, nil
+	}
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, errors.Errorf("expected a dict, got %s", v.Type())
+	}
+	for _, item := range d.Items() {
+		m[valueString(item[0])] = valueString(item[1])
+	}
+	return m, nil
+}
+
+func matchLabels(labels, match map[string]string) bool {
+	for k, v := range match {
+		if l, ok := labels[k]; !ok || l != v {
+			return false
+		}
+	}
+	return true
+}
+
+func krmMatchLabels(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var obj, labels starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"obj", &obj, "labels", &labels); err != nil {
+		return nil, err
+	}
+	match, err := stringDict(labels)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, b.Name())
+	}
+	return starlark.Bool(matchLabels(labelsOf(obj), match)), nil
+}
+
+// krmMatchSelector matches the labels of the resource with a
+// metav1.LabelSelector, with matchLabels and matchExpressions.
+func krmMatchSelector(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var obj, selector starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"obj", &obj, "selector", &selector); err != nil {
+		return nil, err
+	}
+	v, err := util.Unmarshal(selector)
+	if err != nil {
+		return nil, err
+	}
+	var sel struct {
+		MatchLabels      map[string]string `yaml:"matchLabels"`
+		MatchExpressions []struct {
+			Key      string   `yaml:"key"`
+			Operator string   `yaml:"operator"`
+			Values   []string `yaml:"values"`
+		} `yaml:"matchExpressions"`
+	}
+	s, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(s, &sel); err != nil {
+		return nil, errors.WrapPrefixf(err, "%s: invalid selector", b.Name())
+	}
+
+	labels := labelsOf(obj)
+	if !matchLabels(labels, sel.MatchLabels) {
+		return starlark.False, nil
+	}
+	for _, e := range sel.MatchExpressions {
+		l, ok := labels[e.Key]
+		in := false
+		for _, v := range e.Values {
+			in = in || (ok && l == v)
+		}
+		var match bool
+		switch e.Operator {
+		case "In":
+			match = in
+		case "NotIn":
+			match = !in
+		case "Exists":
+			match = ok
+		case "DoesNotExist":
+			match = !ok
+		default:
+			return nil, errors.Errorf(
+				"%s: unknown selector operator %q", b.Name(), e.Operator)
+		}
+		if !match {
+			return starlark.False, nil
+		}
+	}
+	return starlark.True, nil
+}
+
+// krmID returns the identity of the resource, which compares equal to
+// the identity of resources with the same group, version, kind, namespace
+// and name.
+func krmID(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var obj starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "obj", &obj); err != nil {
+		return nil, err
+	}
+	field := func(path ...string) starlark.String {
+		v := obj
+		for _, p := range path {
+			var found bool
+			var err error
+			v, found, err = lookup(v, pathStep{field: p, index: -1})
+			if err != nil || !found {
+				return ""
+			}
+		}
+		return starlark.String(valueString(v))
+	}
+	group, version := "", string(field("apiVersion"))
+	if i := strings.LastIndex(version, "/"); i >= 0 {
+		group, version = version[:i], version[i+1:]
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"group":     starlark.String(group),
+		"version":   starlark.String(version),
+		"kind":      field("kind"),
+		"namespace": field("metadata", "namespace"),
+		"name":      field("metadata", "name"),
+	}), nil
+}
+
+func krmYAMLDecode(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "s", &s); err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+		return nil, errors.WrapPrefixf(err, b.Name())
+	}
+	return util.Marshal(v)
+}
+
+// krmYAMLEncode encodes the value as YAML.  Comments can't be kept
+// on starlark values, so they are copied from the YAML that the value
+// was decoded from, if given, onto the fields that remain.
+func krmYAMLEncode(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value starlark.Value
+	var original string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"value", &value, "original?", &original); err != nil {
+		return nil, err
+	}
+	v, err := util.Unmarshal(value)
+	if err != nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	to, err := yaml.Parse(string(out))
+	if err != nil {
+		return nil, err
+	}
+	if to.YNode().Kind == yaml.MappingNode {
+		// order the fields like kubectl would rather than alphabetically
+		if _, err := (filters.FormatFilter{}).Filter([]*yaml.RNode{to}); err != nil {
+			return nil, err
+		}
+	}
+	if original != "" {
+		from, err := yaml.Parse(original)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "%s: original", b.Name())
+		}
+		if err := comments.CopyComments(from, to); err != nil {
+			return nil, err
+		}
+	}
+	s, err := to.String()
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(s), nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package starlark
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const krmInput = `
+apiVersion: v1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: web
+    namespace: prod
+    labels:
+      app: web
+      tier: frontend
+  spec:
+    template:
+      spec:
+        containers:
+        - name: nginx
+          image: nginx:1.8.1
+`
+
+func TestKrm(t *testing.T) {
+	var tests = []struct {
+		name          string
+		program       string
+		expectedError string
+	}{
+		{
+			name: "get",
+			program: `
+item = ctx.resource_list["items"][0]
+def check():
+  if krm.get(item, "metadata.name") != "web":
+    fail("name")
+  if krm.get(item, "spec.template.spec.containers[0].image") != "nginx:1.8.1":
+    fail("index")
+  if krm.get(item, "spec.template.spec.containers[name=nginx].name") != "nginx":
+    fail("selector")
+  if krm.get(item, ["metadata", "labels", "app"]) != "web":
+    fail("list")
+  if krm.get(item, "spec.replicas", 1) != 1:
+    fail("default")
+  if krm.get(item, "spec.template.spec.containers[3]") != None:
+    fail("out of range")
+check()
+`,
+		},
+		{
+			name: "set",
+			program: `
+item = ctx.resource_list["items"][0]
+def check():
+  krm.set(item, "spec.replicas", 3)
+  krm.set(item, "spec.template.spec.containers[name=sidecar].image", "envoy")
+  krm.set(item, "spec.template.spec.containers[0].image", "nginx:1.9")
+  if item["spec"]["replicas"] != 3:
+    fail("field")
+  if item["spec"]["template"]["spec"]["containers"][1] != {"name": "sidecar", "image": "envoy"}:
+    fail("new element")
+  if item["spec"]["template"]["spec"]["containers"][0]["image"] != "nginx:1.9":
+    fail("index")
+check()
+`,
+		},
+		{
+			name: "set_out_of_range",
+			program: `
+krm.set(ctx.resource_list["items"][0], "spec.template.spec.containers[4].image", "x")
+`,
+			expectedError: "set: list index 4 out of range [0:1]",
+		},
+		{
+			name: "bad_path",
+			program: `
+krm.get(ctx.resource_list["items"][0], "spec.template[x")
+`,
+			expectedError: `unclosed '[' in path "spec.template[x"`,
+		},
+		{
+			name: "labels",
+			program: `
+item = ctx.resource_list["items"][0]
+def check():
+  if not krm.match_labels(item, {"app": "web"}):
+    fail("match")
+  if krm.match_labels(item, {"app": "db"}):
+    fail("no match")
+  if not krm.match_selector(item, {"matchLabels": {"tier": "frontend"},
+      "matchExpressions": [{"key": "app", "operator": "NotIn", "values": ["db"]},
+                           {"key": "canary", "operator": "DoesNotExist"}]}):
+    fail("selector")
+  if krm.match_selector(item, {"matchExpressions": [{"key": "tier", "operator": "Exists"},
+      {"key": "app", "operator": "In", "values": ["db"]}]}):
+    fail("no selector match")
+check()
+`,
+		},
+		{
+			name: "id",
+			program: `
+item = ctx.resource_list["items"][0]
+def check():
+  id = krm.id(item)
+  if (id.group, id.version, id.kind, id.namespace, id.name) != ("apps", "v1", "Deployment", "prod", "web"):
+    fail(id)
+  if krm.id(item) != krm.id(item):
+    fail("equality")
+check()
+`,
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Filter{Name: tt.name, Program: tt.program}
+			err := f.Run(bytes.NewBufferString(krmInput), &bytes.Buffer{})
+			if tt.expectedError != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFilter_load(t *testing.T) {
+	d, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+	files := map[string]string{
+		"outside.star":           `x = 1`,
+		"fn/lib/values.star":     `tier = "frontend"`,
+		"fn/cycle_a.star":        `load("cycle_b.star", "b")`,
+		"fn/cycle_b.star":        `load("cycle_a.star", "a")`,
+		"fn/ok.star":             `load("lib/values.star", "tier")`,
+		"fn/escape.star":         `load("../outside.star", "x")`,
+		"fn/absolute.star":       `load("/etc/values.star", "x")`,
+		"fn/symlink.star":        `load("link.star", "x")`,
+		"fn/cycle.star":          `load("cycle_a.star", "a")`,
+		"fn/missing_symbol.star": `load("lib/values.star", "missing")`,
+	}
+	for p, s := range files {
+		p = filepath.Join(d, p)
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0700)) ||
+			!assert.NoError(t, ioutil.WriteFile(p, []byte(s), 0600)) {
+			t.FailNow()
+		}
+	}
+	if !assert.NoError(t, os.Symlink(
+		filepath.Join(d, "outside.star"), filepath.Join(d, "fn", "link.star"))) {
+		t.FailNow()
+	}
+
+	var tests = []struct {
+		path          string
+		program       string
+		expectedError string
+	}{
+		{path: "ok.star"},
+		{path: "escape.star", expectedError: "module path ../outside.star not allowed outside of"},
+		{path: "absolute.star", expectedError: "absolute module path /etc/values.star not allowed"},
+		{path: "symlink.star", expectedError: "module path link.star not allowed outside of"},
+		{path: "cycle.star", expectedError: "cycle in load graph"},
+		{path: "missing_symbol.star", expectedError: "load: name missing not found in module lib/values.star"},
+		{
+			program:       `load("lib/values.star", "tier")`,
+			expectedError: "cannot load lib/values.star: only programs read from a Path may load modules",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.path, func(t *testing.T) {
+			f := &Filter{Name: tt.path, Program: tt.program}
+			if tt.path != "" {
+				f.Path = filepath.Join(d, "fn", tt.path)
+				if !assert.NoError(t, f.setup()) {
+					t.FailNow()
+				}
+			}
+			err := f.Run(bytes.NewBufferString(krmInput), &bytes.Buffer{})
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if !assert.Error(t, err) {
+				t.FailNow()
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected %q in error %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestFilter_loadReadFile(t *testing.T) {
+	files := map[string]string{
+		"fn/main.star":       `load("lib/values.star", "tier")`,
+		"fn/lib/values.star": `tier = "frontend"`,
+		"fn/escape.star":     `load("../outside.star", "x")`,
+		"fn/missing.star":    `load("lib/missing.star", "x")`,
+		"outside.star":       `x = 1`,
+	}
+	readFile := func(path string) ([]byte, error) {
+		s, ok := files[filepath.ToSlash(path)]
+		if !ok {
+			return nil, fmt.Errorf("no file %s", path)
+		}
+		return []byte(s), nil
+	}
+	var tests = []struct {
+		path          string
+		expectedError string
+	}{
+		{path: "main.star"},
+		{path: "escape.star", expectedError: "module path ../outside.star not allowed outside of fn"},
+		{path: "missing.star", expectedError: "no file fn/lib/missing.star"},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.path, func(t *testing.T) {
+			f := &Filter{Name: tt.path, Path: "fn/" + tt.path, ReadFile: readFile}
+			if !assert.NoError(t, f.setup()) {
+				t.FailNow()
+			}
+			err := f.Run(bytes.NewBufferString(krmInput), &bytes.Buffer{})
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if !assert.Error(t, err) {
+				t.FailNow()
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected %q in error %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/qri-io/starlib/util"
 	"go.starlark.net/starlark"
@@ -28,9 +31,19 @@ type Filter struct {
 	// URL is the url of a starlark program to fetch and run
 	URL string
 
-	// Path is the path to a starlark program to read and run
+	// Path is the path to a starlark program to read and run.  The program
+	// may load() modules from its directory.
 	Path string
 
+	// Args are parameters for the program, which it reads from ctx.args
+	Args map[string]interface{}
+
+	// ReadFile reads the program at Path, and the modules it loads.  It
+	// defaults to ioutil.ReadFile.  A ReadFile that isn't the default is
+	// trusted to keep reads where they should be; only the module paths
+	// are checked.
+	ReadFile func(path string) ([]byte, error)
+
 	runtimeutil.FunctionFilter
 }
 
@@ -58,7 +71,7 @@ func (sf *Filter) setup() error {
 
 	// read the program from a file
 	if sf.Path != "" {
-		b, err := ioutil.ReadFile(sf.Path)
+		b, err := sf.readFile(sf.Path)
 		if err != nil {
 			return err
 		}
@@ -96,14 +109,26 @@ func (sf *Filter) Run(reader io.Reader, writer io.Writer) error {
 		return errors.Wrap(err)
 	}
 
-	// run the starlark as program as transformation function
-	thread := &starlark.Thread{Name: sf.Name}
-
-	ctx := &Context{resourceList: value}
+	args, err := util.Marshal(sf.Args)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	ctx := &Context{resourceList: value, args: args}
 	pd, err := ctx.predeclared()
 	if err != nil {
 		return errors.Wrap(err)
 	}
+
+	// run the starlark as program as transformation function
+	l := &loader{
+		predeclared: pd,
+		modules:     map[string]*loadedModule{},
+		readFile:    sf.ReadFile,
+	}
+	if sf.Path != "" {
+		l.dir = filepath.Dir(sf.Path)
+	}
+	thread := &starlark.Thread{Name: sf.Name, Load: l.load}
 	_, err = starlark.ExecFile(thread, sf.Name, sf.Program, pd)
 	if err != nil {
 		return errors.Wrap(err)
@@ -112,6 +137,14 @@ func (sf *Filter) Run(reader io.Reader, writer io.Writer) error {
 	return sf.writeResourceList(value, writer)
 }
 
+// readFile reads a file with ReadFile, if set.
+func (sf *Filter) readFile(path string) ([]byte, error) {
+	if sf.ReadFile != nil {
+		return sf.ReadFile(path)
+	}
+	return ioutil.ReadFile(path)
+}
+
 // inputToResourceList transforms input into a starlark.Value
 func (sf *Filter) readResourceList(reader io.Reader) (starlark.Value, error) {
 	// read and parse the inputs
@@ -179,3 +212,96 @@ func (sf *Filter) writeResourceList(value starlark.Value, writer io.Writer) erro
 	_, err = writer.Write([]byte(s))
 	return err
 }
+
+// loader loads the modules of a program.  Modules are resolved against
+// the directory of the program, and can't be outside of it.
+type loader struct {
+	dir         string
+	predeclared starlark.StringDict
+	modules     map[string]*loadedModule
+	// readFile is the ReadFile of the Filter.
+	readFile func(path string) ([]byte, error)
+}
+
+type loadedModule struct {
+	globals starlark.StringDict
+	err     error
+}
+
+func (l *loader) load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	p, err := l.resolve(module)
+	if err != nil {
+		return nil, err
+	}
+	m, found := l.modules[p]
+	if found {
+		if m == nil {
+			return nil, errors.Errorf("cycle in load graph at %s", module)
+		}
+		return m.globals, m.err
+	}
+
+	// mark the module as loading to detect cycles
+	l.modules[p] = nil
+	m = &loadedModule{}
+	read := ioutil.ReadFile
+	if l.readFile != nil {
+		read = l.readFile
+	}
+	b, err := read(p)
+	if err != nil {
+		m.err = err
+	} else {
+		t := &starlark.Thread{Name: module, Load: thread.Load}
+		m.globals, m.err = starlark.ExecFile(t, module, b, l.predeclared)
+	}
+	l.modules[p] = m
+	return m.globals, m.err
+}
+
+// resolve returns the file of the module.
+func (l *loader) resolve(module string) (string, error) {
+	if l.dir == "" {
+		return "", errors.Errorf(
+			"cannot load %s: only programs read from a Path may load modules", module)
+	}
+	if filepath.IsAbs(module) || path.IsAbs(module) {
+		return "", errors.Errorf("absolute module path %s not allowed", module)
+	}
+	if l.readFile != nil {
+		// the files may not be on disk, so symlinks are
+		// left to readFile
+		p := filepath.Join(l.dir, filepath.FromSlash(module))
+		rel, err := filepath.Rel(l.dir, p)
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		if !within(rel) {
+			return "", errors.Errorf("module path %s not allowed outside of %s", module, l.dir)
+		}
+		return p, nil
+	}
+	// resolve symlinks, which may point outside of the directory
+	root, err := filepath.EvalSymlinks(l.dir)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	p, err := filepath.EvalSymlinks(filepath.Join(l.dir, filepath.FromSlash(module)))
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	if !within(rel) {
+		return "", errors.Errorf("module path %s not allowed outside of %s", module, l.dir)
+	}
+	return p, nil
+}
+
+// within returns whether a path relative to a directory
+// is within it.
+func within(rel string) bool {
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
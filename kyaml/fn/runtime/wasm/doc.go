@@ -0,0 +1,14 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wasm contains the WebAssembly function implementation.
+//
+// Functions are modules compiled for WASI, run in process without cgo.
+// They read the ResourceList from stdin and write it to stdout, and have
+// no network access, and no filesystem access other than the mounts
+// they are given.
+//
+// It is a module of its own, as its runtime needs a newer Go than kyaml;
+// runfn runs wasm functions with the WasmFilterProvider it is given,
+// e.g. NewFilter.
+package wasm
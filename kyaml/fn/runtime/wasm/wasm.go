@@ -0,0 +1,162 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// DefaultMemoryLimit is the memory limit of modules, in bytes,
+	// if the Filter doesn't set one.
+	DefaultMemoryLimit = 256 << 20
+
+	// DefaultTimeout is how long modules may run if the Filter
+	// doesn't set a limit.
+	DefaultTimeout = time.Minute
+
+	// pageSize is the size of a page of WebAssembly memory.
+	pageSize = 64 << 10
+
+	// bindMount is the only supported type of StorageMount.
+	bindMount = "bind"
+)
+
+type Filter struct {
+	// Path is the path to the .wasm module to run
+	Path string `yaml:"path,omitempty"`
+
+	// Module is the .wasm module to run, if Path is empty
+	Module []byte `yaml:"module,omitempty"`
+
+	// MemoryLimit is the most memory the module may use, in bytes
+	MemoryLimit uint64 `yaml:"memoryLimit,omitempty"`
+
+	// Timeout is how long the module may run
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// StorageMounts are the directories the module may read, mounted
+	// read-only.  Only bind mounts are supported.
+	StorageMounts []runtimeutil.StorageMount `yaml:"mounts,omitempty"`
+
+	runtimeutil.FunctionFilter
+}
+
+// NewFilter returns the Filter of a function spec, with the mounts it
+// may read and the FunctionFilter to run it as.  It is a
+// runfn.RunFns WasmFilterProvider.
+func NewFilter(spec runtimeutil.WasmSpec, mounts []runtimeutil.StorageMount,
+	fn runtimeutil.FunctionFilter) (kio.Filter, error) {
+	f := &Filter{
+		Path:           spec.Path,
+		MemoryLimit:    uint64(spec.MemoryLimitMiB) << 20,
+		Timeout:        time.Duration(spec.TimeoutSeconds) * time.Second,
+		StorageMounts:  mounts,
+		FunctionFilter: fn,
+	}
+	if spec.Module != "" {
+		b, err := base64.StdEncoding.DecodeString(spec.Module)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "decoding wasm module")
+		}
+		f.Module = b
+	}
+	return f, nil
+}
+
+func (c *Filter) String() string {
+	return fmt.Sprintf(
+		"path: %v module: %d bytes memoryLimit: %v timeout: %v",
+		c.Path, len(c.Module), c.MemoryLimit, c.Timeout)
+}
+
+// FunctionName names the function in reports of results: its
+// Path, if it has one.
+func (c *Filter) FunctionName() string {
+	return c.Path
+}
+
+func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	c.FunctionFilter.Run = c.Run
+	return c.FunctionFilter.Filter(nodes)
+}
+
+func (c *Filter) Run(reader io.Reader, writer io.Writer) error {
+	if (c.Path == "") == (len(c.Module) == 0) {
+		return errors.Errorf("wasm Filter Path and Module are mutually exclusive, and one is required")
+	}
+	bin := c.Module
+	if c.Path != "" {
+		var err error
+		if bin, err = ioutil.ReadFile(c.Path); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	fsConfig := wazero.NewFSConfig()
+	for _, m := range c.StorageMounts {
+		if m.MountType != bindMount {
+			return errors.Errorf(
+				"unsupported mount type %q for wasm functions, only %q is supported",
+				m.MountType, bindMount)
+		}
+		fsConfig = fsConfig.WithReadOnlyDirMount(m.Src, m.DstPath)
+	}
+
+	limit := c.MemoryLimit
+	if limit == 0 {
+		limit = DefaultMemoryLimit
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(limit/pageSize)).
+		WithCloseOnContextDone(true))
+	defer r.Close(ctx)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		return errors.Wrap(err)
+	}
+
+	// the module sees fake clocks and a deterministic random source,
+	// which keeps its output reproducible
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs("function").
+		WithStdin(reader).
+		WithStdout(writer).
+		WithStderr(os.Stderr).
+		WithFSConfig(fsConfig)
+	_, err := r.InstantiateWithConfig(ctx, bin, config)
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*sys.ExitError); ok {
+		switch e.ExitCode() {
+		case sys.ExitCodeDeadlineExceeded:
+			return errors.Errorf("wasm function timed out after %v", timeout)
+		default:
+			return errors.Errorf("wasm function exited with code %d", e.ExitCode())
+		}
+	}
+	return errors.Wrap(err)
+}
@@ -0,0 +1,240 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// catModule copies stdin to stdout:
+//
+//	(module
+//	  (import "wasi_snapshot_preview1" "fd_read" (func $fd_read (param i32 i32 i32 i32) (result i32)))
+//	  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+//	  (memory (export "memory") 1)
+//	  (func (export "_start")
+//	    (i32.store (i32.const 0) (i32.const 64))   ;; read iovec: buf
+//	    (i32.store (i32.const 4) (i32.const 4096)) ;; read iovec: len
+//	    (i32.store (i32.const 16) (i32.const 64))  ;; write iovec: buf
+//	    (block $done
+//	      (loop $read
+//	        (br_if $done (call $fd_read (i32.const 0) (i32.const 0) (i32.const 1) (i32.const 8)))
+//	        (br_if $done (i32.eqz (i32.load (i32.const 8))))
+//	        (i32.store (i32.const 20) (i32.load (i32.const 8)))
+//	        (drop (call $fd_write (i32.const 1) (i32.const 16) (i32.const 1) (i32.const 24)))
+//	        (br $read)))))
+var catModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x10, 0x03, 0x60,
+	0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, 0x60, 0x00, 0x00, 0x60, 0x01,
+	0x7f, 0x00, 0x02, 0x44, 0x02, 0x16, 0x77, 0x61, 0x73, 0x69, 0x5f, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x70, 0x72, 0x65, 0x76,
+	0x69, 0x65, 0x77, 0x31, 0x07, 0x66, 0x64, 0x5f, 0x72, 0x65, 0x61, 0x64,
+	0x00, 0x00, 0x16, 0x77, 0x61, 0x73, 0x69, 0x5f, 0x73, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x5f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77,
+	0x31, 0x08, 0x66, 0x64, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x00, 0x00,
+	0x03, 0x02, 0x01, 0x01, 0x05, 0x03, 0x01, 0x00, 0x01, 0x07, 0x13, 0x02,
+	0x06, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x00, 0x02, 0x06, 0x6d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x0a, 0x4d, 0x01, 0x4b, 0x00, 0x41,
+	0x00, 0x41, 0xc0, 0x00, 0x36, 0x02, 0x00, 0x41, 0x04, 0x41, 0x80, 0x20,
+	0x36, 0x02, 0x00, 0x41, 0x10, 0x41, 0xc0, 0x00, 0x36, 0x02, 0x00, 0x02,
+	0x40, 0x03, 0x40, 0x41, 0x00, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10,
+	0x00, 0x0d, 0x01, 0x41, 0x08, 0x28, 0x02, 0x00, 0x45, 0x0d, 0x01, 0x41,
+	0x14, 0x41, 0x08, 0x28, 0x02, 0x00, 0x36, 0x02, 0x00, 0x41, 0x01, 0x41,
+	0x10, 0x41, 0x01, 0x41, 0x18, 0x10, 0x01, 0x1a, 0x0c, 0x00, 0x0b, 0x0b,
+	0x0b,
+}
+
+// exitModule exits with code 3:
+//
+//	(module
+//	  (import "wasi_snapshot_preview1" "proc_exit" (func $proc_exit (param i32)))
+//	  (memory (export "memory") 1)
+//	  (func (export "_start") (call $proc_exit (i32.const 3))))
+var exitModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x10, 0x03, 0x60,
+	0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, 0x60, 0x00, 0x00, 0x60, 0x01,
+	0x7f, 0x00, 0x02, 0x24, 0x01, 0x16, 0x77, 0x61, 0x73, 0x69, 0x5f, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x70, 0x72, 0x65, 0x76,
+	0x69, 0x65, 0x77, 0x31, 0x09, 0x70, 0x72, 0x6f, 0x63, 0x5f, 0x65, 0x78,
+	0x69, 0x74, 0x00, 0x02, 0x03, 0x02, 0x01, 0x01, 0x05, 0x03, 0x01, 0x00,
+	0x01, 0x07, 0x13, 0x02, 0x06, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x00,
+	0x01, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x0a, 0x08,
+	0x01, 0x06, 0x00, 0x41, 0x03, 0x10, 0x00, 0x0b,
+}
+
+// loopModule never returns:
+//
+//	(module
+//	  (memory (export "memory") 1)
+//	  (func (export "_start") (loop (br 0))))
+var loopModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x10, 0x03, 0x60,
+	0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, 0x60, 0x00, 0x00, 0x60, 0x01,
+	0x7f, 0x00, 0x03, 0x02, 0x01, 0x01, 0x05, 0x03, 0x01, 0x00, 0x01, 0x07,
+	0x13, 0x02, 0x06, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x00, 0x00, 0x06,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x0a, 0x09, 0x01, 0x07,
+	0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b,
+}
+
+// memoryModule needs 4MiB of memory:
+//
+//	(module
+//	  (memory (export "memory") 64)
+//	  (func (export "_start")))
+var memoryModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x10, 0x03, 0x60,
+	0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, 0x60, 0x00, 0x00, 0x60, 0x01,
+	0x7f, 0x00, 0x03, 0x02, 0x01, 0x01, 0x05, 0x03, 0x01, 0x00, 0x40, 0x07,
+	0x13, 0x02, 0x06, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x00, 0x00, 0x06,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x0a, 0x04, 0x01, 0x02,
+	0x00, 0x0b,
+}
+
+func TestFilter_Filter(t *testing.T) {
+	var tests = []struct {
+		name           string
+		input          []string
+		expectedOutput []string
+		expectedError  string
+		instance       wasm.Filter
+	}{
+		{
+			name: "identity",
+			input: []string{
+				`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo`,
+				`apiVersion: v1
+kind: Service
+metadata:
+  name: service-foo`,
+			},
+			expectedOutput: []string{
+				`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo
+  annotations:
+    config.kubernetes.io/path: 'deployment_deployment-foo.yaml'
+`,
+				`apiVersion: v1
+kind: Service
+metadata:
+  name: service-foo
+  annotations:
+    config.kubernetes.io/path: 'service_service-foo.yaml'
+`,
+			},
+			instance: wasm.Filter{Module: catModule},
+		},
+		{
+			name:          "exit_code",
+			input:         []string{`kind: Service`},
+			expectedError: "wasm function exited with code 3",
+			instance:      wasm.Filter{Module: exitModule},
+		},
+		{
+			name:          "timeout",
+			input:         []string{`kind: Service`},
+			expectedError: "wasm function timed out after 100ms",
+			instance:      wasm.Filter{Module: loopModule, Timeout: 100 * time.Millisecond},
+		},
+		{
+			name:          "memory_limit",
+			input:         []string{`kind: Service`},
+			expectedError: "section memory: min 64 pages (4 Mi) over limit of 16 pages (1 Mi)",
+			instance:      wasm.Filter{Module: memoryModule, MemoryLimit: 1 << 20},
+		},
+		{
+			name:          "no_module",
+			input:         []string{`kind: Service`},
+			expectedError: "wasm Filter Path and Module are mutually exclusive, and one is required",
+			instance:      wasm.Filter{},
+		},
+		{
+			name:          "unsupported_mount",
+			input:         []string{`kind: Service`},
+			expectedError: `unsupported mount type "volume" for wasm functions, only "bind" is supported`,
+			instance: wasm.Filter{
+				Module: catModule,
+				StorageMounts: []runtimeutil.StorageMount{
+					{MountType: "volume", Src: "data", DstPath: "/data"},
+				},
+			},
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			var inputs []*yaml.RNode
+			for i := range tt.input {
+				node, err := yaml.Parse(tt.input[i])
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				inputs = append(inputs, node)
+			}
+
+			output, err := tt.instance.Filter(inputs)
+			if tt.expectedError != "" {
+				if !assert.EqualError(t, err, tt.expectedError) {
+					t.FailNow()
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			var actual []string
+			for i := range output {
+				s, err := output[i].String()
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				actual = append(actual, strings.TrimSpace(s))
+			}
+			var expected []string
+			for i := range tt.expectedOutput {
+				expected = append(expected, strings.TrimSpace(tt.expectedOutput[i]))
+			}
+			if !assert.Equal(t, expected, actual) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestNewFilter(t *testing.T) {
+	mounts := []runtimeutil.StorageMount{{MountType: "bind", Src: "data", DstPath: "/data"}}
+	f, err := wasm.NewFilter(runtimeutil.WasmSpec{
+		Module:         "AGFzbQEAAAA=",
+		MemoryLimitMiB: 16,
+		TimeoutSeconds: 10,
+	}, mounts, runtimeutil.FunctionFilter{GlobalScope: true})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, &wasm.Filter{
+		Module:         []byte("\x00asm\x01\x00\x00\x00"),
+		MemoryLimit:    16 << 20,
+		Timeout:        10 * time.Second,
+		StorageMounts:  mounts,
+		FunctionFilter: runtimeutil.FunctionFilter{GlobalScope: true},
+	}, f)
+
+	_, err = wasm.NewFilter(runtimeutil.WasmSpec{Module: "not base64"}, nil,
+		runtimeutil.FunctionFilter{})
+	assert.EqualError(t, err,
+		"decoding wasm module: illegal base64 data at input byte 3")
+}
@@ -0,0 +1,531 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Formats of a Report
+const (
+	TableFormat = "table"
+	JSONFormat  = "json"
+	SARIFFormat = "sarif"
+)
+
+// Report contains the results of the functions run by RunFns, grouped by
+// function in the order the functions ran.
+type Report struct {
+	Functions []FunctionResults `json:"functions"`
+}
+
+// FunctionResults contains the results of a function, sorted by severity,
+// file and line.
+type FunctionResults struct {
+	// Name is the name the function gave its results, or else its image,
+	// script or executable
+	Name string `json:"name"`
+
+	Results []Result `json:"results"`
+}
+
+// Result is a function result, located in the input files.
+type Result struct {
+	Severity framework.Severity `json:"severity"`
+
+	Message string `json:"message"`
+
+	// Resource is the kind, namespace and name of the resource
+	Resource string `json:"resource,omitempty"`
+
+	// File is the path of the file containing the resource
+	File string `json:"file,omitempty"`
+
+	// Line is the line of the field, or else of the resource, in File
+	Line int `json:"line,omitempty"`
+
+	// Field is the path of the field
+	Field string `json:"field,omitempty"`
+}
+
+// Count returns the number of results at or above the severity.
+func (r *Report) Count(severity framework.Severity) int {
+	var n int
+	for _, f := range r.Functions {
+		for _, res := range f.Results {
+			if severityRank(res.Severity) >= severityRank(severity) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Write writes the report to w in the format.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case TableFormat:
+		return r.writeTable(w)
+	case JSONFormat:
+		return r.writeJSON(w)
+	case SARIFFormat:
+		return r.writeSARIF(w)
+	}
+	return validFormat(format)
+}
+
+// validFormat returns an error if format isn't a format of a Report.
+func validFormat(format string) error {
+	switch format {
+	case TableFormat, JSONFormat, SARIFFormat:
+		return nil
+	}
+	return errors.Errorf("unknown results format %q, must be one of %s, %s or %s",
+		format, TableFormat, JSONFormat, SARIFFormat)
+}
+
+func (r *Report) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FUNCTION\tSEVERITY\tLOCATION\tRESOURCE\tMESSAGE")
+	for _, f := range r.Functions {
+		for _, res := range f.Results {
+			location := res.File
+			if res.Line > 0 {
+				location = fmt.Sprintf("%s:%d", location, res.Line)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.Name, res.Severity, location,
+				res.Resource, strings.ReplaceAll(res.Message, "\n", " "))
+		}
+	}
+	return tw.Flush()
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// sarifLog is a SARIF 2.1.0 log, with one run per function.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver struct {
+		Name string `json:"name"`
+	} `json:"driver"`
+}
+
+type sarifResult struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+	Region *sarifRegion `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+func (r *Report) writeSARIF(w io.Writer) error {
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{},
+	}
+	for _, f := range r.Functions {
+		run := sarifRun{Results: []sarifResult{}}
+		run.Tool.Driver.Name = f.Name
+		for _, res := range f.Results {
+			sr := sarifResult{
+				Level:   sarifLevel(res.Severity),
+				Message: sarifMessage{Text: res.Message},
+			}
+			var l sarifLocation
+			if res.File != "" {
+				l.PhysicalLocation = &sarifPhysicalLocation{}
+				l.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(res.File)
+				if res.Line > 0 {
+					l.PhysicalLocation.Region = &sarifRegion{StartLine: res.Line}
+				}
+			}
+			if res.Resource != "" {
+				l.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: res.Resource}}
+			}
+			if l.PhysicalLocation != nil || l.LogicalLocations != nil {
+				sr.Locations = []sarifLocation{l}
+			}
+			run.Results = append(run.Results, sr)
+		}
+		log.Runs = append(log.Runs, run)
+	}
+	b, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func sarifLevel(s framework.Severity) string {
+	switch s {
+	case framework.Error:
+		return "error"
+	case framework.Warning:
+		return "warning"
+	}
+	return "note"
+}
+
+// severityRank orders severities, results without a known severity rank as
+// informative.
+func severityRank(s framework.Severity) int {
+	switch s {
+	case framework.Error:
+		return 2
+	case framework.Warning:
+		return 1
+	}
+	return 0
+}
+
+// validSeverity returns an error if s isn't a severity.
+func validSeverity(s framework.Severity) error {
+	switch s {
+	case framework.Error, framework.Warning, framework.Info:
+		return nil
+	}
+	return errors.Errorf("unknown severity %q, must be one of %s, %s or %s",
+		s, framework.Error, framework.Warning, framework.Info)
+}
+
+// newReport returns the results saved by the fltrs.  A function which exits
+// with an error, but reports no results, has its error as result.
+func newReport(l *locator, fltrs []kio.Filter) (*Report, error) {
+	report := &Report{}
+	for i := range fltrs {
+		fr := FunctionResults{Name: functionName(fltrs[i], i)}
+		if rf, ok := fltrs[i].(runtimeutil.ResultsFunction); ok && rf.GetResults() != nil {
+			result, err := parseResults(rf.GetResults())
+			if err != nil {
+				return nil, errors.WrapPrefixf(err, "results of %s", fr.Name)
+			}
+			if result.Name != "" {
+				fr.Name = result.Name
+			}
+			for _, item := range result.Items {
+				fr.Results = append(fr.Results, l.locate(item))
+			}
+		}
+		if df, ok := fltrs[i].(runtimeutil.DeferFailureFunction); ok &&
+			df.GetExit() != nil && len(fr.Results) == 0 {
+			fr.Results = append(fr.Results, Result{
+				Severity: framework.Error, Message: df.GetExit().Error()})
+		}
+		if len(fr.Results) == 0 {
+			continue
+		}
+		sort.SliceStable(fr.Results, func(i, j int) bool {
+			a, b := fr.Results[i], fr.Results[j]
+			if severityRank(a.Severity) != severityRank(b.Severity) {
+				return severityRank(a.Severity) > severityRank(b.Severity)
+			}
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.Line < b.Line
+		})
+		report.Functions = append(report.Functions, fr)
+	}
+	return report, nil
+}
+
+// parseResults parses ResourceList.results, which are either a Result or a
+// list of Items.
+func parseResults(node *yaml.RNode) (*framework.Result, error) {
+	s, err := node.String()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	result := &framework.Result{}
+	if node.YNode().Kind == yaml.SequenceNode {
+		err = yaml.Unmarshal([]byte(s), &result.Items)
+	} else {
+		err = yaml.Unmarshal([]byte(s), result)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return result, nil
+}
+
+// namedFilter is a filter of a runtime outside of kyaml, e.g. that of
+// WebAssembly modules, which names its function if it can.
+type namedFilter interface {
+	FunctionName() string
+}
+
+// functionName names the ith function in the report, counting from 0.
+func functionName(f kio.Filter, i int) string {
+	switch f := f.(type) {
+	case *container.Filter:
+		return f.Image
+	case *starlark.Filter:
+		if f.Name != "" {
+			return f.Name
+		}
+		if f.Path != "" {
+			return f.Path
+		}
+		return f.URL
+	case namedFilter:
+		if n := f.FunctionName(); n != "" {
+			return n
+		}
+	case *exec.Filter:
+		return f.Path
+	case fmt.Stringer:
+		return f.String()
+	}
+	return fmt.Sprintf("function %d", i+1)
+}
+
+// locator locates the resources that results refer to in the input files.
+type locator struct {
+	// dir is the directory the resources were read from, if any
+	dir string
+
+	// resources are the resources as they were read, the functions may
+	// change their annotations but not the lines of their fields
+	resources []resourceLocation
+
+	// offsets are the lines of the files at which their resources start,
+	// by path and index
+	offsets map[string][]int
+}
+
+// resourceLocation is a resource and where it was read from.
+type resourceLocation struct {
+	node  *yaml.RNode
+	meta  yaml.ResourceMeta
+	path  string
+	index int
+}
+
+func newLocator(dir string, nodes []*yaml.RNode) *locator {
+	l := &locator{dir: dir, offsets: map[string][]int{}}
+	for i := range nodes {
+		meta, err := nodes[i].GetMeta()
+		if err != nil {
+			continue
+		}
+		rl := resourceLocation{node: nodes[i], meta: meta, index: -1}
+		path, index, err := kioutil.GetFileAnnotations(nodes[i])
+		if err == nil {
+			rl.path = path
+			if i, err := strconv.Atoi(index); err == nil {
+				rl.index = i
+			}
+		}
+		l.resources = append(l.resources, rl)
+	}
+	return l
+}
+
+// locate returns the result of a function for the item.
+func (l *locator) locate(item framework.Item) Result {
+	res := Result{
+		Severity: framework.Info,
+		Message:  item.Message,
+		File:     item.File.Path,
+		Field:    item.Field.Path,
+	}
+	if item.Severity != "" {
+		res.Severity = item.Severity
+	}
+	if item.ResourceRef.Kind != "" || item.ResourceRef.Name != "" {
+		res.Resource = resourceName(item.ResourceRef)
+	}
+	rl := l.find(item)
+	if rl == nil {
+		return res
+	}
+	if res.Resource == "" {
+		res.Resource = resourceName(rl.meta)
+	}
+	if rl.path == "" {
+		return res
+	}
+	res.File = rl.path
+	res.Line = fieldLine(rl.node, item.Field.Path)
+	if res.Line == 0 {
+		res.Line = rl.node.YNode().Line
+	}
+	if offsets := l.fileOffsets(rl.path); rl.index >= 0 && rl.index < len(offsets) {
+		res.Line += offsets[rl.index]
+	}
+	return res
+}
+
+// find returns the resource that the item refers to, by file or by reference.
+func (l *locator) find(item framework.Item) *resourceLocation {
+	ref := item.ResourceRef
+	for i := range l.resources {
+		rl := &l.resources[i]
+		if item.File.Path != "" {
+			if rl.path == item.File.Path && rl.index == item.File.Index {
+				return rl
+			}
+			continue
+		}
+		if (ref.APIVersion == "" || ref.APIVersion == rl.meta.APIVersion) &&
+			ref.Kind == rl.meta.Kind && ref.Name == rl.meta.Name &&
+			ref.Namespace == rl.meta.Namespace {
+			return rl
+		}
+	}
+	return nil
+}
+
+// fileOffsets returns the number of lines before each resource in the file,
+// or nil if it can't be read.
+func (l *locator) fileOffsets(path string) []int {
+	if l.dir == "" {
+		return nil
+	}
+	if offsets, found := l.offsets[path]; found {
+		return offsets
+	}
+	var offsets []int
+	defer func() { l.offsets[path] = offsets }()
+	b, err := ioutil.ReadFile(filepath.Join(l.dir, path))
+	if err != nil {
+		return nil
+	}
+	// split the documents the same way as kio.ByteReader so that the
+	// resource indexes match
+	var lines int
+	for _, value := range strings.Split(string(b), "\n---\n") {
+		node := &yaml.Node{}
+		err := yaml.NewDecoder(bytes.NewBufferString(value)).Decode(node)
+		if err == nil && len(node.Content) > 0 &&
+			node.Content[0].Tag != yaml.NullNodeTag {
+			offsets = append(offsets, lines)
+		}
+		lines += strings.Count(value, "\n") + 2
+	}
+	return offsets
+}
+
+// fieldLine returns the line of the field in the resource, or 0 if it
+// doesn't have the field.  Field paths are like
+// spec.template.spec.containers[name=app].image or spec.ports[0].port.
+func fieldLine(node *yaml.RNode, path string) int {
+	if path == "" {
+		return 0
+	}
+	for _, part := range splitFieldPath(path) {
+		if i, err := strconv.Atoi(strings.Trim(part, "[]")); err == nil &&
+			strings.HasPrefix(part, "[") {
+			if node.YNode().Kind != yaml.SequenceNode || i < 0 || i >= len(node.Content()) {
+				return 0
+			}
+			node = yaml.NewRNode(node.Content()[i])
+			continue
+		}
+		var err error
+		node, err = node.Pipe(yaml.Lookup(part))
+		if err != nil || node == nil {
+			return 0
+		}
+	}
+	return node.YNode().Line
+}
+
+// splitFieldPath splits a field path into field names and [...] elements.
+func splitFieldPath(path string) []string {
+	var parts []string
+	var part strings.Builder
+	var inBrackets bool
+	flush := func() {
+		if part.Len() > 0 {
+			parts = append(parts, part.String())
+			part.Reset()
+		}
+	}
+	for _, c := range path {
+		switch {
+		case c == '[' && !inBrackets:
+			flush()
+			inBrackets = true
+			part.WriteRune(c)
+		case c == ']' && inBrackets:
+			part.WriteRune(c)
+			inBrackets = false
+			flush()
+		case c == '.' && !inBrackets:
+			flush()
+		default:
+			part.WriteRune(c)
+		}
+	}
+	flush()
+	return parts
+}
+
+func resourceName(m yaml.ResourceMeta) string {
+	if m.Namespace == "" {
+		return m.Kind + "/" + m.Name
+	}
+	return m.Kind + "/" + m.Namespace + "/" + m.Name
+}
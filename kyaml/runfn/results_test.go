@@ -0,0 +1,318 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const resultsFunction = `apiVersion: v1
+kind: Validator
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: %s
+    config.kubernetes.io/local-config: "true"
+`
+
+// runWithResults runs a function for each of the results in the test
+// directory, and returns the report it writes
+func runWithResults(t *testing.T, r RunFns, results ...string) (string, error) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+	for i := range results {
+		if !assert.NoError(t, ioutil.WriteFile(
+			filepath.Join(dir, "validator"+string(rune('a'+i))+".yaml"),
+			[]byte(strings.Replace(resultsFunction, "%s", string(rune('a'+i)), 1)), 0600)) {
+			t.FailNow()
+		}
+	}
+
+	out := &bytes.Buffer{}
+	r.Path = dir
+	r.ResultsOutput = out
+	r.functionFilterProvider = func(
+		f runtimeutil.FunctionSpec, node *yaml.RNode) (kio.Filter, error) {
+		tf := &TestFilter{}
+		i := int(f.Container.Image[0] - 'a')
+		if strings.HasPrefix(results[i], "exit: ") {
+			tf.Exit = errors.Errorf(strings.TrimPrefix(results[i], "exit: "))
+			return tf, nil
+		}
+		tf.Results = yaml.MustParse(results[i])
+		return tf, nil
+	}
+	err := r.Execute()
+	return out.String(), err
+}
+
+const deploymentResults = `
+name: check-images
+items:
+- message: image has no digest
+  severity: warning
+  resourceRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: app
+  field:
+    path: spec.template.spec.containers[name=app].image
+- message: deployment has no replicas
+  severity: error
+  resourceRef:
+    kind: Deployment
+    metadata:
+      name: app
+`
+
+const serviceResults = `
+- message: port is unnamed
+  severity: info
+  file:
+    path: java/java-service.resource.yaml
+  field:
+    path: spec.ports[0].port
+- message: no network policy
+`
+
+func TestRunFns_Execute_resultsTable(t *testing.T) {
+	out, err := runWithResults(t, RunFns{ResultsFormat: TableFormat},
+		deploymentResults, serviceResults, "exit: function failed")
+	if !assert.EqualError(t, err, "function failed") {
+		t.FailNow()
+	}
+	assert.Equal(t, `FUNCTION      SEVERITY  LOCATION                               RESOURCE        MESSAGE
+check-images  error     java/java-deployment.resource.yaml:3   Deployment/app  deployment has no replicas
+check-images  warning   java/java-deployment.resource.yaml:21  Deployment/app  image has no digest
+function 2    info                                                             no network policy
+function 2    info      java/java-service.resource.yaml:14     Service/app     port is unnamed
+function 3    error                                                            function failed
+`, out)
+}
+
+func TestRunFns_Execute_severityThreshold(t *testing.T) {
+	var tests = []struct {
+		name      string
+		threshold framework.Severity
+		results   []string
+		err       string
+	}{
+		{
+			name:      "error",
+			threshold: framework.Error,
+			results:   []string{deploymentResults, serviceResults},
+			err:       "functions reported 1 results at or above severity error",
+		},
+		{
+			name:      "warning",
+			threshold: framework.Warning,
+			results:   []string{deploymentResults, serviceResults},
+			err:       "functions reported 2 results at or above severity warning",
+		},
+		{
+			name:      "info",
+			threshold: framework.Info,
+			results:   []string{deploymentResults, serviceResults},
+			err:       "functions reported 4 results at or above severity info",
+		},
+		{
+			name:      "below threshold",
+			threshold: framework.Warning,
+			results:   []string{serviceResults},
+		},
+		{
+			name:      "failure without results",
+			threshold: framework.Error,
+			results:   []string{serviceResults, "exit: function failed"},
+			err:       "functions reported 1 results at or above severity error",
+		},
+		{
+			name:      "unknown",
+			threshold: "fatal",
+			err:       `unknown severity "fatal", must be one of error, warning or info`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			_, err := runWithResults(t, RunFns{SeverityThreshold: test.threshold}, test.results...)
+			if test.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestRunFns_Execute_resultsJSON(t *testing.T) {
+	out, err := runWithResults(t, RunFns{ResultsFormat: JSONFormat}, serviceResults)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{
+  "functions": [
+    {
+      "name": "function 1",
+      "results": [
+        {
+          "severity": "info",
+          "message": "no network policy"
+        },
+        {
+          "severity": "info",
+          "message": "port is unnamed",
+          "resource": "Service/app",
+          "file": "java/java-service.resource.yaml",
+          "line": 14,
+          "field": "spec.ports[0].port"
+        }
+      ]
+    }
+  ]
+}
+`, out)
+}
+
+func TestRunFns_Execute_resultsSARIF(t *testing.T) {
+	out, err := runWithResults(t, RunFns{ResultsFormat: SARIFFormat}, deploymentResults)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "check-images"
+        }
+      },
+      "results": [
+        {
+          "level": "error",
+          "message": {
+            "text": "deployment has no replicas"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "java/java-deployment.resource.yaml"
+                },
+                "region": {
+                  "startLine": 3
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "Deployment/app"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "level": "warning",
+          "message": {
+            "text": "image has no digest"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "java/java-deployment.resource.yaml"
+                },
+                "region": {
+                  "startLine": 21
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "Deployment/app"
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`, out)
+}
+
+func TestRunFns_Execute_resultsFormat(t *testing.T) {
+	_, err := runWithResults(t, RunFns{ResultsFormat: "xml"})
+	assert.EqualError(t, err,
+		`unknown results format "xml", must be one of table, json or sarif`)
+}
+
+func TestLocator_multipleResources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+---
+# b
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+data:
+  key: value
+`), 0600)) {
+		t.FailNow()
+	}
+	nodes, err := kio.LocalPackageReader{PackagePath: dir}.Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	l := newLocator(dir, nodes)
+	assert.Equal(t, Result{
+		Severity: framework.Info,
+		Message:  "b",
+		Resource: "ConfigMap/b",
+		File:     "config.yaml",
+		Line:     14,
+		Field:    "data.key",
+	}, l.locate(framework.Item{
+		Message:     "b",
+		ResourceRef: yaml.ResourceMeta{Kind: "ConfigMap", ObjectMeta: yaml.ObjectMeta{Name: "b"}},
+		Field:       framework.Field{Path: "data.key"},
+	}))
+	assert.Equal(t, Result{
+		Severity: framework.Info,
+		Message:  "a",
+		Resource: "ConfigMap/a",
+		File:     "config.yaml",
+		Line:     2,
+	}, l.locate(framework.Item{
+		Message: "a",
+		File:    framework.File{Path: "config.yaml", Index: 0},
+	}))
+}
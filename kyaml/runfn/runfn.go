@@ -14,6 +14,7 @@ import (
 	"sync/atomic"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
@@ -68,12 +69,34 @@ type RunFns struct {
 	// EnableExec will enable exec functions
 	EnableExec bool
 
+	// EnableWasm will enable functions run as WebAssembly modules
+	EnableWasm bool
+
+	// WasmFilterProvider provides the filters of functions run as WebAssembly
+	// modules, which EnableWasm needs.  The path of the spec is resolved.
+	// The runtime is the module sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm,
+	// whose NewFilter may be used.
+	WasmFilterProvider func(spec runtimeutil.WasmSpec,
+		mounts []runtimeutil.StorageMount, fn runtimeutil.FunctionFilter) (kio.Filter, error)
+
 	// DisableContainers will disable functions run as containers
 	DisableContainers bool
 
 	// ResultsDir is where to write each functions results
 	ResultsDir string
 
+	// ResultsFormat is the format to write a report of all function results to
+	// ResultsOutput in: table, json or sarif.  If unset, no report is written.
+	ResultsFormat string
+
+	// ResultsOutput is where to write the results report, defaults to stderr.
+	ResultsOutput io.Writer
+
+	// SeverityThreshold if set will cause Execute to fail if any function reports
+	// a result at or above this severity, rather than if any function fails.
+	// A function which fails without reporting results counts as an error.
+	SeverityThreshold framework.Severity
+
 	// resultsCount is used to generate the results filename for each container
 	resultsCount uint32
 
@@ -92,6 +115,17 @@ func (r RunFns) Execute() error {
 		return errors.Wrap(err)
 	}
 
+	if r.ResultsFormat != "" {
+		if err := validFormat(r.ResultsFormat); err != nil {
+			return err
+		}
+	}
+	if r.SeverityThreshold != "" {
+		if err := validSeverity(r.SeverityThreshold); err != nil {
+			return err
+		}
+	}
+
 	// default the containerFilterProvider if it hasn't been override.  Split out for testing.
 	(&r).init()
 	nodes, fltrs, output, err := r.getNodesAndFilters()
@@ -158,7 +192,7 @@ func (r RunFns) getFilters(nodes []*yaml.RNode) ([]kio.Filter, error) {
 
 // runFunctions runs the fltrs against the input and writes to either r.Output or output
 func (r RunFns) runFunctions(
-	input kio.Reader, output kio.Writer, fltrs []kio.Filter) error {
+	input *kio.PackageBuffer, output kio.Writer, fltrs []kio.Filter) error {
 	// use the previously read Resources as input
 	var outputs []kio.Writer
 	if r.Output == nil {
@@ -169,11 +203,31 @@ func (r RunFns) runFunctions(
 		// the output is nil (reading from Input)
 		outputs = append(outputs, kio.ByteWriter{Writer: r.Output})
 	}
+
+	// results refer to the files the Resources were read from
+	var dir string
+	if r.Input == nil {
+		dir = r.Path
+	}
+	l := newLocator(dir, input.Nodes)
+
 	err := kio.Pipeline{
 		Inputs: []kio.Reader{input}, Filters: fltrs, Outputs: outputs}.Execute()
+	report, reportErr := r.writeReport(l, fltrs)
 	if err != nil {
 		return err
 	}
+	if reportErr != nil {
+		return reportErr
+	}
+
+	if r.SeverityThreshold != "" {
+		if n := report.Count(r.SeverityThreshold); n > 0 {
+			return errors.Errorf(
+				"functions reported %d results at or above severity %s", n, r.SeverityThreshold)
+		}
+		return nil
+	}
 
 	// check for deferred function errors
 	var errs []string
@@ -192,6 +246,23 @@ func (r RunFns) runFunctions(
 	return nil
 }
 
+// writeReport collects the function results into a report, and writes it to
+// r.ResultsOutput if r.ResultsFormat is set
+func (r RunFns) writeReport(l *locator, fltrs []kio.Filter) (*Report, error) {
+	report, err := newReport(l, fltrs)
+	if err != nil {
+		return nil, err
+	}
+	if r.ResultsFormat == "" {
+		return report, nil
+	}
+	w := r.ResultsOutput
+	if w == nil {
+		w = os.Stderr
+	}
+	return report, report.Write(w, r.ResultsFormat)
+}
+
 // getFunctionsFromInput scans the input for functions and runs them
 func (r RunFns) getFunctionsFromInput(nodes []*yaml.RNode) ([]kio.Filter, error) {
 	if *r.NoFunctionsFromInput {
@@ -365,21 +436,19 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode) (kio.Filter
 
 		var p string
 		if spec.Starlark.Path != "" {
-			p = filepath.ToSlash(path.Clean(m.Annotations[kioutil.PathAnnotation]))
-			spec.Starlark.Path = filepath.ToSlash(path.Clean(spec.Starlark.Path))
-			if filepath.IsAbs(spec.Starlark.Path) || path.IsAbs(spec.Starlark.Path) {
-				return nil, errors.Errorf(
-					"absolute function path %s not allowed", spec.Starlark.Path)
-			}
-			if strings.HasPrefix(spec.Starlark.Path, "..") {
-				return nil, errors.Errorf(
-					"function path %s not allowed to start with ../", spec.Starlark.Path)
+			p, err = r.functionPath(m, spec.Starlark.Path)
+			if err != nil {
+				return nil, err
 			}
-			p = filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), spec.Starlark.Path))
 		}
 		fmt.Println(p)
 
-		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: p, URL: spec.Starlark.URL}
+		sf := &starlark.Filter{
+			Name: spec.Starlark.Name,
+			Path: p,
+			URL:  spec.Starlark.URL,
+			Args: spec.Starlark.Args,
+		}
 
 		sf.FunctionConfig = api
 		sf.GlobalScope = r.GlobalScope
@@ -388,6 +457,30 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode) (kio.Filter
 		return sf, nil
 	}
 
+	if r.EnableWasm && (spec.Wasm.Path != "" || spec.Wasm.Module != "") {
+		if r.WasmFilterProvider == nil {
+			return nil, errors.Errorf("no WebAssembly runtime to run wasm functions with")
+		}
+		ws := spec.Wasm
+		if ws.Path != "" {
+			// the module path is relative to the function config file
+			m, err := api.GetMeta()
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			ws.Path, err = r.functionPath(m, ws.Path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return r.WasmFilterProvider(ws, r.StorageMounts, runtimeutil.FunctionFilter{
+			FunctionConfig: api,
+			GlobalScope:    r.GlobalScope,
+			ResultsFile:    resultsFile,
+			DeferFailure:   spec.DeferFailure,
+		})
+	}
+
 	if r.EnableExec && spec.Exec.Path != "" {
 		ef := &exec.Filter{Path: spec.Exec.Path}
 
@@ -400,3 +493,17 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode) (kio.Filter
 
 	return nil, nil
 }
+
+// functionPath returns the path of a file that a function refers to, which
+// must be relative to the function config file and inside its directory.
+func (r *RunFns) functionPath(m yaml.ResourceMeta, fp string) (string, error) {
+	p := filepath.ToSlash(path.Clean(m.Annotations[kioutil.PathAnnotation]))
+	fp = filepath.ToSlash(path.Clean(fp))
+	if filepath.IsAbs(fp) || path.IsAbs(fp) {
+		return "", errors.Errorf("absolute function path %s not allowed", fp)
+	}
+	if strings.HasPrefix(fp, "..") {
+		return "", errors.Errorf("function path %s not allowed to start with ../", fp)
+	}
+	return filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), fp)), nil
+}
@@ -217,6 +217,11 @@ func TestRunFns_getFilters(t *testing.T) {
 
 		enableStarlark bool
 
+		enableWasm bool
+
+		// if true, RunFns has no WasmFilterProvider
+		noWasmRuntime bool
+
 		disableContainers bool
 	}{
 		// Test
@@ -585,6 +590,116 @@ metadata:
     config.kubernetes.io/function: |
       starlark:
         path: a/b/c
+`,
+				},
+			},
+		},
+
+		// Test
+		//
+		//
+		{name: "wasm-function",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: a/b/c.wasm
+        memoryLimitMiB: 16
+        timeoutSeconds: 10
+`,
+				},
+			},
+			enableWasm: true,
+			outFn: func(path string) []string {
+				return []string{fmt.Sprintf(
+					"wasm path: %s/foo/a/b/c.wasm module:  memoryLimitMiB: 16 timeoutSeconds: 10",
+					filepath.ToSlash(path))}
+			},
+		},
+
+		// Test
+		//
+		//
+		{name: "wasm-function-embedded",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        module: AGFzbQEAAAA=
+`,
+				},
+			},
+			enableWasm: true,
+			out: []string{
+				"wasm path:  module: AGFzbQEAAAA= memoryLimitMiB: 0 timeoutSeconds: 0"},
+		},
+
+		// Test
+		//
+		//
+		{name: "wasm-function-escape-parent",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: ../a/b/c.wasm
+`,
+				},
+			},
+			enableWasm: true,
+			error:      "function path ../a/b/c.wasm not allowed to start with ../",
+		},
+
+		{name: "wasm-function-no-runtime",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: a/b/c.wasm
+`,
+				},
+			},
+			enableWasm:    true,
+			noWasmRuntime: true,
+			error:         "no WebAssembly runtime to run wasm functions with",
+		},
+
+		{name: "wasm-function-disabled",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: a/b/c.wasm
 `,
 				},
 			},
@@ -642,12 +757,17 @@ metadata:
 			// init the instance
 			r := &RunFns{
 				EnableStarlark:       tt.enableStarlark,
+				EnableWasm:           tt.enableWasm,
+				WasmFilterProvider:   wasmSpecFilterProvider,
 				DisableContainers:    tt.disableContainers,
 				FunctionPaths:        fnPaths,
 				Functions:            parsedFns,
 				Path:                 d,
 				NoFunctionsFromInput: tt.noFunctionsFromInput,
 			}
+			if tt.noWasmRuntime {
+				r.WasmFilterProvider = nil
+			}
 			r.init()
 
 			// get the filters which would be run
@@ -682,6 +802,26 @@ metadata:
 	}
 }
 
+// wasmSpecFilter stands in for the filter of a WebAssembly function,
+// printing the spec it was provided with.
+type wasmSpecFilter struct {
+	spec runtimeutil.WasmSpec
+}
+
+func (f wasmSpecFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return nodes, nil
+}
+
+func (f wasmSpecFilter) String() string {
+	return fmt.Sprintf("wasm path: %s module: %s memoryLimitMiB: %d timeoutSeconds: %d",
+		f.spec.Path, f.spec.Module, f.spec.MemoryLimitMiB, f.spec.TimeoutSeconds)
+}
+
+func wasmSpecFilterProvider(spec runtimeutil.WasmSpec,
+	_ []runtimeutil.StorageMount, _ runtimeutil.FunctionFilter) (kio.Filter, error) {
+	return wasmSpecFilter{spec: spec}, nil
+}
+
 func TestCmd_Execute(t *testing.T) {
 	dir := setupTest(t)
 	defer os.RemoveAll(dir)
@@ -707,6 +847,7 @@ func TestCmd_Execute(t *testing.T) {
 type TestFilter struct {
 	invoked bool
 	Exit    error
+	Results *yaml.RNode
 }
 
 func (f *TestFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
@@ -718,6 +859,10 @@ func (f *TestFilter) GetExit() error {
 	return f.Exit
 }
 
+func (f *TestFilter) GetResults() *yaml.RNode {
+	return f.Results
+}
+
 func TestCmd_Execute_deferFailure(t *testing.T) {
 	dir := setupTest(t)
 	defer os.RemoveAll(dir)
@@ -9,3 +9,4 @@ require (
 )
 
 replace sigs.k8s.io/kustomize/api v0.4.0 => ../../../api
+replace sigs.k8s.io/kustomize/kyaml => ../../../kyaml
@@ -0,0 +1,52 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/checksum"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filtersutil"
+)
+
+// Annotate the pod templates of workloads with a checksum
+// of the ConfigMaps and Secrets that they refer to.
+type plugin struct {
+	hasher ifc.KunstructuredHasher
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, _ []byte) (err error) {
+	p.hasher = h.ResmapFactory().RF().Hasher()
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	hashes := make(map[checksum.Ref]string)
+	for _, r := range m.Resources() {
+		if r.GetKind() != "ConfigMap" && r.GetKind() != "Secret" {
+			continue
+		}
+		h, err := p.hasher.Hash(r)
+		if err != nil {
+			return err
+		}
+		hashes[checksum.Ref{
+			Kind: r.GetKind(), Namespace: r.GetNamespace(), Name: r.GetName()}] = h
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	f := checksum.Filter{Hashes: hashes}
+	for _, r := range m.Resources() {
+		if err := filtersutil.ApplyToJSON(f, r.Kunstructured); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/field"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filtersutil"
+	"sigs.k8s.io/yaml"
+)
+
+// Set, edit or delete the fields that the fieldSpecs locate
+// in the selected resources.
+type plugin struct {
+	// Selector selects the resources to modify; all of them,
+	// if it's nil.
+	Selector *types.Selector `json:"selector,omitempty" yaml:"selector,omitempty"`
+
+	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Operation is one of set, prepend, append, replace,
+	// delete or template.
+	Operation field.Operation `json:"operation,omitempty" yaml:"operation,omitempty"`
+
+	// Value is the value of the operation: the template
+	// for template, and the replacement for replace.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Regex is the pattern that replace replaces.
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Selector = nil
+	p.FieldSpecs = nil
+	p.Operation = ""
+	p.Value = ""
+	p.Regex = ""
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if len(p.FieldSpecs) == 0 {
+		return fmt.Errorf("must specify at least one fieldSpec")
+	}
+	return p.filter().Validate()
+}
+
+func (p *plugin) filter() field.Filter {
+	return field.Filter{
+		Operation: p.Operation,
+		Value:     p.Value,
+		Regex:     p.Regex,
+		FsSlice:   p.FieldSpecs,
+	}
+}
+
+func (p *plugin) Transform(m resmap.ResMap) (err error) {
+	var resources []*resource.Resource
+	if p.Selector == nil {
+		resources = m.Resources()
+	} else {
+		resources, err = m.Select(*p.Selector)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range resources {
+		err = filtersutil.ApplyToJSON(p.filter(), r.Kunstructured)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
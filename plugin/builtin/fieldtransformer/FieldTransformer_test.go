@@ -0,0 +1,179 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const resources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.old.example.com/web:1.0
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  clusterIP: 10.0.0.1
+`
+
+func TestFieldTransformerReplace(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: FieldTransformer
+metadata:
+  name: notImportantHere
+selector:
+  kind: Deployment
+fieldSpecs:
+- path: spec/template/spec/containers/image
+operation: replace
+regex: ^registry\.old\.example\.com/
+value: registry.example.com/
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.example.com/web:1.0
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  clusterIP: 10.0.0.1
+`)
+}
+
+func TestFieldTransformerTemplate(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: FieldTransformer
+metadata:
+  name: notImportantHere
+fieldSpecs:
+- path: metadata/annotations/owner
+  create: true
+operation: template
+value: '{{.metadata.name}}-{{.metadata.namespace}}'
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    owner: web-prod
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.old.example.com/web:1.0
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    owner: web-prod
+  name: web
+  namespace: prod
+spec:
+  clusterIP: 10.0.0.1
+`)
+}
+
+func TestFieldTransformerDelete(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: FieldTransformer
+metadata:
+  name: notImportantHere
+fieldSpecs:
+- path: spec/clusterIP
+  kind: Service
+operation: delete
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.old.example.com/web:1.0
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec: {}
+`)
+}
+
+func TestFieldTransformerBadConfig(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldTransformer")
+	defer th.Reset()
+
+	for config, expected := range map[string]string{
+		`
+operation: set
+value: x
+`: "must specify at least one fieldSpec",
+		`
+fieldSpecs:
+- path: metadata/name
+operation: sed
+`: `unknown operation "sed"`,
+	} {
+		err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: FieldTransformer
+metadata:
+  name: notImportantHere
+`+strings.TrimPrefix(config, "\n"), resources)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error %q, got %v", expected, err)
+		}
+	}
+}
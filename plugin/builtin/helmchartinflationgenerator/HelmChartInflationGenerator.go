@@ -0,0 +1,177 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Inflate a helm chart by running a local
+// 'helm template' binary against it.
+type plugin struct {
+	h                *resmap.PluginHelpers
+	types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	types.HelmChartArgs
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+const (
+	defaultChartHome   = "charts"
+	defaultHelmCommand = "helm"
+	defaultReleaseName = "release-name"
+)
+
+func (p *plugin) Config(h *resmap.PluginHelpers, config []byte) (err error) {
+	if pc := h.GeneralConfig(); pc == nil || !pc.HelmConfig.Enabled {
+		return fmt.Errorf(
+			"must specify --enable-helm to inflate helm charts")
+	}
+	p.HelmChartArgs = types.HelmChartArgs{}
+	err = yaml.Unmarshal(config, p)
+	if err != nil {
+		return
+	}
+	if p.ChartName == "" {
+		return fmt.Errorf("chartName must be specified")
+	}
+	if p.ChartHome == "" {
+		p.ChartHome = defaultChartHome
+	}
+	if p.ReleaseName == "" {
+		p.ReleaseName = defaultReleaseName
+	}
+	if path := filepath.Join(p.ChartHome, p.ChartName); filepath.IsAbs(path) ||
+		path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return fmt.Errorf(
+			"chart '%s' must be within the kustomization root", path)
+	}
+	p.h = h
+	return
+}
+
+// copyChart copies the chart directory, or the chart archive
+// if a version was asked for, into dir, and returns the path
+// of the copy.  The chart is read through the loader, so
+// it's subject to its load restrictions.
+func (p *plugin) copyChart(dir string) (string, error) {
+	ldr := p.h.Loader()
+	if p.ChartVersion != "" {
+		name := fmt.Sprintf("%s-%s.tgz", p.ChartName, p.ChartVersion)
+		content, err := ldr.Load(filepath.Join(p.ChartHome, name))
+		if err != nil {
+			return "", err
+		}
+		path := filepath.Join(dir, name)
+		return path, ioutil.WriteFile(path, content, 0600)
+	}
+	chart := filepath.Join(p.ChartHome, p.ChartName)
+	lister, ok := ldr.(ifc.FileLister)
+	if !ok {
+		return "", fmt.Errorf(
+			"the loader %T can't list the files of %s", ldr, chart)
+	}
+	files, err := lister.ListFiles(chart)
+	if err != nil {
+		return "", err
+	}
+	result := filepath.Join(dir, p.ChartName)
+	for _, f := range files {
+		content, err := ldr.Load(filepath.Join(chart, f))
+		if err != nil {
+			return "", err
+		}
+		path := filepath.Join(result, filepath.FromSlash(f))
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return "", err
+		}
+		if err = ioutil.WriteFile(path, content, 0600); err != nil {
+			return "", err
+		}
+	}
+	return result, nil
+}
+
+// writeValues copies the values files, and writes the inline
+// values, into dir, so that helm can read them whatever file
+// system the loader uses.  It returns the file paths in the
+// order helm should read them.
+func (p *plugin) writeValues(dir string) (result []string, err error) {
+	for i, f := range p.ValuesFiles {
+		content, err := p.h.Loader().Load(f)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("values-%d.yaml", i))
+		if err = ioutil.WriteFile(path, content, 0600); err != nil {
+			return nil, err
+		}
+		result = append(result, path)
+	}
+	if len(p.ValuesInline) > 0 {
+		content, err := yaml.Marshal(p.ValuesInline)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, "values-inline.yaml")
+		if err = ioutil.WriteFile(path, content, 0600); err != nil {
+			return nil, err
+		}
+		result = append(result, path)
+	}
+	return result, nil
+}
+
+func (p *plugin) Generate() (resmap.ResMap, error) {
+	dir, err := ioutil.TempDir("", "kustomize-helm-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	chart, err := p.copyChart(dir)
+	if err != nil {
+		return nil, err
+	}
+	values, err := p.writeValues(dir)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"template", p.ReleaseName, chart}
+	if p.ReleaseNamespace != "" {
+		args = append(args, "--namespace", p.ReleaseNamespace)
+	}
+	for _, v := range values {
+		args = append(args, "--values", v)
+	}
+	if p.IncludeCRDs {
+		args = append(args, "--include-crds")
+	}
+	helm := p.h.GeneralConfig().HelmConfig.Command
+	if helm == "" {
+		helm = defaultHelmCommand
+	}
+	cmd := exec.Command(helm, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf(
+			"unable to inflate chart %s: %v: %s",
+			p.ChartName, err, stderr.String())
+	}
+	return p.h.ResmapFactory().NewResMapFromBytes(stdout.Bytes())
+}
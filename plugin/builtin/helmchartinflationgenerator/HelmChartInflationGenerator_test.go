@@ -0,0 +1,211 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// fakeHelm is a stand-in for 'helm template' that reports
+// the arguments and values it was given in a ConfigMap.
+const fakeHelm = `#!/bin/bash
+[ "$1" == "template" ] || { echo "unexpected command $1" >&2; exit 1; }
+release=$2
+chart=$3
+shift 3
+namespace=""
+crds=false
+values=""
+while [ $# -gt 0 ]; do
+  case $1 in
+    --namespace) namespace=$2; shift 2 ;;
+    --values) values="$values$(grep -v "^$" $2)"$'\n'; shift 2 ;;
+    --include-crds) crds=true; shift ;;
+    *) echo "unexpected flag $1" >&2; exit 1 ;;
+  esac
+done
+[ "$(basename $chart)" == "broken" ] && { echo "chart is broken" >&2; exit 1; }
+cat <<EOF2
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: $release-args
+data:
+  chart: $(basename $chart)
+  files: "$(cd $chart 2>/dev/null && find . -type f | sort | tr '\n' ' ')"
+  namespace: "$namespace"
+  crds: "$crds"
+  values: |
+$(echo -n "$values" | sed 's/^/    /')
+EOF2
+`
+
+func writeFakeHelm(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "kustomize-fake-helm-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	helm := filepath.Join(dir, "helm")
+	if err = ioutil.WriteFile(helm, []byte(fakeHelm), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return helm, func() { os.RemoveAll(dir) }
+}
+
+func TestHelmChartInflationGenerator(t *testing.T) {
+	helm, cleanup := writeFakeHelm(t)
+	defer cleanup()
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator").
+		EnableHelm(helm)
+	defer th.Reset()
+
+	th.WriteF("charts/minecraft/Chart.yaml", `
+name: minecraft
+`)
+	th.WriteF("charts/minecraft/templates/server.yaml", `
+kind: Deployment
+`)
+	th.WriteF("values.yaml", `
+replicas: 2
+`)
+	rm := th.LoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chartName: minecraft
+releaseName: test
+releaseNamespace: games
+valuesFiles:
+- values.yaml
+valuesInline:
+  replicas: 3
+includeCRDs: true
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  chart: minecraft
+  crds: "true"
+  files: './Chart.yaml ./templates/server.yaml '
+  namespace: games
+  values: |
+    replicas: 2
+    replicas: 3
+kind: ConfigMap
+metadata:
+  name: test-args
+`)
+}
+
+func TestHelmChartInflationGeneratorCachedChart(t *testing.T) {
+	helm, cleanup := writeFakeHelm(t)
+	defer cleanup()
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator").
+		EnableHelm(helm)
+	defer th.Reset()
+
+	th.WriteF("repo/cache/minecraft-1.2.0.tgz", "archive")
+	rm := th.LoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chartName: minecraft
+chartVersion: 1.2.0
+chartHome: repo/cache
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  chart: minecraft-1.2.0.tgz
+  crds: "false"
+  files: ""
+  namespace: ""
+  values: ""
+kind: ConfigMap
+metadata:
+  name: release-name-args
+`)
+}
+
+func TestHelmChartInflationGeneratorHelmFails(t *testing.T) {
+	helm, cleanup := writeFakeHelm(t)
+	defer cleanup()
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator").
+		EnableHelm(helm)
+	defer th.Reset()
+
+	th.WriteF("charts/broken/Chart.yaml", `
+name: broken
+`)
+	err := th.ErrorFromLoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chartName: broken
+`)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "chart is broken") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestHelmChartInflationGeneratorChartOutsideRoot(t *testing.T) {
+	helm, cleanup := writeFakeHelm(t)
+	defer cleanup()
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator").
+		EnableHelm(helm)
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chartName: minecraft
+chartHome: ../charts
+`)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "must be within the kustomization root") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestHelmChartInflationGeneratorNotEnabled(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chartName: minecraft
+`)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "must specify --enable-helm") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
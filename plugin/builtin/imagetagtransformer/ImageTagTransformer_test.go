@@ -429,4 +429,57 @@ spec:
       - image: some.registry.io/my-image:my-fixed-tag
         name: my-image
 `)
-}
\ No newline at end of file
+}
+func TestImageTagTransformerTagPolicy(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ImageTagTransformer")
+	defer th.Reset()
+
+	th.WriteF("images.lock.yaml", `
+images:
+- name: nginx
+  tags:
+  - tag: 1.18.0
+  - tag: 1.19.2
+    digest: sha256:1192
+  - tag: 2.0.0
+`)
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ImageTagTransformer
+metadata:
+  name: notImportantHere
+imageTag:
+  name: nginx
+  tagPolicy: semver:~1.19
+  digestFrom: file
+imageIndex: images.lock.yaml
+`, `
+group: apps
+apiVersion: v1
+kind: Deployment
+metadata:
+  name: deploy1
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx:1.18.0
+        name: nginx
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+group: apps
+kind: Deployment
+metadata:
+  name: deploy1
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx@sha256:1192
+        name: nginx
+`)
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Append an inventory object listing all the other
+// objects, so that tools pruning with it can delete
+// objects that have since left the build.
+//
+// The object is a ConfigMap whose data keys name the
+// objects, or a ResourceGroup listing them in its
+// spec, in the formats of sigs.k8s.io/cli-utils and
+// kpt respectively.  Either way, it's annotated with a
+// hash of the content of the objects, which changes
+// whenever any of them does.
+type plugin struct {
+	h         *resmap.PluginHelpers
+	Type      string `json:"type,omitempty" yaml:"type,omitempty"`
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+const (
+	resourceGroupApiVersion = "kpt.dev/v1alpha1"
+)
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	p.h = h
+	p.Type, p.Name, p.Namespace = "", "", ""
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	if p.Type == "" {
+		p.Type = types.InventoryTypeConfigMap
+	}
+	if p.Type != types.InventoryTypeConfigMap &&
+		p.Type != types.InventoryTypeResourceGroup {
+		return fmt.Errorf(
+			"inventory type must be %s or %s, not %q",
+			types.InventoryTypeConfigMap,
+			types.InventoryTypeResourceGroup, p.Type)
+	}
+	if p.Name == "" {
+		return fmt.Errorf("inventory must have a name")
+	}
+	return nil
+}
+
+// item is an object listed in the inventory.
+type item struct {
+	Group, Kind, Namespace, Name string
+}
+
+// key returns the key naming the item in the data of
+// an inventory ConfigMap.  Colons, e.g. of the names of
+// RBAC objects, aren't allowed in keys, so they're
+// doubled underscores.
+func (i item) key() string {
+	return strings.ReplaceAll(
+		strings.Join([]string{i.Namespace, i.Name, i.Group, i.Kind}, "_"),
+		":", "__")
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	items, hash, err := inventoryOf(m.Resources())
+	if err != nil {
+		return err
+	}
+	var obj map[string]interface{}
+	if p.Type == types.InventoryTypeResourceGroup {
+		resources := make([]interface{}, len(items))
+		for i, it := range items {
+			resources[i] = map[string]interface{}{
+				"group":     it.Group,
+				"kind":      it.Kind,
+				"namespace": it.Namespace,
+				"name":      it.Name,
+			}
+		}
+		obj = map[string]interface{}{
+			"apiVersion": resourceGroupApiVersion,
+			"kind":       types.InventoryTypeResourceGroup,
+			"spec": map[string]interface{}{
+				"resources": resources,
+			},
+		}
+	} else {
+		data := make(map[string]interface{}, len(items))
+		for _, it := range items {
+			data[it.key()] = ""
+		}
+		obj = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"data":       data,
+		}
+	}
+	metadata := map[string]interface{}{
+		"name": p.Name,
+		"labels": map[string]interface{}{
+			konfig.InventoryIdLabelKey: p.inventoryId(),
+		},
+		"annotations": map[string]interface{}{
+			konfig.InventoryHashAnnotationKey: hash,
+		},
+	}
+	if p.Namespace != "" {
+		metadata["namespace"] = p.Namespace
+	}
+	obj["metadata"] = metadata
+	return m.Append(p.h.ResmapFactory().RF().FromMap(obj))
+}
+
+// inventoryId returns the value of the inventory-id
+// label: the namespace and name of the inventory, which
+// identify it.
+func (p *plugin) inventoryId() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+	return p.Namespace + "." + p.Name
+}
+
+// inventoryOf returns the items naming the resources,
+// sorted, and a hash of the content of the resources.
+func inventoryOf(resources []*resource.Resource) ([]item, string, error) {
+	items := make([]item, len(resources))
+	content := make(map[item][]byte, len(resources))
+	for i, r := range resources {
+		gvk := r.GetGvk()
+		items[i] = item{
+			Group:     gvk.Group,
+			Kind:      gvk.Kind,
+			Namespace: r.GetNamespace(),
+			Name:      r.GetName(),
+		}
+		if _, ok := content[items[i]]; ok {
+			return nil, "", fmt.Errorf(
+				"%s is in the inventory twice", items[i].key())
+		}
+		b, err := r.MarshalJSON()
+		if err != nil {
+			return nil, "", err
+		}
+		content[items[i]] = b
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].key() < items[j].key()
+	})
+	h := sha256.New()
+	for _, it := range items {
+		fmt.Fprintf(h, "%s\n%s\n", it.key(), content[it])
+	}
+	return items, fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const inventoryInput = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+`
+
+func TestInventoryTransformerConfigMap(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("InventoryTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: InventoryTransformer
+metadata:
+  name: notImportantHere
+name: inventory
+namespace: prod
+`, inventoryInput)
+	th.AssertActualEqualsExpected(rm, inventoryInput+`---
+apiVersion: v1
+data:
+  _system__web_rbac.authorization.k8s.io_ClusterRole: ""
+  prod_web__Service: ""
+  prod_web_apps_Deployment: ""
+kind: ConfigMap
+metadata:
+  annotations:
+    kustomize.config.k8s.io/inventory-hash: afe1742f8095e04d11ea4b54a4392a198655589b129df1ef9e07c359650bea89
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: prod.inventory
+  name: inventory
+  namespace: prod
+`)
+}
+
+func TestInventoryTransformerResourceGroup(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("InventoryTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: InventoryTransformer
+metadata:
+  name: notImportantHere
+type: ResourceGroup
+name: inventory
+`, inventoryInput)
+	th.AssertActualEqualsExpected(rm, inventoryInput+`---
+apiVersion: kpt.dev/v1alpha1
+kind: ResourceGroup
+metadata:
+  annotations:
+    kustomize.config.k8s.io/inventory-hash: afe1742f8095e04d11ea4b54a4392a198655589b129df1ef9e07c359650bea89
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: inventory
+  name: inventory
+spec:
+  resources:
+  - group: rbac.authorization.k8s.io
+    kind: ClusterRole
+    name: system:web
+    namespace: ""
+  - group: ""
+    kind: Service
+    name: web
+    namespace: prod
+  - group: apps
+    kind: Deployment
+    name: web
+    namespace: prod
+`)
+}
+
+func TestInventoryTransformerHashFollowsContent(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("InventoryTransformer")
+	defer th.Reset()
+
+	config := `
+apiVersion: builtin
+kind: InventoryTransformer
+metadata:
+  name: notImportantHere
+name: inventory
+`
+	hashOf := func(input string) string {
+		rm := th.LoadAndRunTransformer(config, input)
+		inv := rm.Resources()[rm.Size()-1]
+		return inv.GetAnnotations()["kustomize.config.k8s.io/inventory-hash"]
+	}
+	before := hashOf(inventoryInput)
+	if hashOf(inventoryInput) != before {
+		t.Fatalf("expected the same hash for the same content")
+	}
+	if hashOf(strings.Replace(inventoryInput, "kind: Service\n", "kind: Service\nspec:\n  type: NodePort\n", 1)) == before {
+		t.Fatalf("expected the hash to change with the content")
+	}
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: InventoryTransformer
+metadata:
+  name: notImportantHere
+type: Secret
+name: inventory
+`, inventoryInput)
+	if err == nil || !strings.Contains(err.Error(), "inventory type must be ConfigMap or ResourceGroup") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/replacement"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filtersutil"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Copy a value, or part of a value, from one resource
+// or from a literal into fields of selected resources.
+type plugin struct {
+	Replacements []types.Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Replacements = []types.Replacement{}
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	for _, r := range p.Replacements {
+		if r.Source == nil {
+			return fmt.Errorf("`source` must be specified in one replacement")
+		}
+		if r.Target == nil {
+			return fmt.Errorf("`target` must be specified in one replacement")
+		}
+		if r.Target.ObjRef == nil {
+			return fmt.Errorf("`target.objref` must be specified in one replacement")
+		}
+		if r.Source.ObjRef != nil && r.Source.Value != "" {
+			return fmt.Errorf("only one of objref and value is allowed in one replacement")
+		}
+		if r.Source.ObjRef == nil && r.Source.Value == "" {
+			return fmt.Errorf("one of objref and value is required in one replacement")
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, r := range p.Replacements {
+		value, err := p.getReplacement(m, r.Source)
+		if err != nil {
+			return err
+		}
+		resources, err := m.Select(*r.Target.ObjRef)
+		if err != nil {
+			return err
+		}
+		for _, res := range resources {
+			err = filtersutil.ApplyToJSON(replacement.Filter{
+				Value:     value,
+				FieldRefs: r.Target.FieldRefs,
+				Options:   r.Target.Options,
+			}, res.Kunstructured)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *plugin) getReplacement(
+	m resmap.ResMap, source *types.ReplSource) (*kyaml.RNode, error) {
+	if source.ObjRef == nil {
+		return kyaml.NewScalarRNode(source.Value), nil
+	}
+	resources, err := m.Select(types.Selector{
+		Gvk:       source.ObjRef.GVK(),
+		Name:      source.ObjRef.Name,
+		Namespace: source.ObjRef.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) > 1 {
+		return nil, fmt.Errorf(
+			"found more than one resource matching source %v", source.ObjRef)
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf(
+			"failed to find one resource matching source %v", source.ObjRef)
+	}
+	node, err := filtersutil.GetRNode(resources[0])
+	if err != nil {
+		return nil, err
+	}
+	return replacement.LookupValue(
+		node, source.FieldRefOrDefault(), source.Options)
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"sigs.k8s.io/kustomize/api/filters/scaling"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filtersutil"
+	"sigs.k8s.io/yaml"
+)
+
+// Set the replica count, container resources and autoscaler
+// bounds of the selected resources.
+type plugin struct {
+	Scaling    types.Scaling     `json:"scaling,omitempty" yaml:"scaling,omitempty"`
+	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+// quantity matches Kubernetes quantities, e.g. "250m",
+// "1.5" or "64Mi".
+var quantity = regexp.MustCompile(
+	`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([KMGTPE]i|[numkMGTPE]|[eE][+-]?[0-9]+)?$`)
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Scaling = types.Scaling{}
+	p.FieldSpecs = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	s := p.Scaling
+	for _, n := range []*int64{s.Replicas, s.MinReplicas, s.MaxReplicas} {
+		if n != nil && *n < 0 {
+			return fmt.Errorf("replica counts can't be negative")
+		}
+	}
+	if s.MinReplicas != nil && s.MaxReplicas != nil && *s.MinReplicas > *s.MaxReplicas {
+		return fmt.Errorf(
+			"minReplicas %d is more than maxReplicas %d", *s.MinReplicas, *s.MaxReplicas)
+	}
+	for _, cr := range s.Resources {
+		if cr.Container == "" {
+			return fmt.Errorf("resources must name a container")
+		}
+		for _, q := range []*types.ResourceQuantities{cr.Requests, cr.Limits} {
+			if q == nil {
+				continue
+			}
+			for _, v := range []string{q.CPU, q.Memory} {
+				if v != "" && !quantity.MatchString(v) {
+					return fmt.Errorf(
+						"resources of container %s: %q isn't a quantity", cr.Container, v)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	selected, err := m.Select(p.Scaling.Selector)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("scaling selects no resources")
+	}
+	targets := selected
+	if p.Scaling.MinReplicas != nil || p.Scaling.MaxReplicas != nil {
+		targets = append(targets, autoscalersOf(m, selected)...)
+	}
+	for _, r := range targets {
+		err = filtersutil.ApplyToJSON(scaling.Filter{
+			Scaling: p.Scaling,
+			FsSlice: p.FieldSpecs,
+		}, r.Kunstructured)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// autoscalersOf returns the HorizontalPodAutoscalers,
+// other than those in workloads, that scale one of the
+// workloads.
+func autoscalersOf(m resmap.ResMap, workloads []*resource.Resource) []*resource.Resource {
+	var result []*resource.Resource
+	for _, r := range m.Resources() {
+		if r.GetKind() != scaling.HorizontalPodAutoscalerKind || contains(workloads, r) {
+			continue
+		}
+		kind, _ := r.GetString("spec.scaleTargetRef.kind")
+		name, _ := r.GetString("spec.scaleTargetRef.name")
+		for _, w := range workloads {
+			// The reference isn't yet updated to the new
+			// name of the workload.
+			if w.GetKind() == kind && w.GetNamespace() == r.GetNamespace() &&
+				(w.OrgId().Name == name || w.GetName() == name) {
+				result = append(result, r)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func contains(rs []*resource.Resource, r *resource.Resource) bool {
+	for _, x := range rs {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const resources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: web
+        name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: staging
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: web
+        name: app
+---
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: prod
+spec:
+  maxReplicas: 4
+  minReplicas: 1
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+`
+
+func TestScalingTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ScalingTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: ScalingTransformer
+metadata:
+  name: notImportantHere
+scaling:
+  selector:
+    kind: Deployment
+    name: web
+    namespace: prod
+  replicas: 3
+  resources:
+  - container: app
+    requests:
+      cpu: 250m
+    limits:
+      memory: 1Gi
+  minReplicas: 2
+  maxReplicas: 10
+fieldSpecs:
+- path: spec/replicas
+  create: true
+  kind: Deployment
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: web
+        name: app
+        resources:
+          limits:
+            memory: 1Gi
+          requests:
+            cpu: 250m
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: staging
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: web
+        name: app
+---
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: prod
+spec:
+  maxReplicas: 10
+  minReplicas: 2
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+`)
+}
+
+func TestScalingTransformerErrors(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ScalingTransformer")
+	defer th.Reset()
+
+	for config, expected := range map[string]string{
+		`
+scaling:
+  selector:
+    name: api
+  replicas: 2
+`: "scaling selects no resources",
+		`
+scaling:
+  minReplicas: 5
+  maxReplicas: 2
+`: "minReplicas 5 is more than maxReplicas 2",
+		`
+scaling:
+  resources:
+  - requests:
+      cpu: 1
+`: "resources must name a container",
+		`
+scaling:
+  resources:
+  - container: app
+    limits:
+      memory: lots
+`: `resources of container app: "lots" isn't a quantity`,
+	} {
+		err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: ScalingTransformer
+metadata:
+  name: notImportantHere
+`+strings.TrimPrefix(config, "\n"), resources)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error %q, got %v", expected, err)
+		}
+	}
+}
@@ -5,3 +5,4 @@ go 1.14
 require sigs.k8s.io/kustomize/api v0.4.0
 
 replace sigs.k8s.io/kustomize/api v0.4.0 => ../../../../api
+replace sigs.k8s.io/kustomize/kyaml => ../../../../kyaml